@@ -27,6 +27,12 @@ const docTemplate = `{
                     "payment"
                 ],
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "操作类型：order（默认）、orders、query、settle",
+                        "name": "act",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
                         "name": "act",
@@ -52,8 +58,7 @@ const docTemplate = `{
                     {
                         "type": "integer",
                         "name": "trade_no",
-                        "in": "query",
-                        "required": true
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -76,6 +81,12 @@ const docTemplate = `{
                     "payment"
                 ],
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "操作类型：refund（默认）、close",
+                        "name": "act",
+                        "in": "query"
+                    },
                     {
                         "description": "退款请求",
                         "name": "request",
@@ -96,6 +107,185 @@ const docTemplate = `{
                 }
             }
         },
+        "/api/v1/admin/balance-discrepancies": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "open",
+                            "resolved",
+                            "repaired"
+                        ],
+                        "type": "string",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "user_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/balance-discrepancies/reconcile": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/balance-discrepancies/{id}/repair": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "差异ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/fee-revenue": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "end_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "name": "start_date",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/pay-key-locks": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "parameters": [
+                    {
+                        "maxLength": 64,
+                        "type": "string",
+                        "name": "username",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/pay-key-locks/{user_id}": {
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "用户ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
         "/api/v1/admin/system-configs": {
             "get": {
                 "produces": [
@@ -618,7 +808,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/merchant/api-keys/{id}/payment-links": {
+        "/api/v1/merchant/api-keys/{id}/notify-failed-orders": {
             "get": {
                 "produces": [
                     "application/json"
@@ -634,6 +824,19 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page_size",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -644,11 +847,10 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/api/v1/merchant/api-keys/{id}/notify-failed-orders/retry": {
             "post": {
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
                     "application/json"
                 ],
@@ -663,15 +865,6 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "创建支付链接请求",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/link.CreatePaymentLinkRequest"
-                        }
                     }
                 ],
                 "responses": {
@@ -684,8 +877,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/merchant/api-keys/{id}/payment-links/{linkId}": {
-            "delete": {
+        "/api/v1/merchant/api-keys/{id}/payment-links": {
+            "get": {
                 "produces": [
                     "application/json"
                 ],
@@ -700,14 +893,6 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "format": "int64",
-                        "description": "Payment Link ID",
-                        "name": "linkId",
-                        "in": "path",
-                        "required": true
                     }
                 ],
                 "responses": {
@@ -718,9 +903,7 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/merchant/payment": {
+            },
             "post": {
                 "consumes": [
                     "application/json"
@@ -729,16 +912,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "payment"
+                    "merchant"
                 ],
                 "parameters": [
                     {
-                        "description": "支付订单请求",
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "API Key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "创建支付链接请求",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/payment.PayOrderRequest"
+                            "$ref": "#/definitions/link.CreatePaymentLinkRequest"
                         }
                     }
                 ],
@@ -752,11 +943,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/merchant/payment-links/pay": {
-            "post": {
-                "consumes": [
-                    "application/json"
-                ],
+        "/api/v1/merchant/api-keys/{id}/payment-links/{linkId}": {
+            "delete": {
                 "produces": [
                     "application/json"
                 ],
@@ -765,13 +953,20 @@ const docTemplate = `{
                 ],
                 "parameters": [
                     {
-                        "description": "支付请求",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/link.PayByLinkRequest"
-                        }
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "API Key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Payment Link ID",
+                        "name": "linkId",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -784,7 +979,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/merchant/payment-links/{token}": {
+        "/api/v1/merchant/api-keys/{id}/webhook-deliveries": {
             "get": {
                 "produces": [
                     "application/json"
@@ -794,12 +989,182 @@ const docTemplate = `{
                 ],
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "支付链接 Token",
-                        "name": "token",
-                        "in": "path",
-                        "required": true
-                    }
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "API Key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "name": "event",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "order_id",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "name": "success",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchant"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "API Key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "request body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/payment.RedeliverWebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/merchant/payment": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payment"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "幂等键，重复请求返回首次响应",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "支付订单请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/payment.PayOrderRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/merchant/payment-links/pay": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchant"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "幂等键，重复请求返回首次响应",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "支付请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/link.PayByLinkRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/merchant/payment-links/{token}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchant"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "支付链接 Token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
                 "responses": {
                     "200": {
@@ -841,6 +1206,90 @@ const docTemplate = `{
                 }
             }
         },
+        "/api/v1/notifications": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notification"
+                ],
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "name": "unread",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/notifications/read-all": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notification"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/notifications/{id}/read": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notification"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "通知 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
         "/api/v1/oauth/callback": {
             "post": {
                 "produces": [
@@ -1062,6 +1511,12 @@ const docTemplate = `{
                     "order"
                 ],
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "幂等键，重复请求返回首次响应",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
                     {
                         "description": "request body",
                         "name": "request",
@@ -1082,25 +1537,490 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/order/transactions": {
-            "post": {
-                "consumes": [
-                    "application/json"
-                ],
+        "/api/v1/order/transactions": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "order"
+                ],
+                "parameters": [
+                    {
+                        "description": "request body",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/order.TransactionListRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/payment/transfer": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payment"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "幂等键，重复请求返回首次响应",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "转账请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/payment.TransferRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/pay-key": {
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "parameters": [
+                    {
+                        "description": "request body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.UpdatePayKeyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/totp": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/totp/disable": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "parameters": [
+                    {
+                        "description": "request body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.DisableTOTPRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/totp/enable": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "parameters": [
+                    {
+                        "description": "request body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.EnableTOTPRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/totp/recovery-codes": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "parameters": [
+                    {
+                        "description": "request body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.RegenerateTOTPRecoveryCodesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/totp/setup": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "parameters": [
+                    {
+                        "description": "request body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.SetupTOTPRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/totp/threshold": {
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "parameters": [
+                    {
+                        "description": "request body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.UpdateTOTPThresholdRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/merchant/balance": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchant-api"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/payment.MerchantAPIErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/merchant/orders": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchant-api"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "end_time",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "start_time",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "success",
+                            "pending",
+                            "expired",
+                            "disputing",
+                            "refund",
+                            "refused",
+                            "partially_refunded",
+                            "closed"
+                        ],
+                        "type": "string",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/payment.MerchantAPIErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchant-api"
+                ],
+                "parameters": [
+                    {
+                        "description": "request body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/payment.CreateOrderRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/payment.MerchantAPIErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/merchant/orders/{trade_no}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchant-api"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "平台订单号",
+                        "name": "trade_no",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/payment.MerchantAPIErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/merchant/orders/{trade_no}/close": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchant-api"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "平台订单号",
+                        "name": "trade_no",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ResponseAny"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/payment.MerchantAPIErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/merchant/orders/{trade_no}/refunds": {
+            "get": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "order"
+                    "merchant-api"
                 ],
                 "parameters": [
                     {
-                        "description": "request body",
-                        "name": "request",
-                        "in": "body",
-                        "schema": {
-                            "$ref": "#/definitions/order.TransactionListRequest"
-                        }
+                        "type": "string",
+                        "description": "平台订单号",
+                        "name": "trade_no",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -1109,11 +2029,15 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/util.ResponseAny"
                         }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/payment.MerchantAPIErrorResponse"
+                        }
                     }
                 }
-            }
-        },
-        "/api/v1/payment/transfer": {
+            },
             "post": {
                 "consumes": [
                     "application/json"
@@ -1122,39 +2046,52 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "payment"
+                    "merchant-api"
                 ],
                 "parameters": [
                     {
-                        "description": "转账请求",
+                        "type": "string",
+                        "description": "平台订单号",
+                        "name": "trade_no",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "request body",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/payment.TransferRequest"
+                            "$ref": "#/definitions/payment.MerchantAPIRefundRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
                             "$ref": "#/definitions/util.ResponseAny"
                         }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/payment.MerchantAPIErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/api/v1/user/pay-key": {
-            "put": {
+        "/mapi.php": {
+            "post": {
                 "consumes": [
-                    "application/json"
+                    "application/x-www-form-urlencoded"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "payment"
                 ],
                 "parameters": [
                     {
@@ -1163,7 +2100,7 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/user.UpdatePayKeyRequest"
+                            "$ref": "#/definitions/payment.EPayRequest"
                         }
                     }
                 ],
@@ -1171,7 +2108,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/util.ResponseAny"
+                            "$ref": "#/definitions/payment.CreateMerchantOrderAPIResponse"
                         }
                     }
                 }
@@ -1231,6 +2168,13 @@ const docTemplate = `{
                     "type": "string",
                     "maxLength": 20
                 },
+                "callback_format": {
+                    "type": "string",
+                    "enum": [
+                        "epay",
+                        "json"
+                    ]
+                },
                 "notify_url": {
                     "type": "string",
                     "maxLength": 100
@@ -1238,6 +2182,12 @@ const docTemplate = `{
                 "redirect_uri": {
                     "type": "string",
                     "maxLength": 100
+                },
+                "webhook_events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.WebhookEvent"
+                    }
                 }
             }
         },
@@ -1256,6 +2206,13 @@ const docTemplate = `{
                     "type": "string",
                     "maxLength": 20
                 },
+                "callback_format": {
+                    "type": "string",
+                    "enum": [
+                        "epay",
+                        "json"
+                    ]
+                },
                 "notify_url": {
                     "type": "string",
                     "maxLength": 100
@@ -1263,6 +2220,12 @@ const docTemplate = `{
                 "redirect_uri": {
                     "type": "string",
                     "maxLength": 100
+                },
+                "webhook_events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.WebhookEvent"
+                    }
                 }
             }
         },
@@ -1378,6 +2341,10 @@ const docTemplate = `{
                 },
                 "token": {
                     "type": "string"
+                },
+                "totp_code": {
+                    "type": "string",
+                    "maxLength": 16
                 }
             }
         },
@@ -1397,6 +2364,23 @@ const docTemplate = `{
                 "PayLevelPremium"
             ]
         },
+        "model.WebhookEvent": {
+            "type": "string",
+            "enum": [
+                "order.paid",
+                "order.refunded",
+                "order.expired",
+                "dispute.opened",
+                "dispute.resolved"
+            ],
+            "x-enum-varnames": [
+                "WebhookEventOrderPaid",
+                "WebhookEventOrderRefunded",
+                "WebhookEventOrderExpired",
+                "WebhookEventDisputeOpened",
+                "WebhookEventDisputeResolved"
+            ]
+        },
         "oauth.CallbackRequest": {
             "type": "object",
             "properties": {
@@ -1450,7 +2434,9 @@ const docTemplate = `{
                         "expired",
                         "disputing",
                         "refund",
-                        "refused"
+                        "refused",
+                        "partially_refunded",
+                        "closed"
                     ]
                 },
                 "type": {
@@ -1465,6 +2451,43 @@ const docTemplate = `{
                 }
             }
         },
+        "payment.CreateMerchantOrderAPIResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "expire_time": {
+                    "type": "string",
+                    "example": "2023-12-08 12:05:00"
+                },
+                "money": {
+                    "type": "string",
+                    "example": "10.00"
+                },
+                "msg": {
+                    "type": "string",
+                    "example": "创建订单成功"
+                },
+                "out_trade_no": {
+                    "type": "string",
+                    "example": "M202312080001"
+                },
+                "payurl": {
+                    "type": "string",
+                    "example": "https://credit.linux.do/paying?order_no=xxx"
+                },
+                "qrcode": {
+                    "type": "string",
+                    "example": "https://credit.linux.do/paying?order_no=xxx"
+                },
+                "trade_no": {
+                    "type": "string",
+                    "example": "123456"
+                }
+            }
+        },
         "payment.CreateOrderRequest": {
             "type": "object",
             "required": [
@@ -1478,6 +2501,10 @@ const docTemplate = `{
                 "merchant_order_no": {
                     "type": "string"
                 },
+                "notify_url": {
+                    "type": "string",
+                    "maxLength": 255
+                },
                 "order_name": {
                     "type": "string",
                     "maxLength": 64
@@ -1488,6 +2515,86 @@ const docTemplate = `{
                 "remark": {
                     "type": "string",
                     "maxLength": 100
+                },
+                "return_url": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "payment.EPayRequest": {
+            "type": "object",
+            "required": [
+                "money",
+                "name",
+                "out_trade_no",
+                "pid",
+                "sign",
+                "type"
+            ],
+            "properties": {
+                "device": {
+                    "type": "string"
+                },
+                "money": {
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "notify_url": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "out_trade_no": {
+                    "type": "string"
+                },
+                "pid": {
+                    "type": "string"
+                },
+                "return_url": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "sign": {
+                    "type": "string"
+                },
+                "sign_type": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "payment.MerchantAPIErrorResponse": {
+            "type": "object",
+            "properties": {
+                "data": {},
+                "error_code": {
+                    "type": "string",
+                    "example": "order_not_found"
+                },
+                "error_msg": {
+                    "type": "string",
+                    "example": "订单不存在或已完成"
+                }
+            }
+        },
+        "payment.MerchantAPIRefundRequest": {
+            "type": "object",
+            "required": [
+                "amount",
+                "refund_no"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "refund_no": {
+                    "type": "string",
+                    "maxLength": 64
                 }
             }
         },
@@ -1504,6 +2611,10 @@ const docTemplate = `{
                 "pay_key": {
                     "type": "string",
                     "maxLength": 6
+                },
+                "totp_code": {
+                    "type": "string",
+                    "maxLength": 16
                 }
             }
         },
@@ -1556,6 +2667,17 @@ const docTemplate = `{
                 }
             }
         },
+        "payment.RedeliverWebhookRequest": {
+            "type": "object",
+            "required": [
+                "order_id"
+            ],
+            "properties": {
+                "order_id": {
+                    "type": "integer"
+                }
+            }
+        },
         "payment.RefundMerchantOrderResponse": {
             "type": "object",
             "properties": {
@@ -1566,6 +2688,18 @@ const docTemplate = `{
                 "msg": {
                     "type": "string",
                     "example": "退款成功"
+                },
+                "out_refund_no": {
+                    "type": "string",
+                    "example": "R20240101001"
+                },
+                "refunded_money": {
+                    "type": "string",
+                    "example": "10.00"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "partially_refunded"
                 }
             }
         },
@@ -1584,6 +2718,10 @@ const docTemplate = `{
                 "money": {
                     "type": "number"
                 },
+                "out_refund_no": {
+                    "type": "string",
+                    "maxLength": 64
+                },
                 "out_trade_no": {
                     "type": "string"
                 },
@@ -1620,6 +2758,10 @@ const docTemplate = `{
                 "remark": {
                     "type": "string",
                     "maxLength": 100
+                },
+                "totp_code": {
+                    "type": "string",
+                    "maxLength": 16
                 }
             }
         },
@@ -1660,18 +2802,89 @@ const docTemplate = `{
                 }
             }
         },
+        "user.DisableTOTPRequest": {
+            "type": "object",
+            "required": [
+                "code",
+                "pay_key"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "maxLength": 16
+                },
+                "pay_key": {
+                    "type": "string",
+                    "maxLength": 6
+                }
+            }
+        },
+        "user.EnableTOTPRequest": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                }
+            }
+        },
+        "user.RegenerateTOTPRecoveryCodesRequest": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "maxLength": 16
+                }
+            }
+        },
+        "user.SetupTOTPRequest": {
+            "type": "object",
+            "required": [
+                "pay_key"
+            ],
+            "properties": {
+                "pay_key": {
+                    "type": "string",
+                    "maxLength": 6
+                }
+            }
+        },
         "user.UpdatePayKeyRequest": {
             "type": "object",
             "required": [
                 "pay_key"
             ],
             "properties": {
+                "old_pay_key": {
+                    "type": "string",
+                    "maxLength": 6
+                },
                 "pay_key": {
                     "type": "string",
                     "maxLength": 6
                 }
             }
         },
+        "user.UpdateTOTPThresholdRequest": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "maxLength": 16
+                },
+                "threshold": {
+                    "type": "number"
+                }
+            }
+        },
         "user_pay_config.CreateUserPayConfigRequest": {
             "type": "object",
             "required": [
@@ -0,0 +1,30 @@
+"use client"
+
+import { PayKeyLocks } from "@/components/common/admin/pay-key-locks"
+import { ErrorPage } from "@/components/layout/error"
+import { LoadingPage } from "@/components/layout/loading"
+
+import { useUser } from "@/contexts/user-context"
+
+
+/* 支付密码锁定页面 */
+export default function PayKeyLockPage() {
+  const { user, loading } = useUser()
+
+  /* 等待用户信息加载完成 */
+  if (loading) {
+    return <LoadingPage text="支付密码锁定" badgeText="安全" />
+  }
+
+  /* 权限检查：只有管理员才能访问 */
+  if (!user?.is_admin) {
+    return (
+      <ErrorPage
+        title="访问被拒绝"
+        message="您没有权限访问此页面"
+      />
+    )
+  }
+
+  return <PayKeyLocks />
+}
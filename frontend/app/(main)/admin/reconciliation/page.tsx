@@ -0,0 +1,30 @@
+"use client"
+
+import { BalanceDiscrepancies } from "@/components/common/admin/balance-discrepancies"
+import { ErrorPage } from "@/components/layout/error"
+import { LoadingPage } from "@/components/layout/loading"
+
+import { useUser } from "@/contexts/user-context"
+
+
+/* 余额对账页面 */
+export default function ReconciliationPage() {
+  const { user, loading } = useUser()
+
+  /* 等待用户信息加载完成 */
+  if (loading) {
+    return <LoadingPage text="余额对账" badgeText="对账" />
+  }
+
+  /* 权限检查：只有管理员才能访问 */
+  if (!user?.is_admin) {
+    return (
+      <ErrorPage
+        title="访问被拒绝"
+        message="您没有权限访问此页面"
+      />
+    )
+  }
+
+  return <BalanceDiscrepancies />
+}
@@ -0,0 +1,189 @@
+"use client"
+
+import * as React from "react"
+import { useCallback, useEffect, useState } from "react"
+import { toast } from "sonner"
+import { RefreshCw, Scale } from "lucide-react"
+import { Button } from "@/components/ui/button"
+import { Badge } from "@/components/ui/badge"
+import { Spinner } from "@/components/ui/spinner"
+import { Table, TableBody, TableCell, TableHead, TableHeader, TableRow } from "@/components/ui/table"
+import { EmptyStateWithBorder } from "@/components/layout/empty"
+
+import { formatDateTime } from "@/lib/utils"
+import services from "@/lib/services"
+import type { BalanceDiscrepancy, BalanceField, DiscrepancyStatus } from "@/lib/services"
+
+
+/* 对账字段名称 */
+const FIELD_LABELS: Record<BalanceField, string> = {
+  available_balance: "可用余额",
+  total_receive: "总收入",
+  total_payment: "总支付",
+  total_transfer: "总转出",
+  ledger_balance: "账本余额",
+}
+
+/* 差异状态名称 */
+const STATUS_LABELS: Record<DiscrepancyStatus, string> = {
+  open: "未处理",
+  resolved: "已恢复",
+  repaired: "已修复",
+}
+
+const PAGE_SIZE = 20
+
+/**
+ * 对账差异管理组件
+ * 展示余额对账发现的差异，支持立即对账和修复差异
+ * 
+ * @example
+ * ```tsx
+ * <BalanceDiscrepancies />
+ * ```
+ * @returns {React.ReactNode} 对账差异管理组件
+ */
+export function BalanceDiscrepancies() {
+  const [status, setStatus] = useState<DiscrepancyStatus>("open")
+  const [page, setPage] = useState(1)
+  const [total, setTotal] = useState(0)
+  const [discrepancies, setDiscrepancies] = useState<BalanceDiscrepancy[]>([])
+  const [loading, setLoading] = useState(true)
+  const [reconciling, setReconciling] = useState(false)
+  const [repairingId, setRepairingId] = useState<number | null>(null)
+
+  /* 加载对账差异 */
+  const loadDiscrepancies = useCallback(async () => {
+    setLoading(true)
+    try {
+      const result = await services.admin.listBalanceDiscrepancies({ page, page_size: PAGE_SIZE, status })
+      setDiscrepancies(result.discrepancies)
+      setTotal(result.total)
+    } catch (error) {
+      toast.error('加载失败', { description: error instanceof Error ? error.message : '未知错误' })
+    } finally {
+      setLoading(false)
+    }
+  }, [page, status])
+
+  useEffect(() => {
+    loadDiscrepancies()
+  }, [loadDiscrepancies])
+
+  /* 立即对账 */
+  const handleReconcile = async () => {
+    setReconciling(true)
+    try {
+      await services.admin.triggerReconcile()
+      toast.success('已下发对账任务', { description: '对账完成后刷新列表查看结果' })
+    } catch (error) {
+      toast.error('下发失败', { description: error instanceof Error ? error.message : '未知错误' })
+    } finally {
+      setReconciling(false)
+    }
+  }
+
+  /* 修复差异 */
+  const handleRepair = async (item: BalanceDiscrepancy) => {
+    setRepairingId(item.id)
+    try {
+      await services.admin.repairBalanceDiscrepancy(item.id)
+      toast.success('修复成功', { description: `${ item.username } 的${ FIELD_LABELS[item.field] }已修正为 ${ item.expected }` })
+      await loadDiscrepancies()
+    } catch (error) {
+      toast.error('修复失败', { description: error instanceof Error ? error.message : '未知错误' })
+    } finally {
+      setRepairingId(null)
+    }
+  }
+
+  const totalPages = Math.max(1, Math.ceil(total / PAGE_SIZE))
+
+  return (
+    <div className="py-6 space-y-4">
+      <div className="flex items-center justify-between border-b border-border pb-2">
+        <h1 className="text-2xl font-semibold">余额对账</h1>
+        <div className="flex items-center gap-2">
+          {(Object.keys(STATUS_LABELS) as DiscrepancyStatus[]).map(key => (
+            <Button
+              key={key}
+              variant={status === key ? "default" : "outline"}
+              className="text-xs h-8"
+              onClick={() => { setStatus(key); setPage(1) }}
+            >
+              {STATUS_LABELS[key]}
+            </Button>
+          ))}
+          <Button variant="outline" className="text-xs h-8 border-dashed" disabled={reconciling} onClick={handleReconcile}>
+            <RefreshCw className={`size-3 mr-1 ${ reconciling ? 'animate-spin' : '' }`} />
+            立即对账
+          </Button>
+        </div>
+      </div>
+
+      {loading ? (
+        <div className="flex justify-center py-12">
+          <Spinner />
+        </div>
+      ) : discrepancies.length === 0 ? (
+        <EmptyStateWithBorder icon={Scale} description="未发现对账差异" />
+      ) : (
+        <div className="border border-dashed rounded-lg">
+          <Table>
+            <TableHeader>
+              <TableRow>
+                <TableHead className="text-xs">用户</TableHead>
+                <TableHead className="text-xs">字段</TableHead>
+                <TableHead className="text-xs text-right">实际值</TableHead>
+                <TableHead className="text-xs text-right">推算值</TableHead>
+                <TableHead className="text-xs text-right">差额</TableHead>
+                <TableHead className="text-xs">状态</TableHead>
+                <TableHead className="text-xs">发现时间</TableHead>
+                <TableHead className="text-xs text-right">操作</TableHead>
+              </TableRow>
+            </TableHeader>
+            <TableBody>
+              {discrepancies.map(item => (
+                <TableRow key={item.id}>
+                  <TableCell className="text-xs font-medium">{item.username}</TableCell>
+                  <TableCell className="text-xs">{FIELD_LABELS[item.field]}</TableCell>
+                  <TableCell className="text-xs text-right font-mono">{item.actual}</TableCell>
+                  <TableCell className="text-xs text-right font-mono">{item.expected}</TableCell>
+                  <TableCell className="text-xs text-right font-mono text-destructive">{item.diff}</TableCell>
+                  <TableCell className="text-xs">
+                    <Badge variant={item.status === 'open' ? 'destructive' : 'secondary'}>{STATUS_LABELS[item.status]}</Badge>
+                  </TableCell>
+                  <TableCell className="text-xs text-muted-foreground">{formatDateTime(item.created_at)}</TableCell>
+                  <TableCell className="text-right">
+                    {item.status === 'open' && item.field !== 'ledger_balance' && (
+                      <Button
+                        variant="outline"
+                        className="text-xs h-7"
+                        disabled={repairingId !== null}
+                        onClick={() => handleRepair(item)}
+                      >
+                        {repairingId === item.id ? <Spinner /> : '修复'}
+                      </Button>
+                    )}
+                  </TableCell>
+                </TableRow>
+              ))}
+            </TableBody>
+          </Table>
+        </div>
+      )}
+
+      {totalPages > 1 && (
+        <div className="flex items-center justify-end gap-2">
+          <Button variant="outline" className="text-xs h-8" disabled={page <= 1} onClick={() => setPage(page - 1)}>
+            上一页
+          </Button>
+          <span className="text-xs text-muted-foreground">{page} / {totalPages}</span>
+          <Button variant="outline" className="text-xs h-8" disabled={page >= totalPages} onClick={() => setPage(page + 1)}>
+            下一页
+          </Button>
+        </div>
+      )}
+    </div>
+  )
+}
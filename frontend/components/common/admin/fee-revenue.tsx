@@ -0,0 +1,118 @@
+"use client"
+
+import * as React from "react"
+import { useCallback, useEffect, useState } from "react"
+import { toast } from "sonner"
+import { format, subDays } from "date-fns"
+import { Receipt } from "lucide-react"
+import { Button } from "@/components/ui/button"
+import { Input } from "@/components/ui/input"
+import { Spinner } from "@/components/ui/spinner"
+import { Table, TableBody, TableCell, TableHead, TableHeader, TableRow } from "@/components/ui/table"
+import { EmptyStateWithBorder } from "@/components/layout/empty"
+
+import services from "@/lib/services"
+import type { GetFeeRevenueResponse } from "@/lib/services"
+
+
+/* 日期格式 */
+const DATE_FORMAT = "yyyy-MM-dd"
+
+/**
+ * 手续费收入统计组件
+ * 按交易日展示平台收取和退还的手续费
+ * 
+ * @example
+ * ```tsx
+ * <FeeRevenue />
+ * ```
+ * @returns {React.ReactNode} 手续费收入统计组件
+ */
+export function FeeRevenue() {
+  const [startDate, setStartDate] = useState(() => format(subDays(new Date(), 29), DATE_FORMAT))
+  const [endDate, setEndDate] = useState(() => format(new Date(), DATE_FORMAT))
+  const [revenue, setRevenue] = useState<GetFeeRevenueResponse | null>(null)
+  const [loading, setLoading] = useState(true)
+
+  /* 加载手续费收入 */
+  const loadRevenue = useCallback(async () => {
+    setLoading(true)
+    try {
+      setRevenue(await services.admin.getFeeRevenue({ start_date: startDate, end_date: endDate }))
+    } catch (error) {
+      toast.error('加载失败', { description: error instanceof Error ? error.message : '未知错误' })
+    } finally {
+      setLoading(false)
+    }
+  }, [startDate, endDate])
+
+  useEffect(() => {
+    loadRevenue()
+    // eslint-disable-next-line react-hooks/exhaustive-deps
+  }, [])
+
+  const summary = [
+    { label: "手续费账户余额", value: revenue?.platform_balance },
+    { label: "收取手续费", value: revenue?.total_fee },
+    { label: "退还手续费", value: revenue?.total_refunded_fee },
+    { label: "净收入", value: revenue?.net_fee },
+  ]
+
+  return (
+    <div className="py-6 space-y-4">
+      <div className="flex items-center justify-between border-b border-border pb-2">
+        <h1 className="text-2xl font-semibold">手续费收入</h1>
+        <div className="flex items-center gap-2">
+          <Input type="date" className="h-8 text-xs w-36" value={startDate} onChange={(e) => setStartDate(e.target.value)} />
+          <span className="text-xs text-muted-foreground">至</span>
+          <Input type="date" className="h-8 text-xs w-36" value={endDate} onChange={(e) => setEndDate(e.target.value)} />
+          <Button variant="outline" className="text-xs h-8" disabled={loading} onClick={loadRevenue}>
+            查询
+          </Button>
+        </div>
+      </div>
+
+      <div className="grid grid-cols-2 md:grid-cols-4 gap-0 border border-dashed rounded-lg">
+        {summary.map(item => (
+          <div key={item.label} className="px-3 py-2 border-r border-dashed last:border-r-0">
+            <label className="text-xs font-medium text-muted-foreground">{item.label}</label>
+            <p className="text-lg font-semibold font-mono">{item.value ?? '-'}</p>
+          </div>
+        ))}
+      </div>
+
+      {loading ? (
+        <div className="flex justify-center py-12">
+          <Spinner />
+        </div>
+      ) : !revenue || revenue.daily.length === 0 ? (
+        <EmptyStateWithBorder icon={Receipt} description="该时间段内没有手续费收入" />
+      ) : (
+        <div className="border border-dashed rounded-lg">
+          <Table>
+            <TableHeader>
+              <TableRow>
+                <TableHead className="text-xs">交易日</TableHead>
+                <TableHead className="text-xs text-right">订单数</TableHead>
+                <TableHead className="text-xs text-right">收取手续费</TableHead>
+                <TableHead className="text-xs text-right">退还手续费</TableHead>
+                <TableHead className="text-xs text-right">净收入</TableHead>
+              </TableRow>
+            </TableHeader>
+            <TableBody>
+              {revenue.daily.map(item => (
+                <TableRow key={item.date}>
+                  <TableCell className="text-xs font-mono">{item.date}</TableCell>
+                  <TableCell className="text-xs text-right">{item.order_count}</TableCell>
+                  <TableCell className="text-xs text-right font-mono">{item.fee}</TableCell>
+                  <TableCell className="text-xs text-right font-mono">{item.refunded_fee}</TableCell>
+                  <TableCell className="text-xs text-right font-mono font-medium">{item.net_fee}</TableCell>
+                </TableRow>
+              ))}
+            </TableBody>
+          </Table>
+        </div>
+      )}
+    </div>
+  )
+}
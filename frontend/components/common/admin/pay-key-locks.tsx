@@ -0,0 +1,122 @@
+"use client"
+
+import * as React from "react"
+import { useState } from "react"
+import { toast } from "sonner"
+import { Search, LockOpen } from "lucide-react"
+import { Button } from "@/components/ui/button"
+import { Input } from "@/components/ui/input"
+import { Badge } from "@/components/ui/badge"
+
+import { formatDateTime } from "@/lib/utils"
+import services from "@/lib/services"
+import type { PayKeyLock } from "@/lib/services"
+
+
+/**
+ * 支付密码锁定管理组件
+ * 按用户名查询支付密码错误次数和锁定状态，支持解除锁定
+ * 
+ * @example
+ * ```tsx
+ * <PayKeyLocks />
+ * ```
+ * @returns {React.ReactNode} 支付密码锁定管理组件
+ */
+export function PayKeyLocks() {
+  const [username, setUsername] = useState("")
+  const [lock, setLock] = useState<PayKeyLock | null>(null)
+  const [searching, setSearching] = useState(false)
+  const [unlocking, setUnlocking] = useState(false)
+
+  /* 查询锁定状态 */
+  const handleSearch = async () => {
+    if (!username.trim()) {
+      toast.error("请输入用户名")
+      return
+    }
+
+    setSearching(true)
+    try {
+      setLock(await services.admin.getPayKeyLock(username.trim()))
+    } catch (error) {
+      setLock(null)
+      toast.error('查询失败', { description: error instanceof Error ? error.message : '未知错误' })
+    } finally {
+      setSearching(false)
+    }
+  }
+
+  /* 解除锁定 */
+  const handleUnlock = async () => {
+    if (!lock) return
+
+    setUnlocking(true)
+    try {
+      await services.admin.unlockPayKey(lock.user_id)
+      toast.success('已解除锁定', { description: `${ lock.username } 的支付密码错误次数已清零` })
+      setLock({ ...lock, failed_attempts: 0, lock_level: 0, locked_until: null })
+    } catch (error) {
+      toast.error('解除失败', { description: error instanceof Error ? error.message : '未知错误' })
+    } finally {
+      setUnlocking(false)
+    }
+  }
+
+  return (
+    <div className="py-6 space-y-4">
+      <div className="flex items-center justify-between border-b border-border pb-2">
+        <h1 className="text-2xl font-semibold">支付密码锁定</h1>
+        <div className="flex items-center gap-2">
+          <Input
+            className="h-8 text-xs w-48"
+            placeholder="输入用户名"
+            value={username}
+            onChange={(e) => setUsername(e.target.value)}
+            onKeyDown={(e) => e.key === 'Enter' && handleSearch()}
+          />
+          <Button variant="outline" className="text-xs h-8" disabled={searching} onClick={handleSearch}>
+            <Search className="size-3 mr-1" />
+            查询
+          </Button>
+        </div>
+      </div>
+
+      {lock && (
+        <div className="max-w-md">
+          <div className="border border-dashed rounded-lg">
+            <div className="px-3 py-2 flex items-center justify-between border-b border-dashed">
+              <label className="text-xs font-medium text-muted-foreground">用户</label>
+              <p className="text-xs font-medium">{lock.username}（ID: {lock.user_id}）</p>
+            </div>
+            <div className="px-3 py-2 flex items-center justify-between border-b border-dashed">
+              <label className="text-xs font-medium text-muted-foreground">状态</label>
+              <Badge variant={lock.locked_until ? 'destructive' : 'secondary'}>{lock.locked_until ? '已锁定' : '正常'}</Badge>
+            </div>
+            <div className="px-3 py-2 flex items-center justify-between border-b border-dashed">
+              <label className="text-xs font-medium text-muted-foreground">连续错误次数</label>
+              <p className="text-xs text-muted-foreground">{lock.failed_attempts}</p>
+            </div>
+            <div className="px-3 py-2 flex items-center justify-between border-b border-dashed">
+              <label className="text-xs font-medium text-muted-foreground">锁定梯度</label>
+              <p className="text-xs text-muted-foreground">{lock.lock_level}</p>
+            </div>
+            <div className="px-3 py-2 flex items-center justify-between">
+              <label className="text-xs font-medium text-muted-foreground">锁定截止</label>
+              <p className="text-xs text-muted-foreground">{lock.locked_until ? formatDateTime(lock.locked_until) : '-'}</p>
+            </div>
+          </div>
+          <Button
+            variant="outline"
+            className="mt-2 text-xs h-8 border-dashed"
+            disabled={unlocking || (!lock.locked_until && lock.failed_attempts === 0 && lock.lock_level === 0)}
+            onClick={handleUnlock}
+          >
+            <LockOpen className="size-3 mr-1" />
+            解除锁定
+          </Button>
+        </div>
+      )}
+    </div>
+  )
+}
@@ -22,11 +22,139 @@ export const apiSections: PolicySection[] = [
     title: "1. 官方服务接口",
     content: (
       <div className="space-y-4 text-sm leading-relaxed">
-        <div className="bg-muted/50 border border-border/50 rounded-lg px-3 py-2">
-          <p className="text-muted-foreground m-0">官方服务接口暂未上线，敬请期待</p>
+        <div className="bg-muted/50 border border-border/50 rounded-lg px-3 py-2 mb-6">
+          <p className="text-muted-foreground m-0">面向服务端的 JSON RESTful 接口，基础路径 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">/api/v2/merchant</code></p>
+        </div>
+
+        <h3 id="1-1-auth" className="text-base md:text-lg font-semibold text-foreground mt-6 md:mt-8 mb-3 md:mb-4">1.1 鉴权</h3>
+        <ul className="list-disc pl-4 md:pl-5 space-y-2 mb-6">
+          <li>使用 HTTP Basic Auth：<code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">Authorization: Basic base64(pid:key)</code></li>
+          <li>请求体与响应体均为 JSON，成功时 HTTP 状态码为 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">200</code> 或 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">201</code>，数据位于 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">data</code> 字段</li>
+          <li>通过本接口创建的积分流转服务以 LDPay 原生协议（见 2.4.3）发送异步通知</li>
+        </ul>
+
+        <h3 id="1-2-endpoints" className="text-base md:text-lg font-semibold text-foreground mt-6 md:mt-8 mb-3 md:mb-4">1.2 接口列表</h3>
+        <div>
+          <DocsTable>
+            <DocsTableHeader>
+              <DocsTableRow>
+                <DocsTableHead>方法</DocsTableHead>
+                <DocsTableHead>路径</DocsTableHead>
+                <DocsTableHead>说明</DocsTableHead>
+              </DocsTableRow>
+            </DocsTableHeader>
+            <DocsTableBody>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">POST</DocsTableCell>
+                <DocsTableCell className="font-mono text-xs">/orders</DocsTableCell>
+                <DocsTableCell>创建积分流转服务，请求体字段为 order_name、merchant_order_no、amount、remark、notify_url、return_url，返回 pay_url；merchant_order_no 幂等规则同 2.5 的 out_trade_no</DocsTableCell>
+              </DocsTableRow>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">GET</DocsTableCell>
+                <DocsTableCell className="font-mono text-xs">/orders</DocsTableCell>
+                <DocsTableCell>服务列表，支持 page、page_size、status、start_time、end_time</DocsTableCell>
+              </DocsTableRow>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">GET</DocsTableCell>
+                <DocsTableCell className="font-mono text-xs">/orders/:trade_no</DocsTableCell>
+                <DocsTableCell>查询单个服务</DocsTableCell>
+              </DocsTableRow>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">POST</DocsTableCell>
+                <DocsTableCell className="font-mono text-xs">/orders/:trade_no/close</DocsTableCell>
+                <DocsTableCell>关闭待认证的服务</DocsTableCell>
+              </DocsTableRow>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">POST</DocsTableCell>
+                <DocsTableCell className="font-mono text-xs">/orders/:trade_no/refunds</DocsTableCell>
+                <DocsTableCell>退回积分，请求体字段为 amount、refund_no（用于幂等）</DocsTableCell>
+              </DocsTableRow>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">GET</DocsTableCell>
+                <DocsTableCell className="font-mono text-xs">/orders/:trade_no/refunds</DocsTableCell>
+                <DocsTableCell>查询退回记录</DocsTableCell>
+              </DocsTableRow>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">GET</DocsTableCell>
+                <DocsTableCell className="font-mono text-xs">/balance</DocsTableCell>
+                <DocsTableCell>查询应用所属账户的积分余额</DocsTableCell>
+              </DocsTableRow>
+            </DocsTableBody>
+          </DocsTable>
+        </div>
+
+        <h3 id="1-3-errors" className="text-base md:text-lg font-semibold text-foreground mt-6 md:mt-8 mb-3 md:mb-4">1.3 错误码</h3>
+        <CodeBlock code={`{ "error_code": "order_not_found", "error_msg": "订单不存在或已完成", "data": null }`} language="json" />
+        <div>
+          <DocsTable>
+            <DocsTableHeader>
+              <DocsTableRow>
+                <DocsTableHead>HTTP 状态码</DocsTableHead>
+                <DocsTableHead>error_code</DocsTableHead>
+                <DocsTableHead>说明</DocsTableHead>
+              </DocsTableRow>
+            </DocsTableHeader>
+            <DocsTableBody>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">400</DocsTableCell>
+                <DocsTableCell className="font-mono text-xs">invalid_request</DocsTableCell>
+                <DocsTableCell>参数不合法</DocsTableCell>
+              </DocsTableRow>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">401</DocsTableCell>
+                <DocsTableCell className="font-mono text-xs">unauthorized</DocsTableCell>
+                <DocsTableCell>鉴权失败</DocsTableCell>
+              </DocsTableRow>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">403</DocsTableCell>
+                <DocsTableCell className="font-mono text-xs">merchant_unavailable</DocsTableCell>
+                <DocsTableCell>应用所属账户不可用</DocsTableCell>
+              </DocsTableRow>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">404</DocsTableCell>
+                <DocsTableCell className="font-mono text-xs">order_not_found</DocsTableCell>
+                <DocsTableCell>服务不存在</DocsTableCell>
+              </DocsTableRow>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">409</DocsTableCell>
+                <DocsTableCell className="font-mono text-xs">order_not_closable</DocsTableCell>
+                <DocsTableCell>仅待认证的服务可关闭</DocsTableCell>
+              </DocsTableRow>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">409</DocsTableCell>
+                <DocsTableCell className="font-mono text-xs">order_not_refundable</DocsTableCell>
+                <DocsTableCell>服务状态不允许退回</DocsTableCell>
+              </DocsTableRow>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">409</DocsTableCell>
+                <DocsTableCell className="font-mono text-xs">refund_amount_exceeded</DocsTableCell>
+                <DocsTableCell>累计退回超过原积分数量</DocsTableCell>
+              </DocsTableRow>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">409</DocsTableCell>
+                <DocsTableCell className="font-mono text-xs">refund_no_conflict</DocsTableCell>
+                <DocsTableCell>refund_no 已用于其他退回</DocsTableCell>
+              </DocsTableRow>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">409</DocsTableCell>
+                <DocsTableCell className="font-mono text-xs">merchant_order_no_used</DocsTableCell>
+                <DocsTableCell>merchant_order_no 已被使用或订单信息不一致</DocsTableCell>
+              </DocsTableRow>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">500</DocsTableCell>
+                <DocsTableCell className="font-mono text-xs">internal_error</DocsTableCell>
+                <DocsTableCell>服务器内部错误</DocsTableCell>
+              </DocsTableRow>
+            </DocsTableBody>
+          </DocsTable>
         </div>
       </div>
     ),
+    children: [
+      { value: "1-1-auth", title: "1.1 鉴权" },
+      { value: "1-2-endpoints", title: "1.2 接口列表" },
+      { value: "1-3-errors", title: "1.3 错误码" },
+    ]
   },
   {
     value: "epay-compatibility",
@@ -69,7 +197,7 @@ export const apiSections: PolicySection[] = [
         <ul className="list-disc pl-4 md:pl-5 space-y-2 mb-4">
           <li><code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">pid</code>：Client ID</li>
           <li><code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">key</code>：Client Secret（妥善保管）</li>
-          <li><code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">notify_url</code>：回调地址, 使用创建应用时设置的 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">notify_url</code>；请求体中的 notify_url 仅参与签名，不会覆盖创建应用时设置的 notify_url。</li>
+          <li><code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">notify_url</code>：回调地址, 默认使用创建应用时设置的 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">notify_url</code>；请求体中传入 notify_url 时，该服务的异步通知将发送到请求中的地址，创建时按与应用回调地址相同的规则校验，不允许指向内网等受限地址。</li>
         </ul>
 
         <h4 className="font-medium text-foreground mt-3 md:mt-4 mb-2">2.4.2 签名算法</h4>
@@ -87,6 +215,26 @@ sign=$(echo -n "\${payload}\${SECRET}" | md5)  # 输出小写`}
           />
         </div>
 
+        <h4 className="font-medium text-foreground mt-3 md:mt-4 mb-2">2.4.3 LDPay 原生协议（HMAC-SHA256）</h4>
+        <div className="space-y-4">
+          <ul className="list-disc pl-4 md:pl-5 space-y-2">
+            <li>以 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">Content-Type: application/json</code> 调用 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">/pay/submit.php</code>，请求体中 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">type</code> 固定为 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">ldpay</code>，所有字段值均为字符串</li>
+            <li>除 2.5 中的业务字段外，还需携带 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">timestamp</code>（Unix 秒级时间戳，与服务器偏差不超过 5 分钟）和 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">nonce</code>（8~64 位随机串，同一应用内不可重复使用）</li>
+            <li>异步通知以 JSON POST 方式发送，同步跳转参数附加在 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">return_url</code> 上，均按同一规则签名，<code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">sign_type</code> 为 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">HMAC-SHA256</code></li>
+          </ul>
+          <ol className="list-decimal pl-4 md:pl-5 space-y-2">
+            <li>取请求体中除 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">sign</code> 外的全部字段（含空值）</li>
+            <li>按 key 的 UTF-8 字节序升序序列化为紧凑 JSON（无空白），key 与 value 均为 JSON 字符串</li>
+            <li>字符串转义规则：<code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">"</code> 与 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">\</code> 加反斜杠转义，<code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">\b \f \n \r \t</code> 使用短转义，其余 U+0000~U+001F 控制字符写作 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">\u00xx</code>（小写十六进制）；其他字符（含 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">&lt; &gt; &amp;</code>、U+2028/U+2029 与中文等非 ASCII 字符）按原始 UTF-8 输出，不做任何转义，与 JavaScript <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">JSON.stringify</code> 的输出一致</li>
+            <li>以应用密钥为 key 计算 HMAC-SHA256，取小写十六进制作为 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">sign</code></li>
+          </ol>
+          <CodeBlock
+            code={`payload='{"money":"10","name":"Test","nonce":"c0ffee42","out_trade_no":"M20250101","pid":"001","timestamp":"1735689600","type":"ldpay"}'
+sign=$(echo -n "\${payload}" | openssl dgst -sha256 -hmac "\${SECRET}" | awk '{print $2}')`}
+            language="bash"
+          />
+        </div>
+
         <h3 id="2-5-submit" className="text-base md:text-lg font-semibold text-foreground mt-6 md:mt-8 mb-3 md:mb-4">2.5 积分流转服务</h3>
         <ul className="list-disc pl-4 md:pl-5 space-y-2 mb-6">
           <li><strong>方法：</strong>POST <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">/pay/submit.php</code></li>
@@ -116,8 +264,8 @@ sign=$(echo -n "\${payload}\${SECRET}" | md5)  # 输出小写`}
             </DocsTableRow>
             <DocsTableRow>
               <DocsTableCell className="font-mono text-xs">out_trade_no</DocsTableCell>
-              <DocsTableCell>否</DocsTableCell>
-              <DocsTableCell>业务单号，建议全局唯一</DocsTableCell>
+              <DocsTableCell>是</DocsTableCell>
+              <DocsTableCell>业务单号，同一应用内唯一；重复提交且标题、积分数量一致时返回原待认证服务，不一致或原服务已结束时拒绝</DocsTableCell>
             </DocsTableRow>
             <DocsTableRow>
               <DocsTableCell className="font-mono text-xs">name</DocsTableCell>
@@ -132,12 +280,12 @@ sign=$(echo -n "\${payload}\${SECRET}" | md5)  # 输出小写`}
             <DocsTableRow>
               <DocsTableCell className="font-mono text-xs">notify_url</DocsTableCell>
               <DocsTableCell>否</DocsTableCell>
-              <DocsTableCell>仅参与签名，不会覆盖创建应用时设置的 notify_url</DocsTableCell>
+              <DocsTableCell>异步通知地址，传入时覆盖创建应用时设置的 notify_url</DocsTableCell>
             </DocsTableRow>
             <DocsTableRow>
               <DocsTableCell className="font-mono text-xs">return_url</DocsTableCell>
               <DocsTableCell>否</DocsTableCell>
-              <DocsTableCell>仅参与签名，不会覆盖创建应用时设置的 return_url</DocsTableCell>
+              <DocsTableCell>认证成功后的跳转地址，跳转时携带与异步通知相同的参数及签名</DocsTableCell>
             </DocsTableRow>
             <DocsTableRow>
               <DocsTableCell className="font-mono text-xs">device</DocsTableCell>
@@ -175,7 +323,7 @@ sign=$(echo -n "\${payload}\${SECRET}" | md5)  # 输出小写`}
         <ul className="list-disc pl-4 md:pl-5 space-y-2 mb-6">
           <li><strong>方法：</strong>GET <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">/api.php</code></li>
           <li><strong>认证：</strong><code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">pid</code> + <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">key</code></li>
-          <li><strong>说明：</strong><code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">trade_no</code> 必填；<code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">out_trade_no</code> 可选；<code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">act</code> 可传 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">order</code>，后端不强校验。</li>
+          <li><strong>说明：</strong><code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">trade_no</code> 与 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">out_trade_no</code> 至少传一个，同时传入时以 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">trade_no</code> 为准；<code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">act</code> 不传时默认为 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">order</code></li>
         </ul>
 
         <DocsTable>
@@ -190,7 +338,7 @@ sign=$(echo -n "\${payload}\${SECRET}" | md5)  # 输出小写`}
             <DocsTableRow>
               <DocsTableCell className="font-mono text-xs">act</DocsTableCell>
               <DocsTableCell>否</DocsTableCell>
-              <DocsTableCell>可选字段，建议 <code className="bg-muted px-1 rounded text-xs before:content-none after:content-none">order</code></DocsTableCell>
+              <DocsTableCell><code className="bg-muted px-1 rounded text-xs before:content-none after:content-none">order</code>（默认）</DocsTableCell>
             </DocsTableRow>
             <DocsTableRow>
               <DocsTableCell className="font-mono text-xs">pid</DocsTableCell>
@@ -204,7 +352,7 @@ sign=$(echo -n "\${payload}\${SECRET}" | md5)  # 输出小写`}
             </DocsTableRow>
             <DocsTableRow>
               <DocsTableCell className="font-mono text-xs">trade_no</DocsTableCell>
-              <DocsTableCell>是</DocsTableCell>
+              <DocsTableCell>否</DocsTableCell>
               <DocsTableCell>编号</DocsTableCell>
             </DocsTableRow>
             <DocsTableRow>
@@ -232,13 +380,109 @@ sign=$(echo -n "\${payload}\${SECRET}" | md5)  # 输出小写`}
 }`}
           language="json"
         />
-        <p className="text-muted-foreground text-xs"><strong className="text-foreground">补充：</strong><code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">status</code> 1=成功，0=失败/处理中；不存在会返回 HTTP 404 且 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">{`{"code":-1,"msg":"服务不存在或已完成"}`}</code>。</p>
+        <p className="text-muted-foreground text-xs"><strong className="text-foreground">补充：</strong><code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">status</code> 1=成功（含部分退回），0=失败/处理中；不存在会返回 HTTP 404 且 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">{`{"code":-1,"msg":"服务不存在或已完成"}`}</code>。</p>
+
+        <h4 className="font-medium text-foreground mt-3 md:mt-4 mb-2">批量查询（act=orders）</h4>
+        <ul className="list-disc pl-4 md:pl-5 space-y-2 mb-6">
+          <li><strong>方法：</strong>GET <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">/api.php?act=orders</code>，仅返回当前应用的积分流转服务，按创建时间倒序</li>
+        </ul>
+
+        <DocsTable>
+          <DocsTableHeader>
+            <DocsTableRow>
+              <DocsTableHead>参数</DocsTableHead>
+              <DocsTableHead>必填</DocsTableHead>
+              <DocsTableHead>说明</DocsTableHead>
+            </DocsTableRow>
+          </DocsTableHeader>
+          <DocsTableBody>
+            <DocsTableRow>
+              <DocsTableCell className="font-mono text-xs">pid / key</DocsTableCell>
+              <DocsTableCell>是</DocsTableCell>
+              <DocsTableCell>Client ID / Client Secret</DocsTableCell>
+            </DocsTableRow>
+            <DocsTableRow>
+              <DocsTableCell className="font-mono text-xs">page</DocsTableCell>
+              <DocsTableCell>否</DocsTableCell>
+              <DocsTableCell>页码，默认 1</DocsTableCell>
+            </DocsTableRow>
+            <DocsTableRow>
+              <DocsTableCell className="font-mono text-xs">limit</DocsTableCell>
+              <DocsTableCell>否</DocsTableCell>
+              <DocsTableCell>每页数量，默认 20，最大 100</DocsTableCell>
+            </DocsTableRow>
+            <DocsTableRow>
+              <DocsTableCell className="font-mono text-xs">status</DocsTableCell>
+              <DocsTableCell>否</DocsTableCell>
+              <DocsTableCell>服务状态，如 <code className="bg-muted px-1 rounded text-xs before:content-none after:content-none">success</code>、<code className="bg-muted px-1 rounded text-xs before:content-none after:content-none">pending</code>、<code className="bg-muted px-1 rounded text-xs before:content-none after:content-none">refund</code></DocsTableCell>
+            </DocsTableRow>
+            <DocsTableRow>
+              <DocsTableCell className="font-mono text-xs">start_time / end_time</DocsTableCell>
+              <DocsTableCell>否</DocsTableCell>
+              <DocsTableCell>创建时间范围，格式 <code className="bg-muted px-1 rounded text-xs before:content-none after:content-none">2006-01-02 15:04:05</code></DocsTableCell>
+            </DocsTableRow>
+          </DocsTableBody>
+        </DocsTable>
+
+        <p className="text-muted-foreground mb-2">成功响应：</p>
+        <CodeBlock
+          code={`{
+  "code": 1,
+  "msg": "查询订单成功",
+  "total": 1,
+  "page": 1,
+  "limit": 20,
+  "data": [{ "trade_no": "...", "out_trade_no": "M20250101", "money": "10.00", "status": 1, ... }]
+}`}
+          language="json"
+        />
 
-        <h3 id="2-7-refund" className="text-base md:text-lg font-semibold text-foreground mt-6 md:mt-8 mb-3 md:mb-4">2.7 订单退款</h3>
+        <h4 className="font-medium text-foreground mt-3 md:mt-4 mb-2">商户信息（act=query）</h4>
+        <ul className="list-disc pl-4 md:pl-5 space-y-2 mb-6">
+          <li><strong>方法：</strong>GET <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">/api.php?act=query&pid=...&key=...</code></li>
+          <li><strong>返回：</strong><code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">active</code> 账户是否可用、<code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">money</code> 可用积分、<code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">fee_rate</code> 当前手续费率、<code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">orders</code> / <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">order_today</code> / <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">order_lastday</code> 累计、今日、昨日已认证服务数</li>
+        </ul>
+        <CodeBlock
+          code={`{
+  "code": 1,
+  "msg": "查询商户信息成功",
+  "pid": "001",
+  "active": 1,
+  "money": "100.00",
+  "total_receive": "1000.00",
+  "pay_level": 1,
+  "fee_rate": "0.05",
+  "orders": 10,
+  "order_today": 2,
+  "order_lastday": 3
+}`}
+          language="json"
+        />
+
+        <h4 className="font-medium text-foreground mt-3 md:mt-4 mb-2">结算汇总（act=settle）</h4>
+        <ul className="list-disc pl-4 md:pl-5 space-y-2 mb-6">
+          <li><strong>方法：</strong>GET <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">/api.php?act=settle&pid=...&key=...&days=7</code></li>
+          <li><strong>说明：</strong>按认证日期汇总最近 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">days</code> 天（默认 7，最大 90）的积分数量与退回数量，<code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">net_money</code> = <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">money</code> - <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">refund_money</code></li>
+        </ul>
+        <CodeBlock
+          code={`{
+  "code": 1,
+  "msg": "查询结算记录成功",
+  "orders": 3,
+  "money": "30.00",
+  "refund_money": "5.00",
+  "net_money": "25.00",
+  "data": [{ "date": "2025-01-01", "orders": 3, "money": "30.00", "refund_money": "5.00", "net_money": "25.00" }]
+}`}
+          language="json"
+        />
+
+        <h3 id="2-7-refund" className="text-base md:text-lg font-semibold text-foreground mt-6 md:mt-8 mb-3 md:mb-4">2.7 订单退款与关闭</h3>
         <ul className="list-disc pl-4 md:pl-5 space-y-2 mb-6">
           <li><strong>方法：</strong>POST <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">/api.php</code></li>
           <li><strong>编码：</strong><code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">application/json</code> 或 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">application/x-www-form-urlencoded</code></li>
-          <li><strong>限制：</strong>仅支持对已成功的积分流转服务进行积分的全额退回</li>
+          <li><strong>限制：</strong>仅支持对已成功的积分流转服务退回积分；可多次部分退回，累计退回积分不超过原积分数量</li>
+          <li><strong>幂等：</strong>同一 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">out_refund_no</code> 重复请求不会重复退回；不传时以 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">trade_no</code> 作为退款单号，仅可一次性退回剩余全部积分；部分退回时必须传入</li>
         </ul>
 
         <div>
@@ -269,27 +513,89 @@ sign=$(echo -n "\${payload}\${SECRET}" | md5)  # 输出小写`}
               <DocsTableRow>
                 <DocsTableCell className="font-mono text-xs">money</DocsTableCell>
                 <DocsTableCell>是</DocsTableCell>
-                <DocsTableCell>必须等于原积分流转服务的积分数量</DocsTableCell>
+                <DocsTableCell>本次退回的积分数量，不超过剩余可退积分数量</DocsTableCell>
               </DocsTableRow>
               <DocsTableRow>
                 <DocsTableCell className="font-mono text-xs">out_trade_no</DocsTableCell>
                 <DocsTableCell>否</DocsTableCell>
                 <DocsTableCell>业务单号（兼容）</DocsTableCell>
               </DocsTableRow>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">out_refund_no</DocsTableCell>
+                <DocsTableCell>否</DocsTableCell>
+                <DocsTableCell>商户退款单号，最多 64 字符，用于幂等；部分退回时必填</DocsTableCell>
+              </DocsTableRow>
             </DocsTableBody>
           </DocsTable>
         </div>
 
         <p className="text-muted-foreground mb-2">响应：</p>
-        <CodeBlock code={`{ "code": 1, "msg": "退款成功" }`} language="json" />
-        <p className="text-muted-foreground text-xs"><strong className="text-foreground">常见失败：</strong>服务不存在/未认证、金额不合法（&lt;=0 或小数超过 2 位）。</p>
+        <CodeBlock code={`{
+  "code": 1,
+  "msg": "退款成功",
+  "out_refund_no": "R20240101001",
+  "refunded_money": "10.00",
+  "status": "partially_refunded"
+}`} language="json" />
+        <p className="text-muted-foreground text-xs"><strong className="text-foreground">常见失败：</strong>服务不存在/未认证、金额不合法（&lt;=0 或小数超过 2 位）、累计退回超过原积分数量、<code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">out_refund_no</code> 已用于其他退款。</p>
+
+        <h4 className="font-medium text-foreground mt-3 md:mt-4 mb-2">关闭订单（act=close）</h4>
+        <ul className="list-disc pl-4 md:pl-5 space-y-2 mb-6">
+          <li><strong>方法：</strong>POST <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">/api.php</code>，<code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">act</code> 可放在表单字段或查询参数中</li>
+          <li><strong>限制：</strong>仅待支付的服务可关闭；关闭后认证页面不可再支付，同一业务单号不可再次使用</li>
+        </ul>
+
+        <div>
+          <DocsTable>
+            <DocsTableHeader>
+              <DocsTableRow>
+                <DocsTableHead>参数</DocsTableHead>
+                <DocsTableHead>必填</DocsTableHead>
+                <DocsTableHead>说明</DocsTableHead>
+              </DocsTableRow>
+            </DocsTableHeader>
+            <DocsTableBody>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">act</DocsTableCell>
+                <DocsTableCell>是</DocsTableCell>
+                <DocsTableCell>固定为 close</DocsTableCell>
+              </DocsTableRow>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">pid</DocsTableCell>
+                <DocsTableCell>是</DocsTableCell>
+                <DocsTableCell>Client ID</DocsTableCell>
+              </DocsTableRow>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">key</DocsTableCell>
+                <DocsTableCell>是</DocsTableCell>
+                <DocsTableCell>Client Secret</DocsTableCell>
+              </DocsTableRow>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">trade_no</DocsTableCell>
+                <DocsTableCell>二选一</DocsTableCell>
+                <DocsTableCell>编号</DocsTableCell>
+              </DocsTableRow>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">out_trade_no</DocsTableCell>
+                <DocsTableCell>二选一</DocsTableCell>
+                <DocsTableCell>业务单号</DocsTableCell>
+              </DocsTableRow>
+            </DocsTableBody>
+          </DocsTable>
+        </div>
+
+        <p className="text-muted-foreground mb-2">响应：</p>
+        <CodeBlock code={`{ "code": 1, "msg": "关闭订单成功" }`} language="json" />
+        <p className="text-muted-foreground text-xs"><strong className="text-foreground">常见失败：</strong>服务不存在/未认证、服务已完成或已过期（<code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">code</code> 为 -1）。</p>
 
 
         <h3 id="2-8-notify" className="text-base md:text-lg font-semibold text-foreground mt-6 md:mt-8 mb-3 md:mb-4">2.8 异步通知（认证成功）</h3>
         <ul className="list-disc pl-4 md:pl-5 space-y-2 mb-6">
           <li><strong>触发：</strong>认证成功后；失败自动重试，最多 5 次（单次 30s 超时）</li>
-          <li><strong>目标：</strong>创建应用时设置的 notify_url</li>
-          <li><strong>方式：</strong>HTTP GET</li>
+          <li><strong>目标：</strong>创建服务时传入的 notify_url，未传入时使用创建应用时设置的 notify_url</li>
+          <li><strong>方式：</strong>HTTP GET；LDPay 原生协议创建的服务为 JSON POST（见 2.4.3）</li>
+          <li><strong>限制：</strong>通知地址须为公网可访问的 http/https 地址，不支持回环、内网等地址；重定向最多跟随 3 次，响应体仅读取前 64KB</li>
+          <li><strong>失败处理：</strong>重试耗尽后服务标记为回调失败（<code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">notify_failed</code>），应用所有者会收到站内通知，可在集市中心对应应用中一键重试全部失败通知，重试成功后标记自动清除</li>
         </ul>
 
         <div>
@@ -341,6 +647,108 @@ sign=$(echo -n "\${payload}\${SECRET}" | md5)  # 输出小写`}
           </DocsTable>
         </div>
         <p className="text-muted-foreground text-xs">应用需返回 HTTP 200 且响应体为 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">success</code>（大小写不敏感），否则视为失败并继续重试。</p>
+
+        <h4 className="font-medium text-foreground mt-3 md:mt-4 mb-2">事件通知</h4>
+        <ul className="list-disc pl-4 md:pl-5 space-y-2 mb-6">
+          <li><strong>订阅：</strong>在应用设置中选择需要推送的事件，默认仅订阅 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">order.paid</code>（即上述认证成功通知，参数格式保持不变）</li>
+          <li><strong>方式：</strong>与认证成功通知相同，发送到同一地址，签名方式与创建服务时使用的协议一致；应答与重试规则相同</li>
+          <li><strong>识别：</strong>除 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">order.paid</code> 外，通知参数中包含 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">event</code> 字段标识事件类型，<code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">status</code> 为事件发生后的服务状态</li>
+        </ul>
+
+        <div>
+          <DocsTable>
+            <DocsTableHeader>
+              <DocsTableRow>
+                <DocsTableHead>事件</DocsTableHead>
+                <DocsTableHead>说明</DocsTableHead>
+              </DocsTableRow>
+            </DocsTableHeader>
+            <DocsTableBody>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">order.paid</DocsTableCell>
+                <DocsTableCell>积分流转成功</DocsTableCell>
+              </DocsTableRow>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">order.refunded</DocsTableCell>
+                <DocsTableCell>积分退回（商户退款或争议退款），附带 refund_money、refunded_money，商户退款时附带 out_refund_no，争议退款时附带 dispute_id</DocsTableCell>
+              </DocsTableRow>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">order.expired</DocsTableCell>
+                <DocsTableCell>服务超时未完成，已过期</DocsTableCell>
+              </DocsTableRow>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">dispute.opened</DocsTableCell>
+                <DocsTableCell>用户发起争议，附带 dispute_id、dispute_reason</DocsTableCell>
+              </DocsTableRow>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">dispute.resolved</DocsTableCell>
+                <DocsTableCell>争议处理完成，附带 dispute_id、dispute_status（refund 已退回 / closed 已关闭）</DocsTableCell>
+              </DocsTableRow>
+            </DocsTableBody>
+          </DocsTable>
+        </div>
+
+        <h4 className="font-medium text-foreground mt-3 md:mt-4 mb-2">JSON 格式通知</h4>
+        <ul className="list-disc pl-4 md:pl-5 space-y-2 mb-6">
+          <li><strong>启用：</strong>在应用设置中将通知格式切换为 JSON，默认仍为易支付兼容的 GET 格式</li>
+          <li><strong>方式：</strong>HTTP POST，<code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">Content-Type: application/json</code>，参数不出现在查询字符串中</li>
+          <li><strong>签名：</strong><code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">X-Credit-Signature</code> = HMAC-SHA256(secret, <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">X-Credit-Timestamp</code> + &quot;.&quot; + 原始请求体)，十六进制小写；建议校验时间戳与当前时间偏差不超过 5 分钟</li>
+          <li><strong>去重：</strong><code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">id</code>（同 <code className="bg-muted px-1.5 py-0.5 rounded text-xs font-mono before:content-none after:content-none">X-Credit-Event-Id</code>）在重试时保持不变，可据此对通知去重</li>
+          <li><strong>应答：</strong>返回任意 2xx 状态码即视为成功，否则按相同规则重试</li>
+        </ul>
+
+        <div>
+          <DocsTable>
+            <DocsTableHeader>
+              <DocsTableRow>
+                <DocsTableHead>请求头</DocsTableHead>
+                <DocsTableHead>说明</DocsTableHead>
+              </DocsTableRow>
+            </DocsTableHeader>
+            <DocsTableBody>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">X-Credit-Event-Id</DocsTableCell>
+                <DocsTableCell>事件 ID</DocsTableCell>
+              </DocsTableRow>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">X-Credit-Event</DocsTableCell>
+                <DocsTableCell>事件类型</DocsTableCell>
+              </DocsTableRow>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">X-Credit-Timestamp</DocsTableCell>
+                <DocsTableCell>签名时间戳（Unix 秒）</DocsTableCell>
+              </DocsTableRow>
+              <DocsTableRow>
+                <DocsTableCell className="font-mono text-xs">X-Credit-Signature</DocsTableCell>
+                <DocsTableCell>签名</DocsTableCell>
+              </DocsTableRow>
+            </DocsTableBody>
+          </DocsTable>
+        </div>
+
+        <p className="text-muted-foreground mb-2">请求体示例：</p>
+        <CodeBlock code={`{
+  "id": "evt_0f8e6a52-3c1d-4b7a-9f2e-6d5c4b3a2190",
+  "event": "order.refunded",
+  "created_at": 1735689600,
+  "data": {
+    "out_refund_no": "R20240101001",
+    "refund_money": "10.00",
+    "refunded_money": "10.00",
+    "order": {
+      "trade_no": "123456789",
+      "out_trade_no": "M20240101001",
+      "pid": "your_client_id",
+      "type": "epay",
+      "name": "测试服务",
+      "money": "30.00",
+      "refunded_money": "10.00",
+      "status": "partially_refunded",
+      "created_at": "2025-01-01T08:00:00+08:00",
+      "trade_time": "2025-01-01T08:01:00+08:00"
+    }
+  }
+}`} language="json" />
       </div>
     ),
     children: [
@@ -350,7 +758,7 @@ sign=$(echo -n "\${payload}\${SECRET}" | md5)  # 输出小写`}
       { value: "2-4-auth-sign", title: "2.4 鉴权与签名" },
       { value: "2-5-submit", title: "2.5 积分流转服务" },
       { value: "2-6-order", title: "2.6 订单查询" },
-      { value: "2-7-refund", title: "2.7 订单退款" },
+      { value: "2-7-refund", title: "2.7 订单退款与关闭" },
       { value: "2-8-notify", title: "2.8 异步通知" },
     ]
   },
@@ -207,6 +207,7 @@ export const howToUseSections: PolicySection[] = [
           <li><strong>承担方：</strong>服务（手续）费默认<strong>由服务方承担</strong></li>
           <li><strong>消费方使用：</strong>不会产生额外费用</li>
           <li><strong>服务方实收：</strong>订单金额 - 服务（手续）费</li>
+          <li><strong>退款：</strong>默认按退款金额占订单金额的比例退还服务（手续）费，服务方只需退回实际到账的部分；具体以平台当前的退款手续费策略为准</li>
         </ul>
         <div className="mt-4">
           <p className="font-mono text-xs mb-2 text-muted-foreground">计算公式：</p>
@@ -0,0 +1,115 @@
+"use client"
+
+import * as React from "react"
+import { useState } from "react"
+import {
+  Dialog,
+  DialogContent,
+} from "@/components/ui/dialog"
+import { Input } from "@/components/ui/input"
+import { Button } from "@/components/ui/button"
+import { Spinner } from "@/components/ui/spinner"
+import type { User } from "@/lib/services"
+
+interface TOTPDialogProps {
+  isOpen: boolean
+  onOpenChange: (open: boolean) => void
+  onConfirm: (code: string) => void
+  loading?: boolean
+  title?: string
+  description?: string
+}
+
+/**
+ * 判断本次支付是否需要两步验证
+ * 用户开启两步验证且金额超过生效阈值时需要
+ */
+export function requiresTOTP(user: User | null, amount: number | string): boolean {
+  if (!user?.totp_enabled || user.totp_threshold === null) {
+    return false
+  }
+  return parseFloat(String(amount)) > parseFloat(user.totp_threshold)
+}
+
+/**
+ * 两步验证对话框
+ * 
+ * 用于大额支付前输入验证器中的验证码，验证器不可用时可输入恢复码
+ */
+export function TOTPDialog({
+  isOpen,
+  onOpenChange,
+  onConfirm,
+  loading = false,
+  title = "两步验证",
+  description = "该笔支付超过两步验证阈值，请输入验证器中的6位验证码"
+}: TOTPDialogProps) {
+  const [code, setCode] = useState("")
+
+  const trimmed = code.trim()
+  const isValid = /^\d{6}$/.test(trimmed) || /^[a-z2-7]{5}-?[a-z2-7]{5}$/i.test(trimmed)
+
+  const handleConfirm = () => {
+    if (isValid) {
+      onConfirm(trimmed)
+    }
+  }
+
+  /* 当对话框关闭时重置验证码*/
+  React.useEffect(() => {
+    if (!isOpen) {
+      setCode("")
+    }
+  }, [isOpen])
+
+  return (
+    <Dialog open={isOpen} onOpenChange={onOpenChange}>
+      <DialogContent>
+        <div className="flex flex-col items-center space-y-6">
+          <div className="text-center">
+            <h2 className="text-lg font-semibold">{title}</h2>
+            <p className="text-sm text-muted-foreground mt-2">
+              {description}
+            </p>
+          </div>
+
+          <div className="w-full space-y-2">
+            <Input
+              value={code}
+              onChange={(e) => setCode(e.target.value)}
+              placeholder="6位验证码或恢复码"
+              maxLength={16}
+              autoComplete="one-time-code"
+              disabled={loading}
+              className="h-9 text-center font-mono tracking-widest"
+              onKeyDown={(e) => {
+                if (e.key === 'Enter' && isValid && !loading) {
+                  handleConfirm()
+                }
+              }}
+            />
+            <p className="text-xs text-muted-foreground text-center">
+              验证器不可用时，可输入一个未使用的恢复码
+            </p>
+          </div>
+
+          <Button
+            type="button"
+            className="w-full bg-primary hover:bg-primary/90 h-8 text-xs"
+            onClick={handleConfirm}
+            disabled={!isValid || loading}
+          >
+            {loading ? (
+              <>
+                <Spinner className="mr-2 h-4 w-4" />
+                验证中...
+              </>
+            ) : (
+              "确认"
+            )}
+          </Button>
+        </div>
+      </DialogContent>
+    </Dialog>
+  )
+}
@@ -172,7 +172,9 @@ const getStatusText = (status: string) => {
     expired: '已过期',
     disputing: '争议中',
     refund: '已退回',
-    refused: '已拒绝'
+    refused: '已拒绝',
+    partially_refunded: '部分退回',
+    closed: '已关闭'
   }
   return statusMap[status] || status
 }
@@ -7,8 +7,19 @@ import { Input } from "@/components/ui/input"
 import { Label } from "@/components/ui/label"
 import { Button } from "@/components/ui/button"
 import { Spinner } from "@/components/ui/spinner"
+import { Checkbox } from "@/components/ui/checkbox"
+import { Select, SelectContent, SelectItem, SelectTrigger, SelectValue } from "@/components/ui/select"
 import { Dialog, DialogClose, DialogContent, DialogDescription, DialogFooter, DialogHeader, DialogTitle, DialogTrigger } from "@/components/ui/dialog"
-import services, { type MerchantAPIKey, type CreateAPIKeyRequest, type UpdateAPIKeyRequest } from "@/lib/services"
+import services, { type MerchantAPIKey, type CreateAPIKeyRequest, type UpdateAPIKeyRequest, type WebhookEvent, type CallbackFormat } from "@/lib/services"
+
+/** 可订阅的 Webhook 事件 */
+const WEBHOOK_EVENT_OPTIONS: { value: WebhookEvent; label: string }[] = [
+  { value: 'order.paid', label: '积分流转成功' },
+  { value: 'order.refunded', label: '积分退回' },
+  { value: 'order.expired', label: '服务过期' },
+  { value: 'dispute.opened', label: '争议发起' },
+  { value: 'dispute.resolved', label: '争议处理完成' },
+]
 
 interface MerchantDialogProps {
   /** 模式：创建或更新 */
@@ -49,6 +60,8 @@ export function MerchantDialog({
     app_description: '',
     redirect_uri: '',
     notify_url: '',
+    webhook_events: ['order.paid'],
+    callback_format: 'epay',
   })
 
   useEffect(() => {
@@ -66,6 +79,8 @@ export function MerchantDialog({
         app_description: apiKey.app_description,
         redirect_uri: apiKey.redirect_uri,
         notify_url: apiKey.notify_url,
+        webhook_events: apiKey.webhook_events ?? ['order.paid'],
+        callback_format: apiKey.callback_format ?? 'epay',
       }
     }
     return {
@@ -74,6 +89,8 @@ export function MerchantDialog({
       app_description: '',
       redirect_uri: '',
       notify_url: '',
+      webhook_events: ['order.paid'],
+      callback_format: 'epay',
     }
   }, [mode, apiKey])
 
@@ -94,6 +111,14 @@ export function MerchantDialog({
     }
   }
 
+  const toggleWebhookEvent = (event: WebhookEvent, checked: boolean) => {
+    const current = formData.webhook_events ?? []
+    setFormData({
+      ...formData,
+      webhook_events: checked ? [...current, event] : current.filter((e) => e !== event),
+    })
+  }
+
   const resetForm = () => {
     setFormData(getInitialFormData())
   }
@@ -264,6 +289,44 @@ export function MerchantDialog({
             <p className="text-xs text-muted-foreground">URL 必须为包含 http:// 或 https:// ，用于接收积分服务成功的异步通知</p>
           </div>
 
+          <div className="grid gap-2">
+            <Label htmlFor="callback_format">通知格式</Label>
+            <Select
+              value={formData.callback_format}
+              onValueChange={(value) => setFormData({ ...formData, callback_format: value as CallbackFormat })}
+              disabled={processing}
+            >
+              <SelectTrigger id="callback_format" className="w-full h-9 text-xs">
+                <SelectValue />
+              </SelectTrigger>
+              <SelectContent>
+                <SelectItem value="epay" className="text-xs">易支付兼容（GET 查询参数，MD5 签名）</SelectItem>
+                <SelectItem value="json" className="text-xs">JSON（POST 请求体，HMAC-SHA256 签名请求头）</SelectItem>
+              </SelectContent>
+            </Select>
+            <p className="text-xs text-muted-foreground">JSON 格式不会在查询字符串中暴露参数，并提供事件 ID 用于去重</p>
+          </div>
+
+          <div className="grid gap-2">
+            <Label>订阅事件</Label>
+            <div className="grid grid-cols-2 gap-2">
+              {WEBHOOK_EVENT_OPTIONS.map((option) => (
+                <div key={option.value} className="flex items-center space-x-2">
+                  <Checkbox
+                    id={`webhook_event_${ option.value }`}
+                    checked={formData.webhook_events?.includes(option.value) ?? false}
+                    onCheckedChange={(checked) => toggleWebhookEvent(option.value, checked === true)}
+                    disabled={processing}
+                  />
+                  <label htmlFor={`webhook_event_${ option.value }`} className="text-xs leading-none cursor-pointer">
+                    {option.label}
+                  </label>
+                </div>
+              ))}
+            </div>
+            <p className="text-xs text-muted-foreground">选择需要推送到通知 URL 的事件，默认仅推送积分流转成功</p>
+          </div>
+
           <div className="grid gap-2">
             <Label htmlFor="redirect_uri">回调 URI</Label>
             <Input
@@ -1,8 +1,8 @@
 import * as React from "react"
-import { useState } from "react"
+import { useState, useEffect, useCallback } from "react"
 import Link from "next/link"
 import { toast } from "sonner"
-import { Copy, Eye, EyeOff, Trash2, ExternalLink, Edit } from "lucide-react"
+import { Copy, Eye, EyeOff, Trash2, ExternalLink, Edit, RefreshCw } from "lucide-react"
 import { Button } from "@/components/ui/button"
 import {
   AlertDialog,
@@ -17,7 +17,7 @@ import {
 } from "@/components/ui/alert-dialog"
 import { MerchantDialog } from "@/components/common/merchant/merchant-dialog"
 import { formatDateTime } from "@/lib/utils"
-import { type UpdateAPIKeyRequest, type MerchantAPIKey } from "@/lib/services"
+import services, { type UpdateAPIKeyRequest, type MerchantAPIKey, type NotifyFailedOrder } from "@/lib/services"
 
 interface MerchantInfoProps {
   /** API Key */
@@ -37,6 +37,40 @@ interface MerchantInfoProps {
 export function MerchantInfo({ apiKey, onUpdate, onDelete, updateAPIKey }: MerchantInfoProps) {
   const [showClientId, setShowClientId] = useState(false)
   const [showClientSecret, setShowClientSecret] = useState(false)
+  const [failedOrders, setFailedOrders] = useState<NotifyFailedOrder[]>([])
+  const [failedTotal, setFailedTotal] = useState(0)
+  const [retrying, setRetrying] = useState(false)
+
+  /* 加载回调失败订单 */
+  const loadFailedOrders = useCallback(async () => {
+    try {
+      const result = await services.merchant.listNotifyFailedOrders(apiKey.id, { page: 1, page_size: 5 })
+      setFailedOrders(result.orders)
+      setFailedTotal(result.total)
+    } catch {
+      setFailedOrders([])
+      setFailedTotal(0)
+    }
+  }, [apiKey.id])
+
+  useEffect(() => {
+    loadFailedOrders()
+  }, [loadFailedOrders])
+
+  /* 重试全部失败通知 */
+  const handleRetryAll = async () => {
+    setRetrying(true)
+    try {
+      const { count, skipped_order_ids } = await services.merchant.retryNotifyFailedOrders(apiKey.id)
+      const skipped = skipped_order_ids.length > 0 ? `，${ skipped_order_ids.length } 笔订单的回调仍在重试中已跳过` : ''
+      toast.success('已重新发送回调', { description: `共 ${ count } 笔订单${ skipped }，回调成功后将自动移出列表` })
+      await loadFailedOrders()
+    } catch (error) {
+      toast.error('重试失败', { description: error instanceof Error ? error.message : '请稍后重试' })
+    } finally {
+      setRetrying(false)
+    }
+  }
 
   /* 复制到剪贴板 */
   const copyToClipboard = async (text: string, label: string) => {
@@ -195,6 +229,38 @@ export function MerchantInfo({ apiKey, onUpdate, onDelete, updateAPIKey }: Merch
         </div>
       </div>
 
+      {failedTotal > 0 && (
+        <div>
+          <h2 className="font-semibold mb-4">回调失败</h2>
+          <div className="border border-dashed border-destructive/50 rounded-lg">
+            <div className="px-3 py-2 flex items-center justify-between border-b border-dashed">
+              <label className="text-xs font-medium text-muted-foreground">失败订单</label>
+              <p className="text-xs font-medium text-destructive">{failedTotal} 笔</p>
+            </div>
+            {failedOrders.map(order => (
+              <div key={order.id} className="px-3 py-2 border-b border-dashed last:border-b-0">
+                <div className="flex items-center justify-between">
+                  <code className="text-xs font-mono">{order.order_no}</code>
+                  <span className="text-[10px] text-muted-foreground">{formatDateTime(order.created_at)}</span>
+                </div>
+                {order.last_error && (
+                  <p className="mt-1 text-[10px] text-muted-foreground truncate">{order.last_error}</p>
+                )}
+              </div>
+            ))}
+          </div>
+          <Button
+            variant="outline"
+            className="mt-2 text-xs h-8 border-dashed"
+            disabled={retrying}
+            onClick={handleRetryAll}
+          >
+            <RefreshCw className={`size-3 mr-1 ${ retrying ? 'animate-spin' : '' }`} />
+            重试全部失败通知
+          </Button>
+        </div>
+      )}
+
       <div>
         <h2 className="font-semibold mb-4">应用管理</h2>
         <div className="flex gap-2">
@@ -6,6 +6,8 @@ import { toast } from "sonner"
 import { motion } from "motion/react"
 import { PayingNow } from "@/components/common/pay/paying/paying-now"
 import { PayingInfo } from "@/components/common/pay/paying/paying-info"
+import { TOTPDialog, requiresTOTP } from "@/components/common/general/totp-dialog"
+import { useUser } from "@/contexts/user-context"
 
 import services from "@/lib/services"
 import type { GetMerchantOrderResponse } from "@/lib/services"
@@ -16,6 +18,7 @@ import type { GetMerchantOrderResponse } from "@/lib/services"
  * 通过 order_no 查询订单信息并完成积分认证
  */
 export function PayingMain() {
+  const { user } = useUser()
   /** 获取URL参数中的认证编号 */
   const searchParams = useSearchParams()
   const router = useRouter()
@@ -27,12 +30,15 @@ export function PayingMain() {
   const [error, setError] = useState(false)
   const [paying, setPaying] = useState(false)
   const [payKey, setPayKey] = useState("")
+  const [isTOTPOpen, setIsTOTPOpen] = useState(false)
   const [currentStep, setCurrentStep] = useState<'method' | 'pay'>('method')
   const [selectedMethod, setSelectedMethod] = useState<string>('')
   const [isOpen, setIsOpen] = useState(false)
 
   const timeoutRef = useRef<NodeJS.Timeout | null>(null)
   const isMountedRef = useRef(true)
+  /* 幂等键：同一订单的重试复用 */
+  const idempotencyKeyRef = useRef(crypto.randomUUID())
 
   useEffect(() => {
     isMountedRef.current = true
@@ -106,7 +112,7 @@ export function PayingMain() {
   }
 
   /** 执行积分认证操作 */
-  const handlePayOrder = async () => {
+  const handlePayOrder = async (totpCode?: string) => {
     if (!orderInfo) return
 
     if (!payKey.trim()) {
@@ -119,6 +125,12 @@ export function PayingMain() {
       return
     }
 
+    /* 超过两步验证阈值时先输入验证码 */
+    if (!totpCode && requiresTOTP(user, orderInfo.order.amount)) {
+      setIsTOTPOpen(true)
+      return
+    }
+
     setPaying(true)
     try {
       const freshOrderInfo = await services.merchant.getMerchantOrder({
@@ -131,10 +143,11 @@ export function PayingMain() {
         return
       }
 
-      await services.merchant.payMerchantOrder({
+      const payResult = await services.merchant.payMerchantOrder({
         order_no: encryptedOrderNo!,
-        pay_key: payKey
-      })
+        pay_key: payKey,
+        totp_code: totpCode
+      }, idempotencyKeyRef.current)
 
       toast.success("积分流转服务认证成功！", { id: 'payment-success' })
 
@@ -149,10 +162,15 @@ export function PayingMain() {
         })
       }
 
-      /** 5秒后跳转到redirect_uri或刷新页面 */
+      /** 5秒后跳转到return_url、redirect_uri或刷新页面 */
       timeoutRef.current = setTimeout(() => {
         if (!isMountedRef.current) return
 
+        if (payResult?.return_url) {
+          window.location.href = payResult.return_url
+          return
+        }
+
         const redirectUri = orderInfo?.merchant?.redirect_uri
         if (redirectUri && redirectUri.trim()) {
           window.location.href = redirectUri
@@ -177,6 +195,7 @@ export function PayingMain() {
       }
     } finally {
       setPaying(false)
+      setIsTOTPOpen(false)
     }
   }
 
@@ -230,11 +249,18 @@ export function PayingMain() {
               onCurrentStepChange={setCurrentStep}
               onSelectedMethodChange={setSelectedMethod}
               onIsOpenChange={setIsOpen}
-              onPayOrder={handlePayOrder}
+              onPayOrder={() => handlePayOrder()}
             />
           </motion.div>
         </div>
       </div>
+
+      <TOTPDialog
+        isOpen={isTOTPOpen}
+        onOpenChange={setIsTOTPOpen}
+        onConfirm={(code) => handlePayOrder(code)}
+        loading={paying}
+      />
     </div>
   )
 }
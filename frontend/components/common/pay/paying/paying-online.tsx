@@ -6,6 +6,7 @@ import { toast } from "sonner"
 import { motion } from "motion/react"
 import { PayingNow } from "@/components/common/pay/paying/paying-now"
 import { PayingInfo } from "@/components/common/pay/paying/paying-info"
+import { TOTPDialog, requiresTOTP } from "@/components/common/general/totp-dialog"
 import { useUser } from "@/contexts/user-context"
 
 import services from "@/lib/services"
@@ -30,12 +31,15 @@ export function PayingOnline() {
   const [error, setError] = useState(false)
   const [paying, setPaying] = useState(false)
   const [payKey, setPayKey] = useState("")
+  const [isTOTPOpen, setIsTOTPOpen] = useState(false)
   const [currentStep, setCurrentStep] = useState<'method' | 'pay'>('method')
   const [selectedMethod, setSelectedMethod] = useState<string>('')
   const [isOpen, setIsOpen] = useState(false)
 
   const timeoutRef = useRef<NodeJS.Timeout | null>(null)
   const isMountedRef = useRef(true)
+  /* 幂等键：同一次支付的重试复用，成功后重新生成 */
+  const idempotencyKeyRef = useRef(crypto.randomUUID())
 
   useEffect(() => {
     isMountedRef.current = true
@@ -169,7 +173,7 @@ export function PayingOnline() {
   }
 
   /* 执行支付操作 */
-  const handlePayOrder = async () => {
+  const handlePayOrder = async (totpCode?: string) => {
     if (!paymentLink || !token) return
 
     if (!payKey.trim()) {
@@ -182,13 +186,21 @@ export function PayingOnline() {
       return
     }
 
+    /* 超过两步验证阈值时先输入验证码 */
+    if (!totpCode && requiresTOTP(user, paymentLink.amount)) {
+      setIsTOTPOpen(true)
+      return
+    }
+
     setPaying(true)
     try {
       await services.merchant.payByLink({
         token: token,
         pay_key: payKey,
+        totp_code: totpCode,
         remark: paymentLink.remark || undefined
-      })
+      }, idempotencyKeyRef.current)
+      idempotencyKeyRef.current = crypto.randomUUID()
 
       toast.success("积分流转服务认证成功！", { id: 'payment-success' })
 
@@ -219,6 +231,7 @@ export function PayingOnline() {
       handleServiceError(error, "认证")
     } finally {
       setPaying(false)
+      setIsTOTPOpen(false)
     }
   }
 
@@ -272,11 +285,18 @@ export function PayingOnline() {
               onCurrentStepChange={setCurrentStep}
               onSelectedMethodChange={setSelectedMethod}
               onIsOpenChange={setIsOpen}
-              onPayOrder={handlePayOrder}
+              onPayOrder={() => handlePayOrder()}
             />
           </motion.div>
         </div>
       </div>
+
+      <TOTPDialog
+        isOpen={isTOTPOpen}
+        onOpenChange={setIsTOTPOpen}
+        onConfirm={(code) => handlePayOrder(code)}
+        loading={paying}
+      />
     </div>
   )
 }
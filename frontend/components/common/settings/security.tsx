@@ -8,12 +8,17 @@ import * as z from "zod"
 import { toast } from "sonner"
 import { Breadcrumb, BreadcrumbItem, BreadcrumbLink, BreadcrumbList, BreadcrumbPage, BreadcrumbSeparator } from "@/components/ui/breadcrumb"
 import { Button } from "@/components/ui/button"
-import { Form, FormControl, FormField, FormItem, FormLabel, FormMessage } from "@/components/ui/form"
+import { Form, FormControl, FormDescription, FormField, FormItem, FormLabel, FormMessage } from "@/components/ui/form"
 import { Input } from "@/components/ui/input"
+import { TOTPSettings } from "@/components/common/settings/totp-settings"
+import { useUser } from "@/contexts/user-context"
 import { UserService } from "@/lib/services/user"
 
 /* 表单验证规则 */
 const payKeySchema = z.object({
+  oldPayKey: z
+    .string()
+    .regex(/^(\d{6})?$/, "原密码必须是6位数字"),
   newPayKey: z
     .string()
     .min(6, "密码必须是6位数字")
@@ -28,11 +33,13 @@ const payKeySchema = z.object({
 type PayKeyFormValues = z.infer<typeof payKeySchema>
 
 export function SecurityMain() {
+  const { user } = useUser()
   const [isSubmitting, setIsSubmitting] = React.useState(false)
 
   const form = useForm<PayKeyFormValues>({
     resolver: zodResolver(payKeySchema),
     defaultValues: {
+      oldPayKey: "",
       newPayKey: "",
       confirmPayKey: "",
     },
@@ -41,7 +48,7 @@ export function SecurityMain() {
   const onSubmit = async (data: PayKeyFormValues) => {
     try {
       setIsSubmitting(true)
-      await UserService.updatePayKey(data.newPayKey)
+      await UserService.updatePayKey(data.newPayKey, data.oldPayKey || undefined)
 
       toast.success("修改成功", {
         description: "您的密码已成功更新",
@@ -86,6 +93,31 @@ export function SecurityMain() {
         <div>
           <Form {...form}>
             <form onSubmit={form.handleSubmit(onSubmit)} className="space-y-4 max-w-md">
+              {user?.is_pay_key && (
+                <FormField
+                  control={form.control}
+                  name="oldPayKey"
+                  render={({ field }) => (
+                    <FormItem>
+                      <FormLabel className="text-xs text-muted-foreground">原密码</FormLabel>
+                      <FormControl>
+                        <Input
+                          type="password"
+                          placeholder="请输入原6位数字密码"
+                          maxLength={6}
+                          className="h-9"
+                          {...field}
+                        />
+                      </FormControl>
+                      <FormDescription className="text-xs">
+                        忘记原密码时，可重新登录后在 10 分钟内直接设置新密码
+                      </FormDescription>
+                      <FormMessage className="text-xs" />
+                    </FormItem>
+                  )}
+                />
+              )}
+
               <FormField
                 control={form.control}
                 name="newPayKey"
@@ -138,6 +170,11 @@ export function SecurityMain() {
           </Form>
         </div>
       </div>
+
+      <div className="space-y-6">
+        <div className="font-medium text-sm text-muted-foreground">两步验证</div>
+        <TOTPSettings />
+      </div>
     </div>
   )
 }
@@ -0,0 +1,316 @@
+"use client"
+
+import * as React from "react"
+import { useCallback, useEffect, useState } from "react"
+import { toast } from "sonner"
+import { Button } from "@/components/ui/button"
+import { Input } from "@/components/ui/input"
+import { Badge } from "@/components/ui/badge"
+import { Spinner } from "@/components/ui/spinner"
+import {
+  Dialog,
+  DialogContent,
+  DialogDescription,
+  DialogFooter,
+  DialogHeader,
+  DialogTitle,
+} from "@/components/ui/dialog"
+import { PasswordDialog } from "@/components/common/general/password-dialog"
+import { TOTPDialog } from "@/components/common/general/totp-dialog"
+import { useUser } from "@/contexts/user-context"
+import { UserService } from "@/lib/services/user"
+import type { SetupTOTPResponse, TOTPStatus } from "@/lib/services"
+
+/** 需要输入两步验证码的操作 */
+type PendingAction = "threshold" | "recovery" | "disable" | null
+
+/**
+ * 两步验证设置组件
+ * 
+ * 提供验证器绑定、恢复码管理、金额阈值设置和关闭两步验证
+ */
+export function TOTPSettings() {
+  const { user, refetch } = useUser()
+  const [status, setStatus] = useState<TOTPStatus | null>(null)
+  const [loading, setLoading] = useState(false)
+
+  /* 开启流程 */
+  const [isSetupPasswordOpen, setIsSetupPasswordOpen] = useState(false)
+  const [setupInfo, setSetupInfo] = useState<SetupTOTPResponse | null>(null)
+  const [enableCode, setEnableCode] = useState("")
+
+  /* 恢复码展示 */
+  const [recoveryCodes, setRecoveryCodes] = useState<string[]>([])
+
+  /* 已开启时的操作 */
+  const [threshold, setThreshold] = useState("")
+  const [pendingAction, setPendingAction] = useState<PendingAction>(null)
+  const [isDisablePasswordOpen, setIsDisablePasswordOpen] = useState(false)
+  const [disablePayKey, setDisablePayKey] = useState("")
+
+  const fetchStatus = useCallback(async () => {
+    try {
+      const result = await UserService.getTOTPStatus()
+      setStatus(result)
+      setThreshold(result.threshold ?? "")
+    } catch (error) {
+      toast.error("获取两步验证状态失败", {
+        description: error instanceof Error ? error.message : "请稍后重试",
+      })
+    }
+  }, [])
+
+  useEffect(() => {
+    fetchStatus()
+  }, [fetchStatus])
+
+  const refresh = async () => {
+    await Promise.all([fetchStatus(), refetch()])
+  }
+
+  /* 验证支付密码后生成密钥 */
+  const handleSetup = async (payKey: string) => {
+    setLoading(true)
+    try {
+      const result = await UserService.setupTOTP({ pay_key: payKey })
+      setSetupInfo(result)
+      setIsSetupPasswordOpen(false)
+    } catch (error) {
+      toast.error("生成密钥失败", {
+        description: error instanceof Error ? error.message : "请稍后重试",
+      })
+    } finally {
+      setLoading(false)
+    }
+  }
+
+  /* 提交验证器中的验证码完成开启 */
+  const handleEnable = async () => {
+    setLoading(true)
+    try {
+      const result = await UserService.enableTOTP({ code: enableCode })
+      setSetupInfo(null)
+      setEnableCode("")
+      setRecoveryCodes(result.recovery_codes)
+      toast.success("两步验证已开启")
+      await refresh()
+    } catch (error) {
+      toast.error("开启失败", {
+        description: error instanceof Error ? error.message : "请稍后重试",
+      })
+    } finally {
+      setLoading(false)
+    }
+  }
+
+  /* 输入两步验证码后执行待处理的操作 */
+  const handleConfirmCode = async (code: string) => {
+    setLoading(true)
+    try {
+      if (pendingAction === "threshold") {
+        await UserService.updateTOTPThreshold({
+          threshold: threshold.trim() === "" ? null : threshold.trim(),
+          code,
+        })
+        toast.success("阈值已更新")
+      } else if (pendingAction === "recovery") {
+        const result = await UserService.regenerateTOTPRecoveryCodes(code)
+        setRecoveryCodes(result.recovery_codes)
+        toast.success("恢复码已重新生成")
+      } else if (pendingAction === "disable") {
+        await UserService.disableTOTP({ pay_key: disablePayKey, code })
+        toast.success("两步验证已关闭")
+      }
+      setPendingAction(null)
+      setDisablePayKey("")
+      await refresh()
+    } catch (error) {
+      toast.error("操作失败", {
+        description: error instanceof Error ? error.message : "请稍后重试",
+      })
+    } finally {
+      setLoading(false)
+    }
+  }
+
+  const handleSaveThreshold = () => {
+    const value = threshold.trim()
+    if (value !== "" && !/^\d+(\.\d{1,2})?$/.test(value)) {
+      toast.error("阈值格式不正确，必须为非负数且最多2位小数")
+      return
+    }
+    setPendingAction("threshold")
+  }
+
+  const handleCopyRecoveryCodes = async () => {
+    try {
+      await navigator.clipboard.writeText(recoveryCodes.join("\n"))
+      toast.success("已复制到剪贴板")
+    } catch {
+      toast.error("复制失败，请手动记录")
+    }
+  }
+
+  if (!user?.is_pay_key) {
+    return (
+      <p className="text-xs text-muted-foreground">请先设置支付密码，再开启两步验证。</p>
+    )
+  }
+
+  return (
+    <div className="space-y-4 max-w-md">
+      <div className="flex items-center gap-2">
+        <span className="text-sm">验证器应用</span>
+        {status?.enabled ? (
+          <Badge variant="secondary" className="text-[10px]">已开启</Badge>
+        ) : (
+          <Badge variant="outline" className="text-[10px]">未开启</Badge>
+        )}
+      </div>
+
+      {!status?.enabled ? (
+        <div className="space-y-3">
+          <p className="text-xs text-muted-foreground">
+            开启后，单笔支付或转移超过 {status?.system_threshold ?? "-"} LDC 时，需额外输入验证器中的 6 位验证码。
+          </p>
+          <div className="flex justify-end">
+            <Button size="sm" onClick={() => setIsSetupPasswordOpen(true)} disabled={!status}>
+              开启两步验证
+            </Button>
+          </div>
+        </div>
+      ) : (
+        <div className="space-y-4">
+          <p className="text-xs text-muted-foreground">
+            当前单笔支付超过 <span className="font-medium text-foreground">{status.effective_threshold} LDC</span> 需输入验证码，
+            剩余可用恢复码 <span className="font-medium text-foreground">{status.recovery_codes_remaining}</span> 个。
+          </p>
+
+          <div className="space-y-2">
+            <div className="text-xs text-muted-foreground">验证阈值（LDC）</div>
+            <div className="flex gap-2">
+              <Input
+                value={threshold}
+                onChange={(e) => setThreshold(e.target.value)}
+                placeholder={`留空使用系统阈值 ${ status.system_threshold }`}
+                className="h-9"
+              />
+              <Button size="sm" variant="secondary" className="h-9" onClick={handleSaveThreshold}>
+                保存
+              </Button>
+            </div>
+            <p className="text-xs text-muted-foreground">
+              可设置低于系统阈值的金额，高于系统阈值时以系统阈值为准
+            </p>
+          </div>
+
+          <div className="flex justify-end gap-3">
+            <Button size="sm" variant="secondary" onClick={() => setPendingAction("recovery")}>
+              重新生成恢复码
+            </Button>
+            <Button size="sm" variant="destructive" onClick={() => setIsDisablePasswordOpen(true)}>
+              关闭两步验证
+            </Button>
+          </div>
+        </div>
+      )}
+
+      <PasswordDialog
+        isOpen={isSetupPasswordOpen}
+        onOpenChange={setIsSetupPasswordOpen}
+        onConfirm={handleSetup}
+        loading={loading}
+        title="密码验证"
+        description="开启两步验证前，请输入6位支付密码"
+      />
+
+      <PasswordDialog
+        isOpen={isDisablePasswordOpen}
+        onOpenChange={setIsDisablePasswordOpen}
+        onConfirm={(payKey) => {
+          setDisablePayKey(payKey)
+          setIsDisablePasswordOpen(false)
+          setPendingAction("disable")
+        }}
+        title="密码验证"
+        description="关闭两步验证前，请输入6位支付密码"
+      />
+
+      <TOTPDialog
+        isOpen={pendingAction !== null}
+        onOpenChange={(open) => {
+          if (!open) {
+            setPendingAction(null)
+            setDisablePayKey("")
+          }
+        }}
+        onConfirm={handleConfirmCode}
+        loading={loading}
+        description="请输入验证器中的6位验证码以确认本次操作"
+      />
+
+      <Dialog open={setupInfo !== null} onOpenChange={(open) => !open && setSetupInfo(null)}>
+        <DialogContent>
+          <DialogHeader>
+            <DialogTitle>绑定验证器</DialogTitle>
+            <DialogDescription>
+              在验证器应用中添加账户，然后输入其生成的6位验证码完成开启
+            </DialogDescription>
+          </DialogHeader>
+          {setupInfo && (
+            <div className="space-y-4">
+              <div className="space-y-1">
+                <div className="text-xs text-muted-foreground">密钥（手动输入）</div>
+                <div className="rounded-md bg-muted px-3 py-2 font-mono text-sm break-all select-all">
+                  {setupInfo.secret}
+                </div>
+              </div>
+              <a href={setupInfo.uri} className="text-xs text-primary underline-offset-4 hover:underline">
+                在本设备的验证器中打开
+              </a>
+              <Input
+                value={enableCode}
+                onChange={(e) => setEnableCode(e.target.value.replace(/\D/g, ""))}
+                placeholder="6位验证码"
+                maxLength={6}
+                inputMode="numeric"
+                autoComplete="one-time-code"
+                className="h-9 text-center font-mono tracking-widest"
+              />
+            </div>
+          )}
+          <DialogFooter>
+            <Button size="sm" onClick={handleEnable} disabled={enableCode.length !== 6 || loading}>
+              {loading ? <Spinner className="mr-2 h-4 w-4" /> : null}
+              确认开启
+            </Button>
+          </DialogFooter>
+        </DialogContent>
+      </Dialog>
+
+      <Dialog open={recoveryCodes.length > 0} onOpenChange={(open) => !open && setRecoveryCodes([])}>
+        <DialogContent>
+          <DialogHeader>
+            <DialogTitle>恢复码</DialogTitle>
+            <DialogDescription>
+              验证器不可用时，每个恢复码可代替验证码使用一次。恢复码只显示这一次，请妥善保存。
+            </DialogDescription>
+          </DialogHeader>
+          <div className="grid grid-cols-2 gap-2 rounded-md bg-muted p-3 font-mono text-sm">
+            {recoveryCodes.map((code) => (
+              <span key={code}>{code}</span>
+            ))}
+          </div>
+          <DialogFooter>
+            <Button size="sm" variant="secondary" onClick={handleCopyRecoveryCodes}>
+              复制
+            </Button>
+            <Button size="sm" onClick={() => setRecoveryCodes([])}>
+              我已保存
+            </Button>
+          </DialogFooter>
+        </DialogContent>
+      </Dialog>
+    </div>
+  )
+}
@@ -1,7 +1,7 @@
 "use client"
 
 import * as React from "react"
-import { useState } from "react"
+import { useRef, useState } from "react"
 import { toast } from "sonner"
 import { Input } from "@/components/ui/input"
 import { Label } from "@/components/ui/label"
@@ -16,6 +16,8 @@ import {
   DialogTitle,
 } from "@/components/ui/dialog"
 import { PasswordDialog } from "@/components/common/general/password-dialog"
+import { TOTPDialog, requiresTOTP } from "@/components/common/general/totp-dialog"
+import { useUser } from "@/contexts/user-context"
 import services from "@/lib/services"
 import type { TransferRequest } from "@/lib/services"
 
@@ -25,8 +27,11 @@ import type { TransferRequest } from "@/lib/services"
  * 提供用户之间的积分转移功能 (弹窗式)
  */
 export function Transfer() {
+  const { user } = useUser()
   const [isFormOpen, setIsFormOpen] = useState(false)
   const [isPasswordOpen, setIsPasswordOpen] = useState(false)
+  const [isTOTPOpen, setIsTOTPOpen] = useState(false)
+  const [pendingPassword, setPendingPassword] = useState("")
 
   /* 表单状态 */
   const [recipientUsername, setRecipientUsername] = useState("")
@@ -35,6 +40,9 @@ export function Transfer() {
   const [remark, setRemark] = useState("")
   const [loading, setLoading] = useState(false)
 
+  /* 幂等键：同一笔转移的重试复用，成功后重新生成 */
+  const idempotencyKeyRef = useRef(crypto.randomUUID())
+
   /* 验证金额格式*/
   const validateAmount = (value: string): boolean => {
     const regex = /^\d+(\.\d{1,2})?$/
@@ -81,7 +89,15 @@ export function Transfer() {
   }
 
   /* 处理最终转账（第二步）*/
-  const handleConfirmTransfer = async (password: string) => {
+  const handleConfirmTransfer = async (password: string, totpCode?: string) => {
+    /* 超过两步验证阈值时先输入验证码（第三步）*/
+    if (!totpCode && requiresTOTP(user, amount)) {
+      setPendingPassword(password)
+      setIsPasswordOpen(false)
+      setIsTOTPOpen(true)
+      return
+    }
+
     setLoading(true)
     try {
       const transferData: TransferRequest = {
@@ -89,10 +105,12 @@ export function Transfer() {
         recipient_username: recipientUsername,
         amount: parseFloat(amount),
         pay_key: password,
+        totp_code: totpCode,
         remark: remark || undefined,
       }
 
-      await services.transaction.transfer(transferData)
+      await services.transaction.transfer(transferData, idempotencyKeyRef.current)
+      idempotencyKeyRef.current = crypto.randomUUID()
 
       toast.success("积分转移成功！积分已实时到账。")
 
@@ -101,7 +119,9 @@ export function Transfer() {
       setRecipientId("")
       setAmount("")
       setRemark("")
+      setPendingPassword("")
       setIsPasswordOpen(false)
+      setIsTOTPOpen(false)
     } catch (error: unknown) {
       const errorMessage = error instanceof Error ? error.message : '转移失败'
       toast.error('转移失败', {
@@ -227,11 +247,25 @@ export function Transfer() {
             setIsFormOpen(true)
           }
         }}
-        onConfirm={handleConfirmTransfer}
+        onConfirm={(password) => handleConfirmTransfer(password)}
         loading={loading}
         title="密码验证"
         description={`正在向 ${ recipientUsername } 转移 ${ amount } LDC`}
       />
+
+      <TOTPDialog
+        isOpen={isTOTPOpen}
+        onOpenChange={(open) => {
+          setIsTOTPOpen(open)
+          if (!open) {
+            setPendingPassword("")
+            setIsFormOpen(true)
+          }
+        }}
+        onConfirm={(code) => handleConfirmTransfer(pendingPassword, code)}
+        loading={loading}
+        description={`正在向 ${ recipientUsername } 转移 ${ amount } LDC，请输入验证器中的6位验证码`}
+      />
     </div>
   )
 }
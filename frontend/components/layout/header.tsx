@@ -2,12 +2,13 @@
 
 import { useState, useEffect } from "react"
 import { Button } from "@/components/ui/button"
-import { Bell, Plus, Settings, Search, Moon, Sun } from "lucide-react"
+import { Plus, Settings, Search, Moon, Sun } from "lucide-react"
 import { useUser } from "@/contexts/user-context"
 import { SidebarTrigger } from "@/components/ui/sidebar"
 import { useTheme } from "next-themes"
 import { useRouter } from "next/navigation"
 import { SearchDialog } from "@/components/layout/search-dialog"
+import { NotificationPopover } from "@/components/layout/notification-popover"
 
 
 /**
@@ -44,10 +45,7 @@ export function SiteHeader() {
             <Search className="size-[18px]" />
             <span className="sr-only">搜索</span>
           </Button>
-          <Button variant="ghost" size="icon" className="size-9 text-muted-foreground hover:text-foreground">
-            <Bell className="size-[18px]" />
-            <span className="sr-only">通知</span>
-          </Button>
+          <NotificationPopover />
           <Button variant="ghost" size="icon" className="size-9 text-muted-foreground hover:text-foreground" onClick={() => router.push('/settings')}>
             <Settings className="size-[18px]" />
             <span className="sr-only">设置</span>
@@ -67,10 +65,7 @@ export function SiteHeader() {
         </div>
 
         <div className="ml-auto flex items-center gap-1">
-          <Button variant="ghost" size="icon" className="size-9 text-muted-foreground hover:text-foreground">
-            <Bell className="size-[18px]" />
-            <span className="sr-only">通知</span>
-          </Button>
+          <NotificationPopover />
           <Button variant="ghost" size="icon" className="size-9 text-muted-foreground hover:text-foreground" onClick={() => router.push('/settings')}>
             <Settings className="size-[18px]" />
             <span className="sr-only">设置</span>
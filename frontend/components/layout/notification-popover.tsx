@@ -0,0 +1,122 @@
+"use client"
+
+import { useState, useEffect, useCallback } from "react"
+import { useRouter } from "next/navigation"
+import { Bell } from "lucide-react"
+
+import { Button } from "@/components/ui/button"
+import { Popover, PopoverContent, PopoverTrigger } from "@/components/ui/popover"
+import { cn, formatDateTime } from "@/lib/utils"
+import services from "@/lib/services"
+import type { Notification } from "@/lib/services"
+
+
+/**
+ * 站内通知弹出层组件
+ * 显示未读角标，点击展开最近的站内通知
+ * 
+ * @example
+ * ```tsx
+ * <NotificationPopover />
+ * ```
+ */
+export function NotificationPopover() {
+  const router = useRouter()
+  const [open, setOpen] = useState(false)
+  const [notifications, setNotifications] = useState<Notification[]>([])
+  const [unreadCount, setUnreadCount] = useState(0)
+
+  const loadNotifications = useCallback(async () => {
+    try {
+      const result = await services.notification.listNotifications({ page: 1, page_size: 10 })
+      setNotifications(result.notifications)
+      setUnreadCount(result.unread_count)
+    } catch {
+      /* 通知加载失败不影响页面使用 */
+    }
+  }, [])
+
+  useEffect(() => {
+    loadNotifications()
+  }, [loadNotifications])
+
+  const handleOpenChange = (next: boolean) => {
+    setOpen(next)
+    if (next) {
+      loadNotifications()
+    }
+  }
+
+  const handleClick = async (notification: Notification) => {
+    if (!notification.is_read) {
+      try {
+        await services.notification.readNotification(notification.id)
+        setNotifications(prev => prev.map(n => n.id === notification.id ? { ...n, is_read: true } : n))
+        setUnreadCount(prev => Math.max(prev - 1, 0))
+      } catch {
+        /* 标记失败时保留未读状态 */
+      }
+    }
+    if (notification.link) {
+      setOpen(false)
+      router.push(notification.link)
+    }
+  }
+
+  const handleReadAll = async () => {
+    try {
+      await services.notification.readAllNotifications()
+      setNotifications(prev => prev.map(n => ({ ...n, is_read: true })))
+      setUnreadCount(0)
+    } catch {
+      /* 标记失败时保留未读状态 */
+    }
+  }
+
+  return (
+    <Popover open={open} onOpenChange={handleOpenChange}>
+      <PopoverTrigger asChild>
+        <Button variant="ghost" size="icon" className="relative size-9 text-muted-foreground hover:text-foreground">
+          <Bell className="size-[18px]" />
+          {unreadCount > 0 && (
+            <span className="absolute top-1.5 right-1.5 size-2 rounded-full bg-destructive" />
+          )}
+          <span className="sr-only">通知</span>
+        </Button>
+      </PopoverTrigger>
+      <PopoverContent align="end" className="w-80 p-0">
+        <div className="flex items-center justify-between px-4 py-3 border-b">
+          <span className="text-sm font-medium">通知</span>
+          {unreadCount > 0 && (
+            <button className="text-xs text-muted-foreground hover:text-foreground" onClick={handleReadAll}>
+              全部已读
+            </button>
+          )}
+        </div>
+        <div className="max-h-80 overflow-y-auto">
+          {notifications.length === 0 ? (
+            <div className="px-4 py-8 text-center text-xs text-muted-foreground">暂无通知</div>
+          ) : (
+            notifications.map(notification => (
+              <button
+                key={notification.id}
+                className={cn(
+                  "w-full text-left px-4 py-3 border-b last:border-b-0 hover:bg-muted/50 transition-colors",
+                  notification.is_read && "opacity-60"
+                )}
+                onClick={() => handleClick(notification)}
+              >
+                <div className="flex items-center gap-2">
+                  {!notification.is_read && <span className="size-1.5 shrink-0 rounded-full bg-destructive" />}
+                  <span className="text-sm font-medium truncate">{notification.title}</span>
+                </div>
+                <p className="mt-1 text-xs text-muted-foreground line-clamp-2">{notification.content}</p>
+                <p className="mt-1 text-[10px] text-muted-foreground">{formatDateTime(notification.created_at)}</p>
+              </button>
+            ))
+          )}
+        </div>
+      </PopoverContent>
+    </Popover>
+  )
+}
@@ -56,6 +56,9 @@ import {
   FileQuestionMark,
   ShieldCheck,
   Globe,
+  Scale,
+  Receipt,
+  LockKeyhole,
 } from "lucide-react"
 
 import { useUser } from "@/contexts/user-context"
@@ -72,6 +75,9 @@ const data = {
   admin: [
     { title: "系统配置", url: "/admin/system", icon: ShieldCheck },
     { title: "积分配置", url: "/admin/user_pay", icon: Settings },
+    { title: "手续费收入", url: "/admin/fee_revenue", icon: Receipt },
+    { title: "余额对账", url: "/admin/reconciliation", icon: Scale },
+    { title: "密码锁定", url: "/admin/pay_key_lock", icon: LockKeyhole },
   ],
   document: [
     { title: "接口文档", url: "/docs/api", icon: CreditCard },
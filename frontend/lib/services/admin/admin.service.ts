@@ -6,6 +6,11 @@ import type {
   UserPayConfig,
   CreateUserPayConfigRequest,
   UpdateUserPayConfigRequest,
+  ListBalanceDiscrepanciesRequest,
+  ListBalanceDiscrepanciesResponse,
+  GetFeeRevenueRequest,
+  GetFeeRevenueResponse,
+  PayKeyLock,
 } from './types';
 
 /**
@@ -234,5 +239,132 @@ export class AdminService extends BaseService {
   static async deleteUserPayConfig(id: number): Promise<void> {
     return this.delete<void>(`/user-pay-configs/${id}`);
   }
-}
 
+  // ==================== 余额对账 ====================
+
+  /**
+   * 查询对账差异列表
+   * @param request - 查询参数
+   * @returns 对账差异列表
+   * @throws {UnauthorizedError} 当未登录时
+   * @throws {ForbiddenError} 当无管理员权限时
+   *
+   * @example
+   * ```typescript
+   * const result = await AdminService.listBalanceDiscrepancies({
+   *   page: 1,
+   *   page_size: 20,
+   *   status: 'open'
+   * });
+   * console.log('未处理差异:', result.total);
+   * ```
+   */
+  static async listBalanceDiscrepancies(
+    request: ListBalanceDiscrepanciesRequest,
+  ): Promise<ListBalanceDiscrepanciesResponse> {
+    return this.get<ListBalanceDiscrepanciesResponse>('/balance-discrepancies', { ...request });
+  }
+
+  /**
+   * 立即执行余额对账
+   * @returns void
+   * @throws {UnauthorizedError} 当未登录时
+   * @throws {ForbiddenError} 当无管理员权限时
+   * @throws {ValidationError} 当对账任务已在执行中时
+   *
+   * @example
+   * ```typescript
+   * await AdminService.triggerReconcile();
+   * ```
+   */
+  static async triggerReconcile(): Promise<void> {
+    return this.post<void>('/balance-discrepancies/reconcile');
+  }
+
+  /**
+   * 修复对账差异
+   * @param id - 差异ID
+   * @returns void
+   * @throws {UnauthorizedError} 当未登录时
+   * @throws {ForbiddenError} 当无管理员权限或未开启对账修复时
+   * @throws {NotFoundError} 当差异不存在时
+   * @throws {ValidationError} 当差异已处理、字段不支持修复或余额已变化时
+   *
+   * @example
+   * ```typescript
+   * await AdminService.repairBalanceDiscrepancy(123);
+   * ```
+   *
+   * @remarks
+   * - 可用余额通过对账调整凭证入账，总收入/总支付/总转出直接修正为推算值
+   * - 账本余额差异不支持修复，需排查账本记录
+   */
+  static async repairBalanceDiscrepancy(id: number): Promise<void> {
+    return this.post<void>(`/balance-discrepancies/${id}/repair`);
+  }
+
+  // ==================== 手续费收入 ====================
+
+  /**
+   * 查询平台手续费收入
+   * @param request - 查询日期范围
+   * @returns 手续费收入统计
+   * @throws {UnauthorizedError} 当未登录时
+   * @throws {ForbiddenError} 当无管理员权限时
+   * @throws {ValidationError} 当日期格式或范围无效时
+   *
+   * @example
+   * ```typescript
+   * const revenue = await AdminService.getFeeRevenue({
+   *   start_date: '2025-01-01',
+   *   end_date: '2025-01-31'
+   * });
+   * console.log('净手续费收入:', revenue.net_fee);
+   * ```
+   *
+   * @remarks
+   * - 按订单交易日统计，退还的手续费计入原订单的交易日
+   * - 日期范围跨度不能超过 366 天
+   */
+  static async getFeeRevenue(
+    request: GetFeeRevenueRequest,
+  ): Promise<GetFeeRevenueResponse> {
+    return this.get<GetFeeRevenueResponse>('/fee-revenue', { ...request });
+  }
+
+  // ==================== 支付密码锁定 ====================
+
+  /**
+   * 按用户名查询支付密码锁定状态
+   * @param username - 用户名
+   * @returns 支付密码锁定状态
+   * @throws {UnauthorizedError} 当未登录时
+   * @throws {ForbiddenError} 当无管理员权限时
+   * @throws {NotFoundError} 当用户不存在时
+   *
+   * @example
+   * ```typescript
+   * const lock = await AdminService.getPayKeyLock('user123');
+   * console.log('锁定截止:', lock.locked_until);
+   * ```
+   */
+  static async getPayKeyLock(username: string): Promise<PayKeyLock> {
+    return this.get<PayKeyLock>('/pay-key-locks', { username });
+  }
+
+  /**
+   * 解除用户支付密码锁定，并清空错误次数
+   * @param userId - 用户ID
+   * @returns void
+   * @throws {UnauthorizedError} 当未登录时
+   * @throws {ForbiddenError} 当无管理员权限时
+   *
+   * @example
+   * ```typescript
+   * await AdminService.unlockPayKey(123);
+   * ```
+   */
+  static async unlockPayKey(userId: number): Promise<void> {
+    return this.delete<void>(`/pay-key-locks/${userId}`);
+  }
+}
@@ -35,5 +35,14 @@ export type {
   UserPayConfig,
   CreateUserPayConfigRequest,
   UpdateUserPayConfigRequest,
+  BalanceField,
+  DiscrepancyStatus,
+  BalanceDiscrepancy,
+  ListBalanceDiscrepanciesRequest,
+  ListBalanceDiscrepanciesResponse,
+  GetFeeRevenueRequest,
+  DailyFeeRevenue,
+  GetFeeRevenueResponse,
+  PayKeyLock,
 } from './types';
 
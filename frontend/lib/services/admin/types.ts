@@ -96,3 +96,136 @@ export interface UpdateUserPayConfigRequest {
   score_rate: number | string;
 }
 
+
+/**
+ * 对账字段
+ */
+export type BalanceField =
+  | 'available_balance'
+  | 'total_receive'
+  | 'total_payment'
+  | 'total_transfer'
+  | 'ledger_balance';
+
+/**
+ * 对账差异状态
+ */
+export type DiscrepancyStatus = 'open' | 'resolved' | 'repaired';
+
+/**
+ * 对账差异
+ */
+export interface BalanceDiscrepancy {
+  /** 差异ID */
+  id: number;
+  /** 用户ID */
+  user_id: number;
+  /** 用户名 */
+  username: string;
+  /** 对账字段 */
+  field: BalanceField;
+  /** 实际值 */
+  actual: string;
+  /** 订单流水推算值 */
+  expected: string;
+  /** 差额（实际值 - 推算值） */
+  diff: string;
+  /** 状态 */
+  status: DiscrepancyStatus;
+  /** 修复人ID */
+  resolved_by_user_id: number;
+  /** 处理时间 */
+  resolved_at: string | null;
+  /** 创建时间 */
+  created_at: string;
+  /** 更新时间 */
+  updated_at: string;
+}
+
+/**
+ * 查询对账差异请求参数
+ */
+export interface ListBalanceDiscrepanciesRequest {
+  /** 页码，从 1 开始 */
+  page: number;
+  /** 每页数量，1-100 */
+  page_size: number;
+  /** 状态筛选 */
+  status?: DiscrepancyStatus;
+  /** 用户ID筛选 */
+  user_id?: number;
+}
+
+/**
+ * 查询对账差异响应
+ */
+export interface ListBalanceDiscrepanciesResponse {
+  /** 总数 */
+  total: number;
+  /** 当前页码 */
+  page: number;
+  /** 每页数量 */
+  page_size: number;
+  /** 差异列表 */
+  discrepancies: BalanceDiscrepancy[];
+}
+
+/**
+ * 查询手续费收入请求参数
+ */
+export interface GetFeeRevenueRequest {
+  /** 开始日期（YYYY-MM-DD） */
+  start_date: string;
+  /** 结束日期（YYYY-MM-DD，包含当天） */
+  end_date: string;
+}
+
+/**
+ * 按交易日统计的手续费收入
+ */
+export interface DailyFeeRevenue {
+  /** 交易日（YYYY-MM-DD） */
+  date: string;
+  /** 收取手续费的订单数 */
+  order_count: number;
+  /** 收取的手续费 */
+  fee: string;
+  /** 退还的手续费 */
+  refunded_fee: string;
+  /** 净手续费收入 */
+  net_fee: string;
+}
+
+/**
+ * 查询手续费收入响应
+ */
+export interface GetFeeRevenueResponse {
+  /** 平台手续费账户当前余额 */
+  platform_balance: string;
+  /** 收取手续费的订单数 */
+  order_count: number;
+  /** 收取的手续费合计 */
+  total_fee: string;
+  /** 退还的手续费合计 */
+  total_refunded_fee: string;
+  /** 净手续费收入 */
+  net_fee: string;
+  /** 按交易日统计 */
+  daily: DailyFeeRevenue[];
+}
+
+/**
+ * 支付密码锁定状态
+ */
+export interface PayKeyLock {
+  /** 用户ID */
+  user_id: number;
+  /** 用户名 */
+  username: string;
+  /** 统计窗口内的连续错误次数 */
+  failed_attempts: number;
+  /** 锁定梯度，每次锁定时长翻倍 */
+  lock_level: number;
+  /** 锁定截止时间，未锁定时为 null */
+  locked_until: string | null;
+}
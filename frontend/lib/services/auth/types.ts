@@ -66,6 +66,10 @@ export interface User {
   pay_level: PayLevel;
   /** 每日限额 */
   daily_limit: number | null;
+  /** 是否开启两步验证 */
+  totp_enabled: boolean;
+  /** 两步验证生效阈值，支付金额超过该值需输入验证码；未开启时为 null */
+  totp_threshold: string | null;
 }
 
 /**
@@ -182,5 +182,21 @@ export class BaseService {
     const response = await apiClient.post<T>(url, data);
     return response.data;
   }
-}
 
+  /**
+   * 构造携带幂等键的请求配置
+   * @param idempotencyKey - 幂等键，相同幂等键的重复请求返回首次请求的结果
+   * @returns 请求配置，未传幂等键时返回 undefined
+   * 
+   * @remarks
+   * 用于转账、支付等资金类接口，避免网络超时重试导致重复扣款
+   */
+  protected static withIdempotencyKey(
+    idempotencyKey?: string,
+  ): InternalAxiosRequestConfig | undefined {
+    if (!idempotencyKey) {
+      return undefined;
+    }
+    return { headers: { 'Idempotency-Key': idempotencyKey } } as unknown as InternalAxiosRequestConfig;
+  }
+}
@@ -28,6 +28,7 @@ import { UserService } from './user';
 import { DisputeService } from './dispute';
 import { ConfigService } from './config';
 import { DashboardService } from './dashboard';
+import { NotificationService } from './notification';
 
 /**
  * 服务对象
@@ -53,6 +54,8 @@ const services = {
   config: ConfigService,
   /** 仪表板服务 */
   dashboard: DashboardService,
+  /** 站内通知服务 */
+  notification: NotificationService,
 } as const;
 
 export default services;
@@ -136,6 +139,7 @@ export type {
   CreateAPIKeyRequest,
   UpdateAPIKeyRequest,
   PayMerchantOrderRequest,
+  PayMerchantOrderResponse,
   GetMerchantOrderRequest,
   GetMerchantOrderResponse,
   PaymentLink,
@@ -145,6 +149,15 @@ export type {
   RefundMerchantOrderRequest,
   RefundMerchantOrderResponse,
   GetPaymentLinkInfoResponse,
+  WebhookEvent,
+  CallbackFormat,
+  WebhookDelivery,
+  ListWebhookDeliveriesRequest,
+  ListWebhookDeliveriesResponse,
+  NotifyFailedOrder,
+  ListNotifyFailedOrdersRequest,
+  ListNotifyFailedOrdersResponse,
+  RetryNotifyFailedOrdersResponse,
 } from './merchant';
 
 // 管理员服务
@@ -156,11 +169,29 @@ export type {
   UserPayConfig,
   CreateUserPayConfigRequest,
   UpdateUserPayConfigRequest,
+  BalanceField,
+  DiscrepancyStatus,
+  BalanceDiscrepancy,
+  ListBalanceDiscrepanciesRequest,
+  ListBalanceDiscrepanciesResponse,
+  GetFeeRevenueRequest,
+  DailyFeeRevenue,
+  GetFeeRevenueResponse,
+  PayKeyLock,
 } from './admin';
 
 // 用户服务
 export { UserService } from './user';
-export type { UpdatePayKeyRequest } from './user';
+export type {
+  UpdatePayKeyRequest,
+  TOTPStatus,
+  SetupTOTPRequest,
+  SetupTOTPResponse,
+  EnableTOTPRequest,
+  DisableTOTPRequest,
+  TOTPRecoveryCodesResponse,
+  UpdateTOTPThresholdRequest,
+} from './user';
 
 // 仪表板服务
 export { DashboardService } from './dashboard';
@@ -173,4 +204,13 @@ export type {
   GetTopCustomersRequest,
 } from './dashboard';
 
+// 站内通知服务
+export { NotificationService } from './notification';
+export type {
+  Notification,
+  NotificationType,
+  ListNotificationsRequest,
+  ListNotificationsResponse,
+} from './notification';
+
 
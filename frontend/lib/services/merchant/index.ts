@@ -7,6 +7,7 @@
  * - 支付链接管理（创建、列表、删除）
  * - 商户订单查询和支付
  * - 商户订单退款
+ * - 回调投递记录查询与手动重发
  * 
  * @example
  * ```typescript
@@ -31,12 +32,22 @@ export type {
   CreateAPIKeyRequest,
   UpdateAPIKeyRequest,
   PayMerchantOrderRequest,
+  PayMerchantOrderResponse,
   GetMerchantOrderRequest,
   GetMerchantOrderResponse,
   PaymentLink,
   CreatePaymentLinkRequest,
   PayByLinkRequest,
   GetPaymentLinkInfoResponse,
+  WebhookEvent,
+  CallbackFormat,
+  WebhookDelivery,
+  ListWebhookDeliveriesRequest,
+  ListWebhookDeliveriesResponse,
+  NotifyFailedOrder,
+  ListNotifyFailedOrdersRequest,
+  ListNotifyFailedOrdersResponse,
+  RetryNotifyFailedOrdersResponse,
   QueryMerchantOrderRequest,
   QueryMerchantOrderResponse,
   RefundMerchantOrderRequest,
@@ -4,6 +4,7 @@ import type {
   CreateAPIKeyRequest,
   UpdateAPIKeyRequest,
   PayMerchantOrderRequest,
+  PayMerchantOrderResponse,
   GetMerchantOrderRequest,
   GetMerchantOrderResponse,
   PaymentLink,
@@ -13,6 +14,12 @@ import type {
   QueryMerchantOrderResponse,
   RefundMerchantOrderRequest,
   RefundMerchantOrderResponse,
+  WebhookDelivery,
+  ListWebhookDeliveriesRequest,
+  ListWebhookDeliveriesResponse,
+  ListNotifyFailedOrdersRequest,
+  ListNotifyFailedOrdersResponse,
+  RetryNotifyFailedOrdersResponse,
 } from './types';
 
 /**
@@ -183,6 +190,84 @@ export class MerchantService extends BaseService {
     return this.delete<void>(`/api-keys/${ apiKeyId }/payment-links/${ linkId }`);
   }
 
+  // ==================== 回调投递记录 ====================
+
+  /**
+   * 获取回调投递记录
+   * @param apiKeyId - API Key ID
+   * @param request - 查询参数
+   * @returns 投递记录列表（按时间倒序）
+   * @throws {UnauthorizedError} 当未登录时
+   * @throws {NotFoundError} 当 API Key 不存在时
+   * 
+   * @example
+   * ```typescript
+   * const result = await MerchantService.listWebhookDeliveries(123, { page: 1, page_size: 20, success: false });
+   * console.log('失败投递数量:', result.total);
+   * ```
+   */
+  static async listWebhookDeliveries(apiKeyId: number, request: ListWebhookDeliveriesRequest): Promise<ListWebhookDeliveriesResponse> {
+    return this.get<ListWebhookDeliveriesResponse>(`/api-keys/${ apiKeyId }/webhook-deliveries`, { ...request });
+  }
+
+  /**
+   * 手动重发订单回调
+   * @param apiKeyId - API Key ID
+   * @param orderId - 订单 ID
+   * @returns 本次投递记录（投递失败时 success 为 false）
+   * @throws {UnauthorizedError} 当未登录时
+   * @throws {NotFoundError} 当 API Key 或订单不存在时
+   * @throws {ApiErrorBase} 当订单未完成支付时
+   * 
+   * @example
+   * ```typescript
+   * const delivery = await MerchantService.redeliverWebhook(123, 456);
+   * console.log('投递结果:', delivery.success);
+   * ```
+   */
+  static async redeliverWebhook(apiKeyId: number, orderId: number): Promise<WebhookDelivery> {
+    return this.post<WebhookDelivery>(`/api-keys/${ apiKeyId }/webhook-deliveries`, { order_id: orderId });
+  }
+
+  /**
+   * 获取回调失败订单（重试耗尽后仍未成功通知商户）
+   * @param apiKeyId - API Key ID
+   * @param request - 查询参数
+   * @returns 回调失败订单列表（按创建时间倒序）
+   * @throws {UnauthorizedError} 当未登录时
+   * @throws {NotFoundError} 当 API Key 不存在时
+   * 
+   * @example
+   * ```typescript
+   * const result = await MerchantService.listNotifyFailedOrders(123, { page: 1, page_size: 20 });
+   * console.log('回调失败订单数量:', result.total);
+   * ```
+   */
+  static async listNotifyFailedOrders(apiKeyId: number, request: ListNotifyFailedOrdersRequest): Promise<ListNotifyFailedOrdersResponse> {
+    return this.get<ListNotifyFailedOrdersResponse>(`/api-keys/${ apiKeyId }/notify-failed-orders`, { ...request });
+  }
+
+  /**
+   * 重试全部回调失败订单
+   * @param apiKeyId - API Key ID
+   * @returns 本次重新下发的回调数量
+   * @throws {UnauthorizedError} 当未登录时
+   * @throws {NotFoundError} 当 API Key 不存在时
+   * 
+   * @example
+   * ```typescript
+   * const { count } = await MerchantService.retryNotifyFailedOrders(123);
+   * ```
+   * 
+   * @remarks
+   * - 回调成功后订单的失败标记会自动清除
+   * - 已在队列中的订单不会重复下发，其订单 ID 通过 skipped_order_ids 返回
+   * - 重试耗尽被归档的任务会被清理后重新下发
+   */
+  static async retryNotifyFailedOrders(apiKeyId: number): Promise<RetryNotifyFailedOrdersResponse> {
+    return this.post<RetryNotifyFailedOrdersResponse>(`/api-keys/${ apiKeyId }/notify-failed-orders/retry`);
+  }
+
   /**
    * 通过 Token 获取支付链接信息
    * 
@@ -213,6 +298,7 @@ export class MerchantService extends BaseService {
    * 需要用户登录，并且用户余额充足。
    * 
    * @param request - 支付请求参数（token 和 pay_key）
+   * @param idempotencyKey - 幂等键（可选），同一次支付的重试应使用相同的幂等键
    * @returns void
    * @throws {UnauthorizedError} 当用户未登录时
    * @throws {NotFoundError} 当支付链接不存在时
@@ -237,8 +323,8 @@ export class MerchantService extends BaseService {
    * - 用户余额必须充足
    * - 支付成功后会扣除手续费（根据商户的支付等级）
    */
-  static async payByLink(request: PayByLinkRequest): Promise<void> {
-    return this.post<void>('/payment-links/pay', request);
+  static async payByLink(request: PayByLinkRequest, idempotencyKey?: string): Promise<void> {
+    return this.post<void>('/payment-links/pay', request, this.withIdempotencyKey(idempotencyKey));
   }
 
   // ==================== 商户支付订单 ====================
@@ -290,6 +376,7 @@ export class MerchantService extends BaseService {
    * 需要用户登录，并且用户余额充足。
    *
    * @param request - 支付订单请求参数
+   * @param idempotencyKey - 幂等键（可选），同一次支付的重试应使用相同的幂等键
    * @returns void
    * @throws {UnauthorizedError} 当用户未登录时
    * @throws {NotFoundError} 当订单不存在或已过期时
@@ -322,8 +409,8 @@ export class MerchantService extends BaseService {
    * - 用户余额必须充足
    * - 支付成功后会扣除手续费（根据用户的积分等级）
    */
-  static async payMerchantOrder(request: PayMerchantOrderRequest): Promise<void> {
-    return this.post<void>('/payment', request);
+  static async payMerchantOrder(request: PayMerchantOrderRequest, idempotencyKey?: string): Promise<PayMerchantOrderResponse> {
+    return this.post<PayMerchantOrderResponse>('/payment', request, this.withIdempotencyKey(idempotencyKey));
   }
 
   /**
@@ -1,3 +1,15 @@
+import type { Order } from '../transaction/types';
+
+/**
+ * 商户 Webhook 事件类型
+ */
+export type WebhookEvent = 'order.paid' | 'order.refunded' | 'order.expired' | 'dispute.opened' | 'dispute.resolved';
+
+/**
+ * 商户回调格式（epay：GET 查询参数 + MD5 签名；json：POST JSON + HMAC-SHA256 签名请求头）
+ */
+export type CallbackFormat = 'epay' | 'json';
+
 /**
  * 商户 API Key 信息
  */
@@ -20,6 +32,10 @@ export interface MerchantAPIKey {
   redirect_uri?: string;
   /** 通知 URL */
   notify_url: string;
+  /** 订阅的 Webhook 事件 */
+  webhook_events: WebhookEvent[];
+  /** 回调格式 */
+  callback_format: CallbackFormat;
   /** 创建时间 */
   created_at: string;
   /** 更新时间 */
@@ -42,6 +58,10 @@ export interface CreateAPIKeyRequest {
   redirect_uri?: string;
   /** 通知 URL（最大100字符，必须是有效的 URL） */
   notify_url: string;
+  /** 订阅的 Webhook 事件（可选，默认仅订阅 order.paid） */
+  webhook_events?: WebhookEvent[];
+  /** 回调格式（可选，默认 epay） */
+  callback_format?: CallbackFormat;
 }
 
 /**
@@ -58,6 +78,10 @@ export interface UpdateAPIKeyRequest {
   redirect_uri?: string;
   /** 通知 URL（最大100字符，必须是有效的 URL，可选） */
   notify_url?: string;
+  /** 订阅的 Webhook 事件（可选，不传时保持不变） */
+  webhook_events?: WebhookEvent[];
+  /** 回调格式（可选，不传时保持不变） */
+  callback_format?: CallbackFormat;
 }
 
 /**
@@ -68,6 +92,16 @@ export interface PayMerchantOrderRequest {
   order_no: string;
   /** 支付密码（6位数字） */
   pay_key: string;
+  /** 两步验证码或恢复码（金额超过两步验证阈值时必填） */
+  totp_code?: string;
+}
+
+/**
+ * 支付商户订单响应
+ */
+export interface PayMerchantOrderResponse {
+  /** 同步跳转地址（订单未设置 return_url 时为空） */
+  return_url: string;
 }
 
 /**
@@ -169,6 +203,116 @@ export interface PaymentLink {
   app_name: string;
 }
 
+/**
+ * 商户回调投递记录
+ */
+export interface WebhookDelivery {
+  /** 投递记录 ID */
+  id: number;
+  /** 订单 ID */
+  order_id: number;
+  /** 客户端 ID */
+  client_id: string;
+  /** 事件类型 */
+  event: WebhookEvent;
+  /** 事件 ID */
+  event_id: string;
+  /** 回调地址 */
+  url: string;
+  /** 请求方法 */
+  method: string;
+  /** 回调参数或请求体（JSON 字符串） */
+  params: string;
+  /** HTTP 状态码（请求未发出时为 0） */
+  http_status: number;
+  /** 响应体片段 */
+  response_body: string;
+  /** 失败原因 */
+  error: string;
+  /** 耗时（毫秒） */
+  latency_ms: number;
+  /** 重试次数 */
+  retry_count: number;
+  /** 是否为手动重发 */
+  manual: boolean;
+  /** 是否投递成功 */
+  success: boolean;
+  /** 创建时间 */
+  created_at: string;
+}
+
+/**
+ * 回调投递记录查询参数
+ */
+export interface ListWebhookDeliveriesRequest {
+  /** 页码 */
+  page: number;
+  /** 每页数量（最大100） */
+  page_size: number;
+  /** 订单 ID（可选） */
+  order_id?: number;
+  /** 事件类型（可选） */
+  event?: WebhookEvent;
+  /** 是否投递成功（可选） */
+  success?: boolean;
+}
+
+/**
+ * 回调投递记录查询响应
+ */
+export interface ListWebhookDeliveriesResponse {
+  /** 总数 */
+  total: number;
+  /** 页码 */
+  page: number;
+  /** 每页数量 */
+  page_size: number;
+  /** 投递记录列表 */
+  deliveries: WebhookDelivery[];
+}
+
+/**
+ * 回调失败订单（重试耗尽后仍未成功通知商户）
+ */
+export interface NotifyFailedOrder extends Order {
+  /** 最近一次投递的失败原因 */
+  last_error: string;
+}
+
+/**
+ * 回调失败订单查询参数
+ */
+export interface ListNotifyFailedOrdersRequest {
+  /** 页码 */
+  page: number;
+  /** 每页数量（最大100） */
+  page_size: number;
+}
+
+/**
+ * 回调失败订单查询响应
+ */
+export interface ListNotifyFailedOrdersResponse {
+  /** 总数 */
+  total: number;
+  /** 页码 */
+  page: number;
+  /** 每页数量 */
+  page_size: number;
+  /** 订单列表 */
+  orders: NotifyFailedOrder[];
+}
+
+/**
+ * 批量重发回调响应
+ */
+export interface RetryNotifyFailedOrdersResponse {
+  /** 本次重新下发的回调数量 */
+  count: number;
+  /** 回调任务仍在执行中而跳过的订单 ID */
+  skipped_order_ids: number[];
+}
+
 /**
  * 创建支付链接请求参数
  */
@@ -189,6 +333,8 @@ export interface PayByLinkRequest {
   token: string;
   /** 支付密码（6位数字） */
   pay_key: string;
+  /** 两步验证码或恢复码（金额超过两步验证阈值时必填） */
+  totp_code?: string;
   /** 备注（可选，最大100字符） */
   remark?: string;
 }
@@ -0,0 +1,24 @@
+/**
+ * 站内通知服务模块
+ * 
+ * @description
+ * 提供站内通知相关的功能，包括：
+ * - 查询通知及未读数量
+ * - 标记单条/全部通知为已读
+ * 
+ * @example
+ * ```typescript
+ * import { NotificationService } from '@/lib/services';
+ * 
+ * const { unread_count } = await NotificationService.listNotifications({ page: 1, page_size: 20 });
+ * await NotificationService.readAllNotifications();
+ * ```
+ */
+
+export { NotificationService } from './notification.service';
+export type {
+  Notification,
+  NotificationType,
+  ListNotificationsRequest,
+  ListNotificationsResponse,
+} from './types';
@@ -0,0 +1,49 @@
+import { BaseService } from '../core/base.service';
+import type {
+  ListNotificationsRequest,
+  ListNotificationsResponse,
+} from './types';
+
+/**
+ * 站内通知服务
+ * 处理站内通知相关的 API 请求
+ */
+export class NotificationService extends BaseService {
+  protected static readonly basePath = '/api/v1/notifications';
+
+  /**
+   * 获取当前用户的站内通知
+   * @param request - 查询参数
+   * @returns 通知列表及未读数量
+   * @throws {UnauthorizedError} 当用户未登录时
+   *
+   * @example
+   * ```typescript
+   * const result = await NotificationService.listNotifications({ page: 1, page_size: 20 });
+   * console.log('未读数量:', result.unread_count);
+   * ```
+   */
+  static async listNotifications(request: ListNotificationsRequest): Promise<ListNotificationsResponse> {
+    return this.get<ListNotificationsResponse>('', { ...request });
+  }
+
+  /**
+   * 将单条通知标记为已读
+   * @param id - 通知 ID
+   * @returns void
+   * @throws {UnauthorizedError} 当用户未登录时
+   * @throws {NotFoundError} 当通知不存在时
+   */
+  static async readNotification(id: number): Promise<void> {
+    return this.post<void>(`/${ id }/read`);
+  }
+
+  /**
+   * 将全部通知标记为已读
+   * @returns void
+   * @throws {UnauthorizedError} 当用户未登录时
+   */
+  static async readAllNotifications(): Promise<void> {
+    return this.post<void>('/read-all');
+  }
+}
@@ -0,0 +1,56 @@
+/**
+ * 站内通知类型
+ */
+export type NotificationType = 'webhook_failed' | 'pay_key_locked';
+
+/**
+ * 站内通知
+ */
+export interface Notification {
+  /** 通知 ID */
+  id: number;
+  /** 接收用户 ID */
+  user_id: number;
+  /** 通知类型 */
+  type: NotificationType;
+  /** 关联对象标识 */
+  ref_key: string;
+  /** 标题 */
+  title: string;
+  /** 内容 */
+  content: string;
+  /** 跳转链接 */
+  link: string;
+  /** 是否已读 */
+  is_read: boolean;
+  /** 创建时间 */
+  created_at: string;
+}
+
+/**
+ * 站内通知查询参数
+ */
+export interface ListNotificationsRequest {
+  /** 页码 */
+  page: number;
+  /** 每页数量（最大100） */
+  page_size: number;
+  /** 是否仅查询未读通知（可选） */
+  unread?: boolean;
+}
+
+/**
+ * 站内通知查询响应
+ */
+export interface ListNotificationsResponse {
+  /** 总数 */
+  total: number;
+  /** 未读数量 */
+  unread_count: number;
+  /** 页码 */
+  page: number;
+  /** 每页数量 */
+  page_size: number;
+  /** 通知列表 */
+  notifications: Notification[];
+}
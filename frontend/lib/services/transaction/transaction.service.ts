@@ -34,6 +34,7 @@ export class TransactionService extends BaseService {
   /**
    * 用户转账
    * @param data - 转账信息
+   * @param idempotencyKey - 幂等键（可选），同一次转账的重试应使用相同的幂等键
    * @returns 转账结果（订单信息）
    * @throws {UnauthorizedError} 当未登录时
    * @throws {NotFoundError} 当收款人不存在时
@@ -58,8 +59,12 @@ export class TransactionService extends BaseService {
    * - 不能给自己转账
    * - 需要确保账户余额充足
    */
-  static async transfer(data: TransferRequest): Promise<TransferResponse> {
-    const response = await apiClient.post<ApiResponse<TransferResponse>>('/api/v1/payment/transfer', data);
+  static async transfer(data: TransferRequest, idempotencyKey?: string): Promise<TransferResponse> {
+    const response = await apiClient.post<ApiResponse<TransferResponse>>(
+      '/api/v1/payment/transfer',
+      data,
+      this.withIdempotencyKey(idempotencyKey),
+    );
     return response.data.data;
   }
 }
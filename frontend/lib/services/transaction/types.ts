@@ -6,7 +6,7 @@ export type OrderType = 'receive' | 'payment' | 'transfer' | 'community' | 'onli
 /**
  * 订单状态
  */
-export type OrderStatus = 'success' | 'pending' | 'failed' | 'expired' | 'disputing' | 'refund' | 'refused';
+export type OrderStatus = 'success' | 'pending' | 'failed' | 'expired' | 'disputing' | 'refund' | 'refused' | 'partially_refunded' | 'closed';
 
 /**
  * 订单信息
@@ -30,6 +30,12 @@ export interface Order {
   payee_username: string;
   /** 交易金额（decimal字符串） */
   amount: string;
+  /** 累计退回金额（decimal字符串） */
+  refunded_amount: string;
+  /** 手续费（decimal字符串） */
+  fee: string;
+  /** 累计退还手续费（decimal字符串） */
+  refunded_fee: string;
   /** 订单状态 */
   status: OrderStatus;
   /** 订单类型 */
@@ -58,6 +64,8 @@ export interface Order {
   dispute_id?: number;
   /** 支付类型 */
   payment_type: string;
+  /** 商户回调是否在重试耗尽后仍失败 */
+  notify_failed: boolean;
 }
 
 /**
@@ -114,6 +122,8 @@ export interface TransferRequest {
   amount: number | string;
   /** 支付密码（6-10位） */
   pay_key: string;
+  /** 两步验证码或恢复码（金额超过两步验证阈值时必填） */
+  totp_code?: string;
   /** 备注（可选，最大200字符） */
   remark?: string;
 }
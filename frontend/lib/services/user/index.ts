@@ -4,6 +4,7 @@
  * @description
  * 提供用户个人设置相关的功能，包括：
  * - 更新支付密钥
+ * - 两步验证（TOTP）管理
  * 
  * @example
  * ```typescript
@@ -15,4 +16,13 @@
  */
 
 export { UserService } from './user.service';
-export type { UpdatePayKeyRequest } from './types';
+export type {
+  UpdatePayKeyRequest,
+  TOTPStatus,
+  SetupTOTPRequest,
+  SetupTOTPResponse,
+  EnableTOTPRequest,
+  DisableTOTPRequest,
+  TOTPRecoveryCodesResponse,
+  UpdateTOTPThresholdRequest,
+} from './types';
@@ -4,5 +4,77 @@
 export interface UpdatePayKeyRequest {
   /** 新的支付密钥（6位数字） */
   pay_key: string;
+  /** 原支付密钥，已设置支付密钥且非近期登录时必填 */
+  old_pay_key?: string;
 }
 
+
+/**
+ * 两步验证状态
+ */
+export interface TOTPStatus {
+  /** 是否已开启 */
+  enabled: boolean;
+  /** 用户自行设置的阈值，null 表示使用系统阈值 */
+  threshold: string | null;
+  /** 系统阈值 */
+  system_threshold: string;
+  /** 实际生效的阈值 */
+  effective_threshold: string;
+  /** 剩余可用恢复码数量 */
+  recovery_codes_remaining: number;
+}
+
+/**
+ * 生成两步验证密钥请求
+ */
+export interface SetupTOTPRequest {
+  /** 支付密钥 */
+  pay_key: string;
+}
+
+/**
+ * 生成两步验证密钥响应
+ */
+export interface SetupTOTPResponse {
+  /** Base32 编码的密钥，用于手动添加到验证器 */
+  secret: string;
+  /** otpauth:// 链接 */
+  uri: string;
+}
+
+/**
+ * 开启两步验证请求
+ */
+export interface EnableTOTPRequest {
+  /** 验证器生成的6位验证码 */
+  code: string;
+}
+
+/**
+ * 关闭两步验证请求
+ */
+export interface DisableTOTPRequest {
+  /** 支付密钥 */
+  pay_key: string;
+  /** 两步验证码或恢复码 */
+  code: string;
+}
+
+/**
+ * 恢复码响应，明文仅返回一次
+ */
+export interface TOTPRecoveryCodesResponse {
+  /** 恢复码列表 */
+  recovery_codes: string[];
+}
+
+/**
+ * 设置两步验证阈值请求
+ */
+export interface UpdateTOTPThresholdRequest {
+  /** 阈值，null 表示使用系统阈值 */
+  threshold: string | null;
+  /** 两步验证码或恢复码 */
+  code: string;
+}
@@ -1,4 +1,13 @@
 import { BaseService } from '../core/base.service';
+import type {
+  TOTPStatus,
+  SetupTOTPRequest,
+  SetupTOTPResponse,
+  EnableTOTPRequest,
+  DisableTOTPRequest,
+  TOTPRecoveryCodesResponse,
+  UpdateTOTPThresholdRequest,
+} from './types';
 
 /**
  * 用户服务
@@ -10,6 +19,7 @@ export class UserService extends BaseService {
   /**
    * 更新用户支付密钥
    * @param payKey - 新的支付密钥
+   * @param oldPayKey - 原支付密钥，已设置支付密钥且非近期登录时必填
    * @returns void
    * @throws {UnauthorizedError} 当用户未登录时
    * @throws {ValidationError} 当支付密钥格式无效时
@@ -22,9 +32,67 @@ export class UserService extends BaseService {
    * @remarks
    * - 支付密钥必须为6位数字
    * - 只能更新当前登录用户的支付密钥
+   * - 已设置支付密钥时需提供原支付密钥，或在重新登录后的短时间内修改
    */
-  static async updatePayKey(payKey: string): Promise<void> {
-    return this.put<void>('/pay-key', { pay_key: payKey });
+  static async updatePayKey(payKey: string, oldPayKey?: string): Promise<void> {
+    return this.put<void>('/pay-key', { pay_key: payKey, old_pay_key: oldPayKey });
+  }
+
+  /**
+   * 查询两步验证状态
+   * @returns 两步验证状态
+   */
+  static async getTOTPStatus(): Promise<TOTPStatus> {
+    return this.get<TOTPStatus>('/totp');
+  }
+
+  /**
+   * 生成两步验证密钥
+   * @param request - 包含支付密钥
+   * @returns 密钥与 otpauth 链接
+   *
+   * @remarks
+   * - 需先设置支付密钥
+   * - 生成后需调用 enableTOTP 提交验证码才会开启
+   */
+  static async setupTOTP(request: SetupTOTPRequest): Promise<SetupTOTPResponse> {
+    return this.post<SetupTOTPResponse>('/totp/setup', request);
+  }
+
+  /**
+   * 开启两步验证
+   * @param request - 验证器生成的验证码
+   * @returns 恢复码，仅返回一次
+   */
+  static async enableTOTP(request: EnableTOTPRequest): Promise<TOTPRecoveryCodesResponse> {
+    return this.post<TOTPRecoveryCodesResponse>('/totp/enable', request);
   }
-}
 
+  /**
+   * 关闭两步验证
+   * @param request - 支付密钥与验证码（或恢复码）
+   */
+  static async disableTOTP(request: DisableTOTPRequest): Promise<void> {
+    return this.post<void>('/totp/disable', request);
+  }
+
+  /**
+   * 重新生成恢复码，原有恢复码全部作废
+   * @param code - 验证码或恢复码
+   * @returns 新的恢复码，仅返回一次
+   */
+  static async regenerateTOTPRecoveryCodes(code: string): Promise<TOTPRecoveryCodesResponse> {
+    return this.post<TOTPRecoveryCodesResponse>('/totp/recovery-codes', { code });
+  }
+
+  /**
+   * 设置两步验证阈值
+   * @param request - 阈值与验证码（或恢复码）
+   *
+   * @remarks
+   * - 阈值高于系统阈值时以系统阈值为准
+   */
+  static async updateTOTPThreshold(request: UpdateTOTPThresholdRequest): Promise<void> {
+    return this.put<void>('/totp/threshold', request);
+  }
+}
@@ -12,6 +12,11 @@ const nextConfig: NextConfig = {
         source: '/epay/pay/:path*',
         destination: `${ backendUrl }/pay/:path*`,
       },
+      // 易支付兼容接口 - 创建订单（API 方式）
+      {
+        source: '/epay/mapi.php',
+        destination: `${ backendUrl }/mapi.php`,
+      },
       // 易支付兼容接口 - 查询订单和退款
       {
         source: '/epay/api.php',
@@ -0,0 +1,116 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fee_revenue
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linux-do/credit/internal/db"
+	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/util"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+const maxRangeDays = 366
+
+// GetFeeRevenueRequest 查询手续费收入请求
+type GetFeeRevenueRequest struct {
+	StartDate string `json:"start_date" form:"start_date" binding:"required"`
+	EndDate   string `json:"end_date" form:"end_date" binding:"required"`
+}
+
+// DailyFeeRevenue 按交易日统计的手续费收入
+type DailyFeeRevenue struct {
+	Date        string          `json:"date"`
+	OrderCount  int64           `json:"order_count"`
+	Fee         decimal.Decimal `json:"fee"`
+	RefundedFee decimal.Decimal `json:"refunded_fee"`
+	NetFee      decimal.Decimal `json:"net_fee"`
+}
+
+// GetFeeRevenueResponse 查询手续费收入响应
+type GetFeeRevenueResponse struct {
+	PlatformBalance  decimal.Decimal   `json:"platform_balance"`
+	OrderCount       int64             `json:"order_count"`
+	TotalFee         decimal.Decimal   `json:"total_fee"`
+	TotalRefundedFee decimal.Decimal   `json:"total_refunded_fee"`
+	NetFee           decimal.Decimal   `json:"net_fee"`
+	Daily            []DailyFeeRevenue `json:"daily"`
+}
+
+// GetFeeRevenue 查询平台手续费收入，按订单交易日统计收取及退还的手续费
+// @Tags admin
+// @Produce json
+// @Param request query GetFeeRevenueRequest true "request query"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/admin/fee-revenue [get]
+func GetFeeRevenue(c *gin.Context) {
+	var req GetFeeRevenueRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	startDate, errStart := time.ParseInLocation(time.DateOnly, req.StartDate, time.Local)
+	endDate, errEnd := time.ParseInLocation(time.DateOnly, req.EndDate, time.Local)
+	if errStart != nil || errEnd != nil {
+		c.JSON(http.StatusBadRequest, util.Err(InvalidDateFormat))
+		return
+	}
+	endDate = endDate.AddDate(0, 0, 1)
+	if !endDate.After(startDate) || endDate.Sub(startDate) > maxRangeDays*24*time.Hour {
+		c.JSON(http.StatusBadRequest, util.Err(InvalidDateRange))
+		return
+	}
+
+	response := &GetFeeRevenueResponse{Daily: []DailyFeeRevenue{}}
+
+	// 平台手续费账户余额
+	var account model.LedgerAccount
+	if err := db.DB(c.Request.Context()).
+		Where("type = ? AND user_id = ?", model.LedgerAccountPlatformFee, 0).
+		First(&account).Error; err == nil {
+		response.PlatformBalance = account.Balance
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	if err := db.DB(c.Request.Context()).Model(&model.Order{}).
+		Select("TO_CHAR(trade_time, 'YYYY-MM-DD') AS date, COUNT(*) AS order_count, SUM(fee) AS fee, SUM(refunded_fee) AS refunded_fee, SUM(fee - refunded_fee) AS net_fee").
+		Where("type IN ? AND status IN ? AND fee > 0 AND trade_time >= ? AND trade_time < ?",
+			[]model.OrderType{model.OrderTypePayment, model.OrderTypeOnline}, model.PaidOrderStatuses, startDate, endDate).
+		Group("date").
+		Order("date ASC").
+		Scan(&response.Daily).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	for _, daily := range response.Daily {
+		response.OrderCount += daily.OrderCount
+		response.TotalFee = response.TotalFee.Add(daily.Fee)
+		response.TotalRefundedFee = response.TotalRefundedFee.Add(daily.RefundedFee)
+		response.NetFee = response.NetFee.Add(daily.NetFee)
+	}
+
+	c.JSON(http.StatusOK, util.OK(response))
+}
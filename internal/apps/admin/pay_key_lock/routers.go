@@ -0,0 +1,104 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pay_key_lock
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linux-do/credit/internal/apps/oauth"
+	"github.com/linux-do/credit/internal/db"
+	"github.com/linux-do/credit/internal/logger"
+	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/service"
+	"github.com/linux-do/credit/internal/util"
+	"gorm.io/gorm"
+)
+
+// GetPayKeyLockRequest 查询支付密码锁定状态请求
+type GetPayKeyLockRequest struct {
+	Username string `json:"username" form:"username" binding:"required,max=64"`
+}
+
+// GetPayKeyLockResponse 查询支付密码锁定状态响应
+type GetPayKeyLockResponse struct {
+	UserID   uint64 `json:"user_id"`
+	Username string `json:"username"`
+	*service.PayKeyLockStatus
+}
+
+// GetPayKeyLock 按用户名查询支付密码错误次数和锁定状态
+// @Tags admin
+// @Produce json
+// @Param request query GetPayKeyLockRequest true "request query"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/admin/pay-key-locks [get]
+func GetPayKeyLock(c *gin.Context) {
+	var req GetPayKeyLockRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	var user model.User
+	if err := db.DB(c.Request.Context()).Where("username = ?", req.Username).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, util.Err(UserNotFound))
+		} else {
+			c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		}
+		return
+	}
+
+	status, err := service.GetPayKeyLockStatus(c.Request.Context(), user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OK(GetPayKeyLockResponse{
+		UserID:           user.ID,
+		Username:         user.Username,
+		PayKeyLockStatus: status,
+	}))
+}
+
+// UnlockPayKey 解除用户支付密码锁定并清空错误次数
+// @Tags admin
+// @Produce json
+// @Param user_id path string true "用户ID"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/admin/pay-key-locks/{user_id} [delete]
+func UnlockPayKey(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(UserNotFound))
+		return
+	}
+
+	if err := service.UnlockPayKey(c.Request.Context(), userID); err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	admin, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
+	logger.InfoF(c.Request.Context(), "[PayKey] 管理员解除支付密码锁定: admin_id=%d, user_id=%d", admin.ID, userID)
+
+	c.JSON(http.StatusOK, util.OKNil())
+}
@@ -0,0 +1,27 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciliation
+
+const (
+	DiscrepancyNotFound    = "对账差异不存在"
+	DiscrepancyNotOpen     = "对账差异已处理"
+	RepairDisabled         = "未开启对账修复"
+	FieldNotRepairable     = "该字段不支持修复，请排查账本记录"
+	BalanceChanged         = "余额已发生变化，请重新对账后再修复"
+	ReconcileAlreadyQueued = "对账任务已在执行中"
+	RefundsNotBackfilled   = "存在未回填退款金额的历史退款订单，请先完成数据迁移"
+)
@@ -0,0 +1,216 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciliation
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hibiken/asynq"
+	"github.com/linux-do/credit/internal/apps/oauth"
+	"github.com/linux-do/credit/internal/config"
+	"github.com/linux-do/credit/internal/db"
+	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/service"
+	"github.com/linux-do/credit/internal/task"
+	"github.com/linux-do/credit/internal/task/scheduler"
+	"github.com/linux-do/credit/internal/util"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ListBalanceDiscrepanciesRequest 查询对账差异请求
+type ListBalanceDiscrepanciesRequest struct {
+	Page     int     `json:"page" form:"page" binding:"min=1"`
+	PageSize int     `json:"page_size" form:"page_size" binding:"min=1,max=100"`
+	Status   string  `json:"status" form:"status" binding:"omitempty,oneof=open resolved repaired"`
+	UserID   *uint64 `json:"user_id" form:"user_id" binding:"omitempty"`
+}
+
+// ListBalanceDiscrepanciesResponse 查询对账差异响应
+type ListBalanceDiscrepanciesResponse struct {
+	Total         int64                      `json:"total"`
+	Page          int                        `json:"page"`
+	PageSize      int                        `json:"page_size"`
+	Discrepancies []model.BalanceDiscrepancy `json:"discrepancies"`
+}
+
+// ListBalanceDiscrepancies 查询对账差异列表
+// @Tags admin
+// @Produce json
+// @Param request query ListBalanceDiscrepanciesRequest true "request query"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/admin/balance-discrepancies [get]
+func ListBalanceDiscrepancies(c *gin.Context) {
+	var req ListBalanceDiscrepanciesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	baseQuery := db.DB(c.Request.Context()).Model(&model.BalanceDiscrepancy{}).
+		Select("balance_discrepancies.*, users.username").
+		Joins("JOIN users ON balance_discrepancies.user_id = users.id")
+
+	if req.Status != "" {
+		baseQuery = baseQuery.Where("balance_discrepancies.status = ?", model.DiscrepancyStatus(req.Status))
+	}
+	if req.UserID != nil {
+		baseQuery = baseQuery.Where("balance_discrepancies.user_id = ?", *req.UserID)
+	}
+
+	var total int64
+	if err := baseQuery.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	response := &ListBalanceDiscrepanciesResponse{
+		Total:         total,
+		Page:          req.Page,
+		PageSize:      req.PageSize,
+		Discrepancies: []model.BalanceDiscrepancy{},
+	}
+
+	offset := (req.Page - 1) * req.PageSize
+	if err := baseQuery.Order("balance_discrepancies.created_at DESC").Offset(offset).Limit(req.PageSize).Find(&response.Discrepancies).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OK(response))
+}
+
+// TriggerReconcile 立即下发余额对账任务
+// @Tags admin
+// @Produce json
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/admin/balance-discrepancies/reconcile [post]
+func TriggerReconcile(c *gin.Context) {
+	if _, err := scheduler.AsynqClient.Enqueue(
+		asynq.NewTask(task.ReconcileUserBalancesTask, nil),
+		asynq.MaxRetry(3),
+		asynq.Timeout(time.Hour),
+		asynq.Unique(time.Hour),
+	); err != nil {
+		if errors.Is(err, asynq.ErrDuplicateTask) {
+			c.JSON(http.StatusBadRequest, util.Err(ReconcileAlreadyQueued))
+		} else {
+			c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OKNil())
+}
+
+// RepairBalanceDiscrepancy 修复对账差异，将用户余额字段修正为订单流水推算值
+// 修复前重新推算，余额与发现差异时不一致则拒绝修复；可用余额通过对账调整凭证入账
+// @Tags admin
+// @Produce json
+// @Param id path string true "差异ID"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/admin/balance-discrepancies/{id}/repair [post]
+func RepairBalanceDiscrepancy(c *gin.Context) {
+	if !config.Config.Reconcile.RepairEnabled {
+		c.JSON(http.StatusForbidden, util.Err(RepairDisabled))
+		return
+	}
+
+	admin, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
+
+	if err := db.DB(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		var discrepancy model.BalanceDiscrepancy
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", c.Param("id")).
+			First(&discrepancy).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New(DiscrepancyNotFound)
+			}
+			return err
+		}
+		if discrepancy.Status != model.DiscrepancyStatusOpen {
+			return errors.New(DiscrepancyNotOpen)
+		}
+		if discrepancy.Field == model.BalanceFieldLedgerBalance {
+			return errors.New(FieldNotRepairable)
+		}
+
+		unbackfilled, err := model.HasUnbackfilledRefundOrders(tx)
+		if err != nil {
+			return err
+		}
+		if unbackfilled {
+			return errors.New(RefundsNotBackfilled)
+		}
+
+		// 锁定用户后重新推算，确保修复期间余额不再变动
+		var user model.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", discrepancy.UserID).
+			First(&user).Error; err != nil {
+			return err
+		}
+
+		balances, err := model.GetExpectedUserBalances(tx, []uint64{user.ID})
+		if err != nil {
+			return err
+		}
+		if len(balances) != 1 {
+			return errors.New(BalanceChanged)
+		}
+		values, ok := balances[0].Mismatches()[discrepancy.Field]
+		if !ok || !values[0].Equal(discrepancy.Actual) || !values[1].Equal(discrepancy.Expected) {
+			return errors.New(BalanceChanged)
+		}
+
+		if discrepancy.Field == model.BalanceFieldAvailableBalance {
+			if err := service.PostBalanceAdjustment(tx, user.ID, discrepancy.Expected.Sub(discrepancy.Actual),
+				fmt.Sprintf("对账修复[ID:%d]", discrepancy.ID)); err != nil {
+				return err
+			}
+		} else {
+			if err := tx.Model(&model.User{}).
+				Where("id = ?", user.ID).
+				UpdateColumn(string(discrepancy.Field), discrepancy.Expected).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&discrepancy).
+			Updates(map[string]interface{}{
+				"status":              model.DiscrepancyStatusRepaired,
+				"resolved_by_user_id": admin.ID,
+				"resolved_at":         time.Now(),
+			}).Error
+	}); err != nil {
+		switch err.Error() {
+		case DiscrepancyNotFound:
+			c.JSON(http.StatusNotFound, util.Err(err.Error()))
+		case DiscrepancyNotOpen, FieldNotRepairable, BalanceChanged, RefundsNotBackfilled:
+			c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		default:
+			c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OKNil())
+}
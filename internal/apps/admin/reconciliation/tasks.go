@@ -0,0 +1,153 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciliation
+
+import (
+	"context"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/linux-do/credit/internal/config"
+	"github.com/linux-do/credit/internal/db"
+	"github.com/linux-do/credit/internal/logger"
+	"github.com/linux-do/credit/internal/model"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// HandleReconcileUserBalances 分批由订单流水推算每个用户的余额，与用户余额字段及账本余额核对
+// 不一致的字段记录为 open 差异，已恢复一致的 open 差异自动标记为 resolved
+func HandleReconcileUserBalances(ctx context.Context, t *asynq.Task) error {
+	batchSize := config.Config.Reconcile.BatchSize
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+
+	// 历史退款订单未回填时推算值不可信，跳过本次对账
+	unbackfilled, err := model.HasUnbackfilledRefundOrders(db.DB(ctx))
+	if err != nil {
+		logger.ErrorF(ctx, "检查历史退款订单失败: %v", err)
+		return err
+	}
+	if unbackfilled {
+		logger.ErrorF(ctx, "余额对账已跳过: %s", RefundsNotBackfilled)
+		return nil
+	}
+
+	lastID := uint64(0)
+	checked, mismatched := 0, 0
+
+	for {
+		var userIDs []uint64
+		if err := db.DB(ctx).Model(&model.User{}).
+			Where("id > ?", lastID).
+			Order("id ASC").
+			Limit(batchSize).
+			Pluck("id", &userIDs).Error; err != nil {
+			logger.ErrorF(ctx, "查询用户失败: %v", err)
+			return err
+		}
+
+		if len(userIDs) == 0 {
+			break
+		}
+
+		n, err := reconcileBatch(ctx, userIDs)
+		if err != nil {
+			logger.ErrorF(ctx, "对账失败: 用户ID范围[%d, %d], error=%v", userIDs[0], userIDs[len(userIDs)-1], err)
+			return err
+		}
+
+		checked += len(userIDs)
+		mismatched += n
+		lastID = userIDs[len(userIDs)-1]
+	}
+
+	logger.InfoF(ctx, "余额对账完成: 共核对 %d 个用户, 发现 %d 处差异", checked, mismatched)
+	return nil
+}
+
+// reconcileBatch 核对一批用户，返回本批发现的差异数量
+func reconcileBatch(ctx context.Context, userIDs []uint64) (int, error) {
+	mismatched := 0
+
+	err := db.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		balances, err := model.GetExpectedUserBalances(tx, userIDs)
+		if err != nil {
+			return err
+		}
+
+		var openDiscrepancies []model.BalanceDiscrepancy
+		if err := tx.Where("user_id IN ? AND status = ?", userIDs, model.DiscrepancyStatusOpen).
+			Find(&openDiscrepancies).Error; err != nil {
+			return err
+		}
+
+		mismatchesByUser := make(map[uint64]map[model.BalanceField][2]decimal.Decimal, len(balances))
+		var discrepancies []model.BalanceDiscrepancy
+		for i := range balances {
+			mismatches := balances[i].Mismatches()
+			mismatchesByUser[balances[i].UserID] = mismatches
+			for field, values := range mismatches {
+				discrepancies = append(discrepancies, model.BalanceDiscrepancy{
+					UserID:   balances[i].UserID,
+					Field:    field,
+					Actual:   values[0],
+					Expected: values[1],
+					Diff:     values[0].Sub(values[1]),
+					Status:   model.DiscrepancyStatusOpen,
+				})
+			}
+		}
+		mismatched = len(discrepancies)
+
+		// 记录差异，已存在 open 差异时刷新为最新值
+		// 冲突目标需与部分唯一索引 idx_balance_discrepancies_open 的谓词字面一致，不能使用参数占位符
+		if len(discrepancies) > 0 {
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:     []clause.Column{{Name: "user_id"}, {Name: "field"}},
+				TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "status = 'open'"}}},
+				DoUpdates:   clause.AssignmentColumns([]string{"actual", "expected", "diff", "updated_at"}),
+			}).Create(&discrepancies).Error; err != nil {
+				return err
+			}
+		}
+
+		// 已恢复一致的差异标记为已解决
+		var resolvedIDs []uint64
+		for _, d := range openDiscrepancies {
+			if _, ok := mismatchesByUser[d.UserID][d.Field]; !ok {
+				resolvedIDs = append(resolvedIDs, d.ID)
+			}
+		}
+		if len(resolvedIDs) > 0 {
+			if err := tx.Model(&model.BalanceDiscrepancy{}).
+				Where("id IN ? AND status = ?", resolvedIDs, model.DiscrepancyStatusOpen).
+				Updates(map[string]interface{}{
+					"status":      model.DiscrepancyStatusResolved,
+					"resolved_at": time.Now(),
+				}).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	return mismatched, err
+}
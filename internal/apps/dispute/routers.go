@@ -19,6 +19,7 @@ package dispute
 import (
 	"errors"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -26,6 +27,7 @@ import (
 	"github.com/linux-do/credit/internal/apps/oauth"
 	"github.com/linux-do/credit/internal/db"
 	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/service"
 	"github.com/linux-do/credit/internal/util"
 	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
@@ -221,7 +223,10 @@ func CreateDispute(c *gin.Context) {
 				return err
 			}
 
-			return nil
+			return service.EnqueueWebhookEvent(tx, &order, model.WebhookEventDisputeOpened, map[string]string{
+				"dispute_id":     strconv.FormatUint(dispute.ID, 10),
+				"dispute_reason": dispute.Reason,
+			})
 		},
 	); err != nil {
 		errMsg := err.Error()
@@ -251,6 +256,7 @@ type RefundReviewRequest struct {
 // @Tags order
 // @Accept json
 // @Produce json
+// @Param Idempotency-Key header string false "幂等键，重复请求返回首次响应"
 // @Param request body RefundReviewRequest true "request body"
 // @Success 200 {object} util.ResponseAny
 // @Router /api/v1/order/refund-review [post]
@@ -303,24 +309,14 @@ func RefundReview(c *gin.Context) {
 					return err
 				}
 
-				merchantScoreDecrease := order.Amount.Mul(merchantPayConfig.ScoreRate).Round(0).IntPart()
-				if err := tx.Model(&model.User{}).
-					Where("id = ?", merchantUser.ID).
-					UpdateColumns(map[string]interface{}{
-						"available_balance": gorm.Expr("available_balance - ?", order.Amount),
-						"total_receive":     gorm.Expr("total_receive - ?", order.Amount),
-						"pay_score":         gorm.Expr("pay_score - ?", merchantScoreDecrease),
-					}).Error; err != nil {
+				// 按退款手续费策略计算退还的手续费
+				feeRefund, err := service.CalculateRefundFee(c.Request.Context(), &order, order.Amount)
+				if err != nil {
 					return err
 				}
 
-				if err := tx.Model(&model.User{}).
-					Where("id = ?", payerUser.ID).
-					UpdateColumns(map[string]interface{}{
-						"available_balance": gorm.Expr("available_balance + ?", order.Amount),
-						"total_payment":     gorm.Expr("total_payment - ?", order.Amount),
-						"pay_score":         gorm.Expr("pay_score - ?", order.Amount.Round(0).IntPart()),
-					}).Error; err != nil {
+				merchantScoreDecrease := order.Amount.Mul(merchantPayConfig.ScoreRate).Round(0).IntPart()
+				if err := service.PostOrderRefund(tx, model.LedgerEntryDisputeRefund, &order, order.Amount, feeRefund, merchantScoreDecrease, ""); err != nil {
 					return err
 				}
 
@@ -335,7 +331,19 @@ func RefundReview(c *gin.Context) {
 
 				if err := tx.Model(&model.Order{}).
 					Where("id = ?", order.ID).
-					Update("status", model.OrderStatusRefund).Error; err != nil {
+					UpdateColumns(map[string]interface{}{
+						"status":          model.OrderStatusRefund,
+						"refunded_amount": order.Amount,
+						"refunded_fee":    order.RefundedFee.Add(feeRefund),
+					}).Error; err != nil {
+					return err
+				}
+
+				if err := service.EnqueueWebhookEvent(tx, &order, model.WebhookEventOrderRefunded, map[string]string{
+					"dispute_id":     strconv.FormatUint(dispute.ID, 10),
+					"refund_money":   order.Amount.StringFixed(2),
+					"refunded_money": order.Amount.StringFixed(2),
+				}); err != nil {
 					return err
 				}
 			} else if status == model.DisputeStatusClosed {
@@ -358,7 +366,10 @@ func RefundReview(c *gin.Context) {
 				}
 			}
 
-			return nil
+			return service.EnqueueWebhookEvent(tx, &order, model.WebhookEventDisputeResolved, map[string]string{
+				"dispute_id":     strconv.FormatUint(dispute.ID, 10),
+				"dispute_status": string(status),
+			})
 		},
 	); err != nil {
 		errMsg := err.Error()
@@ -431,7 +442,10 @@ func CloseDispute(c *gin.Context) {
 				return err
 			}
 
-			return nil
+			return service.EnqueueWebhookEvent(tx, &order, model.WebhookEventDisputeResolved, map[string]string{
+				"dispute_id":     strconv.FormatUint(dispute.ID, 10),
+				"dispute_status": string(model.DisputeStatusClosed),
+			})
 		},
 	); err != nil {
 		errMsg := err.Error()
@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/hibiken/asynq"
@@ -28,6 +29,7 @@ import (
 	"github.com/linux-do/credit/internal/db"
 	"github.com/linux-do/credit/internal/logger"
 	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/service"
 	"github.com/linux-do/credit/internal/task"
 	"github.com/linux-do/credit/internal/task/scheduler"
 	"gorm.io/gorm"
@@ -140,28 +142,17 @@ func HandleAutoRefundSingleDispute(ctx context.Context, t *asynq.Task) error {
 		}
 
 		// 计算商家积分减少：订单金额 × 商家的 score_rate
-		merchantScoreDecrease := order.Amount.Mul(merchantPayConfig.ScoreRate).Round(0).IntPart()
-
-		// 商家(收款方)退款：扣除可用余额、总收款和积分
-		if err := tx.Model(&model.User{}).
-			Where("id = ?", payeeUser.ID).
-			UpdateColumns(map[string]interface{}{
-				"available_balance": gorm.Expr("available_balance - ?", order.Amount),
-				"total_receive":     gorm.Expr("total_receive - ?", order.Amount),
-				"pay_score":         gorm.Expr("pay_score - ?", merchantScoreDecrease),
-			}).Error; err != nil {
-			return fmt.Errorf("商家退款失败: %w", err)
+		// 按退款手续费策略计算退还的手续费
+		feeRefund, err := service.CalculateRefundFee(ctx, &order, order.Amount)
+		if err != nil {
+			return err
 		}
 
-		// 付款方收到退款：增加可用余额，减少总支付和支付积分
-		if err := tx.Model(&model.User{}).
-			Where("id = ?", payerUser.ID).
-			UpdateColumns(map[string]interface{}{
-				"available_balance": gorm.Expr("available_balance + ?", order.Amount),
-				"total_payment":     gorm.Expr("total_payment - ?", order.Amount),
-				"pay_score":         gorm.Expr("pay_score - ?", order.Amount.Round(0).IntPart()),
-			}).Error; err != nil {
-			return fmt.Errorf("付款方退款失败: %w", err)
+		merchantScoreDecrease := order.Amount.Mul(merchantPayConfig.ScoreRate).Round(0).IntPart()
+
+		// 记账：商家(收款方)扣除可用余额、总收款和积分，付款方增加可用余额，减少总支付和支付积分
+		if err := service.PostOrderRefund(tx, model.LedgerEntryDisputeRefund, &order, order.Amount, feeRefund, merchantScoreDecrease, ""); err != nil {
+			return fmt.Errorf("争议退款记账失败: %w", err)
 		}
 
 		// 更新争议状态为已退款，handler_user_id 设为 0（系统自动处理）
@@ -177,10 +168,27 @@ func HandleAutoRefundSingleDispute(ctx context.Context, t *asynq.Task) error {
 		// 更新订单状态为已退款
 		if err := tx.Model(&model.Order{}).
 			Where("id = ?", order.ID).
-			Update("status", model.OrderStatusRefund).Error; err != nil {
+			UpdateColumns(map[string]interface{}{
+				"status":          model.OrderStatusRefund,
+				"refunded_amount": order.Amount,
+				"refunded_fee":    order.RefundedFee.Add(feeRefund),
+			}).Error; err != nil {
 			return fmt.Errorf("更新订单状态失败: %w", err)
 		}
 
+		eventData := map[string]string{
+			"dispute_id":     strconv.FormatUint(dispute.ID, 10),
+			"dispute_status": string(model.DisputeStatusRefund),
+			"refund_money":   order.Amount.StringFixed(2),
+			"refunded_money": order.Amount.StringFixed(2),
+		}
+		if err := service.EnqueueWebhookEvent(tx, &order, model.WebhookEventOrderRefunded, eventData); err != nil {
+			return err
+		}
+		if err := service.EnqueueWebhookEvent(tx, &order, model.WebhookEventDisputeResolved, eventData); err != nil {
+			return err
+		}
+
 		logger.InfoF(ctx, "自动退款成功: 争议[ID:%d] 订单[ID:%d] 金额[%s] 付款方[%s] 商家[%s]",
 			dispute.ID, order.ID, order.Amount.String(), payerUser.Username, payeeUser.Username)
 
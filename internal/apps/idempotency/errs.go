@@ -0,0 +1,23 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package idempotency
+
+const (
+	InvalidIdempotencyKey = "Idempotency-Key 格式错误，长度应为 1-64 个可见字符"
+	IdempotencyKeyReused  = "Idempotency-Key 已用于其他请求"
+	RequestInProgress     = "相同 Idempotency-Key 的请求正在处理中"
+)
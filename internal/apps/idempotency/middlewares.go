@@ -0,0 +1,208 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linux-do/credit/internal/apps/oauth"
+	"github.com/linux-do/credit/internal/config"
+	"github.com/linux-do/credit/internal/db"
+	"github.com/linux-do/credit/internal/logger"
+	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/util"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// HeaderIdempotencyKey 客户端携带的幂等键请求头
+	HeaderIdempotencyKey = "Idempotency-Key"
+	// HeaderIdempotentReplayed 响应为重放结果时返回的响应头
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+
+	maxKeyLength = 64
+)
+
+// responseRecorder 记录下游处理器写出的响应体
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// WithIdempotencyKey 为资金类接口提供 Idempotency-Key 支持，需在 LoginRequired 之后使用
+// 未携带请求头时不做处理；同一用户同一幂等键的重复请求直接返回首次请求的响应，
+// 请求内容不同时拒绝；首次请求参数校验失败（4xx）时未产生资金变动，释放幂等键以便客户端修正后重试；
+// 服务端错误（5xx）无法确认资金是否变动，保留响应供重放，由客户端核实后使用新的幂等键
+func WithIdempotencyKey() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(HeaderIdempotencyKey)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if !validKey(key) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, util.Err(InvalidIdempotencyKey))
+			return
+		}
+
+		user, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, util.Err(err.Error()))
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		fp := fingerprint(c.Request.Method, c.Request.URL.Path, body)
+		record, acquired, err := acquire(c, user.ID, key, fp)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, util.Err(err.Error()))
+			return
+		}
+
+		if !acquired {
+			replay(c, record, fp)
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		// 使用独立的 context，避免客户端断开后无法保存响应
+		ctx := context.WithoutCancel(c.Request.Context())
+		status := recorder.Status()
+		if status >= http.StatusBadRequest && status < http.StatusInternalServerError {
+			if err := db.DB(ctx).Delete(&model.IdempotencyKey{}, record.ID).Error; err != nil {
+				logger.ErrorF(ctx, "[Idempotency] 释放幂等键失败: user_id=%d, key=%s, error=%v", user.ID, key, err)
+			}
+			return
+		}
+		if status >= http.StatusInternalServerError {
+			logger.ErrorF(ctx, "[Idempotency] 请求返回服务端错误，保留幂等键待核对: user_id=%d, key=%s, status=%d", user.ID, key, status)
+		}
+
+		if err := db.DB(ctx).Model(&model.IdempotencyKey{}).
+			Where("id = ?", record.ID).
+			Updates(map[string]interface{}{
+				"status_code":   status,
+				"response_body": recorder.body.Bytes(),
+			}).Error; err != nil {
+			logger.ErrorF(ctx, "[Idempotency] 保存幂等键响应失败，幂等键将保持处理中直至过期: user_id=%d, key=%s, error=%v", user.ID, key, err)
+		}
+	}
+}
+
+// acquire 占用幂等键，已过期的幂等键视为不存在
+// 处理中（未记录响应）的幂等键无法确认资金是否已变动，有效期内不会被重新占用
+// 返回：幂等键记录、是否由本次请求占用
+func acquire(c *gin.Context, userID uint64, key string, fp string) (*model.IdempotencyKey, bool, error) {
+	ttl := time.Duration(config.Config.Idempotency.TTLHours) * time.Hour
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+
+	record := model.IdempotencyKey{
+		UserID:      userID,
+		Key:         key,
+		Fingerprint: fp,
+		ExpiresAt:   time.Now().Add(ttl),
+	}
+	acquired := false
+
+	if err := db.DB(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND key = ? AND expires_at <= ?", userID, key, time.Now()).
+			Delete(&model.IdempotencyKey{}).Error; err != nil {
+			return err
+		}
+
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&record)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 1 {
+			acquired = true
+			return nil
+		}
+
+		return tx.Where("user_id = ? AND key = ?", userID, key).First(&record).Error
+	}); err != nil {
+		return nil, false, err
+	}
+
+	return &record, acquired, nil
+}
+
+// replay 对重复请求返回首次请求的响应
+func replay(c *gin.Context, record *model.IdempotencyKey, fp string) {
+	if !hmac.Equal([]byte(record.Fingerprint), []byte(fp)) {
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, util.Err(IdempotencyKeyReused))
+		return
+	}
+	if record.StatusCode == 0 {
+		c.AbortWithStatusJSON(http.StatusConflict, util.Err(RequestInProgress))
+		return
+	}
+
+	c.Header(HeaderIdempotentReplayed, "true")
+	c.Data(record.StatusCode, gin.MIMEJSON, record.ResponseBody)
+	c.Abort()
+}
+
+// fingerprint 计算请求指纹，请求体可能包含支付密码，使用 HMAC 避免指纹被离线穷举
+func fingerprint(method, path string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(config.Config.App.SessionSecret))
+	mac.Write([]byte(method))
+	mac.Write([]byte{'\n'})
+	mac.Write([]byte(path))
+	mac.Write([]byte{'\n'})
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// validKey 校验幂等键长度与字符范围
+func validKey(key string) bool {
+	if len(key) > maxKeyLength {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x21 || key[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
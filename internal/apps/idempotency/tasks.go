@@ -0,0 +1,40 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package idempotency
+
+import (
+	"context"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/linux-do/credit/internal/db"
+	"github.com/linux-do/credit/internal/logger"
+	"github.com/linux-do/credit/internal/model"
+)
+
+// HandleCleanupIdempotencyKeys 清理已过期的幂等键
+func HandleCleanupIdempotencyKeys(ctx context.Context, t *asynq.Task) error {
+	result := db.DB(ctx).Where("expires_at <= ?", time.Now()).Delete(&model.IdempotencyKey{})
+	if result.Error != nil {
+		logger.ErrorF(ctx, "清理过期幂等键失败: %v", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		logger.InfoF(ctx, "已清理 %d 个过期幂等键", result.RowsAffected)
+	}
+	return nil
+}
@@ -28,19 +28,23 @@ import (
 )
 
 type CreateAPIKeyRequest struct {
-	AppName        string `json:"app_name" binding:"required,max=20"`
-	AppHomepageURL string `json:"app_homepage_url" binding:"required,max=100,url"`
-	AppDescription string `json:"app_description" binding:"max=100"`
-	RedirectURI    string `json:"redirect_uri" binding:"omitempty,max=100,url"`
-	NotifyURL      string `json:"notify_url" binding:"required,max=100,url"`
+	AppName        string                 `json:"app_name" binding:"required,max=20"`
+	AppHomepageURL string                 `json:"app_homepage_url" binding:"required,max=100,url"`
+	AppDescription string                 `json:"app_description" binding:"max=100"`
+	RedirectURI    string                 `json:"redirect_uri" binding:"omitempty,max=100,url"`
+	NotifyURL      string                 `json:"notify_url" binding:"required,max=100,url"`
+	WebhookEvents  model.WebhookEventList `json:"webhook_events" binding:"omitempty,dive,oneof=order.paid order.refunded order.expired dispute.opened dispute.resolved"`
+	CallbackFormat string                 `json:"callback_format" binding:"omitempty,oneof=epay json"`
 }
 
 type UpdateAPIKeyRequest struct {
-	AppName        string `json:"app_name" binding:"omitempty,max=20"`
-	AppHomepageURL string `json:"app_homepage_url" binding:"omitempty,max=100,url"`
-	AppDescription string `json:"app_description" binding:"omitempty,max=100"`
-	RedirectURI    string `json:"redirect_uri" binding:"omitempty,max=100,url"`
-	NotifyURL      string `json:"notify_url" binding:"omitempty,max=100,url"`
+	AppName        string                 `json:"app_name" binding:"omitempty,max=20"`
+	AppHomepageURL string                 `json:"app_homepage_url" binding:"omitempty,max=100,url"`
+	AppDescription string                 `json:"app_description" binding:"omitempty,max=100"`
+	RedirectURI    string                 `json:"redirect_uri" binding:"omitempty,max=100,url"`
+	NotifyURL      string                 `json:"notify_url" binding:"omitempty,max=100,url"`
+	WebhookEvents  model.WebhookEventList `json:"webhook_events" binding:"omitempty,dive,oneof=order.paid order.refunded order.expired dispute.opened dispute.resolved"`
+	CallbackFormat string                 `json:"callback_format" binding:"omitempty,oneof=epay json"`
 }
 
 type APIKeyListResponse struct {
@@ -62,6 +66,11 @@ func CreateAPIKey(c *gin.Context) {
 		return
 	}
 
+	if err := util.ValidateWebhookURL(c.Request.Context(), req.NotifyURL); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
 	user, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
 
 	apiKey := model.MerchantAPIKey{
@@ -73,6 +82,8 @@ func CreateAPIKey(c *gin.Context) {
 		AppDescription: req.AppDescription,
 		RedirectURI:    req.RedirectURI,
 		NotifyURL:      req.NotifyURL,
+		WebhookEvents:  req.WebhookEvents,
+		CallbackFormat: req.CallbackFormat,
 	}
 
 	if err := db.DB(c.Request.Context()).Create(&apiKey).Error; err != nil {
@@ -129,6 +140,13 @@ func UpdateAPIKey(c *gin.Context) {
 		return
 	}
 
+	if req.NotifyURL != "" {
+		if err := util.ValidateWebhookURL(c.Request.Context(), req.NotifyURL); err != nil {
+			c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+			return
+		}
+	}
+
 	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, merchant.APIKeyObjKey)
 
 	updates := map[string]interface{}{
@@ -139,6 +157,14 @@ func UpdateAPIKey(c *gin.Context) {
 		"notify_url":       req.NotifyURL,
 	}
 
+	// 未传入时保持原有的事件订阅与回调格式
+	if req.WebhookEvents != nil {
+		updates["webhook_events"] = req.WebhookEvents
+	}
+	if req.CallbackFormat != "" {
+		updates["callback_format"] = req.CallbackFormat
+	}
+
 	if err := db.DB(c.Request.Context()).
 		Model(&apiKey).
 		Updates(updates).Error; err != nil {
@@ -17,22 +17,18 @@ limitations under the License.
 package link
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/hibiken/asynq"
 	"github.com/linux-do/credit/internal/apps/merchant"
 	"github.com/linux-do/credit/internal/apps/oauth"
 	"github.com/linux-do/credit/internal/common"
 	"github.com/linux-do/credit/internal/db"
 	"github.com/linux-do/credit/internal/model"
 	"github.com/linux-do/credit/internal/service"
-	"github.com/linux-do/credit/internal/task"
-    "github.com/linux-do/credit/internal/task/scheduler"
 	"github.com/linux-do/credit/internal/util"
 	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
@@ -40,9 +36,10 @@ import (
 
 // PayByLinkRequest 通过支付链接支付请求
 type PayByLinkRequest struct {
-	Token  string `json:"token" binding:"required"`
-	PayKey string `json:"pay_key" binding:"required,max=6"`
-	Remark string `json:"remark" binding:"max=100"`
+	Token    string `json:"token" binding:"required"`
+	PayKey   string `json:"pay_key" binding:"required,max=6"`
+	TOTPCode string `json:"totp_code" binding:"max=16"`
+	Remark   string `json:"remark" binding:"max=100"`
 }
 
 // CreatePaymentLinkRequest 创建支付链接请求
@@ -187,6 +184,7 @@ func DeletePaymentLink(c *gin.Context) {
 // @Tags merchant
 // @Accept json
 // @Produce json
+// @Param Idempotency-Key header string false "幂等键，重复请求返回首次响应"
 // @Param request body PayByLinkRequest true "支付请求"
 // @Success 200 {object} util.ResponseAny
 // @Router /api/v1/merchant/payment-links/pay [post]
@@ -205,8 +203,8 @@ func PayByLink(c *gin.Context) {
 
 	currentUser, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
 
-	if !currentUser.VerifyPayKey(req.PayKey) {
-		c.JSON(http.StatusBadRequest, util.Err(common.PayKeyIncorrect))
+	if err := service.VerifyPayKey(c.Request.Context(), currentUser, req.PayKey); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
 		return
 	}
 
@@ -252,13 +250,18 @@ func PayByLink(c *gin.Context) {
 
 	if err := db.DB(c.Request.Context()).Transaction(
 		func(tx *gorm.DB) error {
+			// 超过两步验证阈值时校验验证码，验证码在支付事务内消耗，支付失败时随事务回滚
+			if err := service.VerifyTOTPForPayment(c.Request.Context(), tx, currentUser, paymentLink.Amount, req.TOTPCode); err != nil {
+				return err
+			}
+
 			// 检查每日限额
 			if err := service.CheckDailyLimit(tx, currentUser.ID, paymentLink.Amount, payerPayConfig.DailyLimit); err != nil {
 				return err
 			}
 
 			// 计算手续费
-			_, merchantAmount, feePercent := service.CalculateFee(paymentLink.Amount, merchantPayConfig.FeeRate)
+			fee, merchantAmount, feePercent := service.CalculateFee(paymentLink.Amount, merchantPayConfig.FeeRate)
 			feeRemark := fmt.Sprintf("[系统]: 收取商家%d%%手续费", feePercent)
 
 			remark := req.Remark
@@ -275,6 +278,7 @@ func PayByLink(c *gin.Context) {
 				PayeeUserID: merchantUser.ID,
 				ClientID:    merchantAPIKey.ClientID,
 				Amount:      paymentLink.Amount,
+				Fee:         fee,
 				Status:      model.OrderStatusSuccess,
 				Type:        model.OrderTypeOnline,
 				Remark:      remark,
@@ -285,31 +289,14 @@ func PayByLink(c *gin.Context) {
 				return err
 			}
 
-			// 扣减用户余额
-			if err := service.DeductUserBalance(tx, currentUser.ID, paymentLink.Amount); err != nil {
-				return err
-			}
-
-			// 增加商户余额和积分
+			// 记账：扣减用户余额，增加商户余额和积分，手续费计入平台账户
 			merchantScoreIncrease := paymentLink.Amount.Mul(merchantPayConfig.ScoreRate).Round(0).IntPart()
-			if err := service.AddMerchantBalance(tx, merchantUser.ID, merchantAmount, merchantScoreIncrease); err != nil {
+			if err := service.PostOrderPayment(tx, &order, merchantUser.ID, merchantAmount, merchantScoreIncrease); err != nil {
 				return err
 			}
 
-			notifyPayload, _ := json.Marshal(map[string]interface{}{
-				"order_id":  order.ID,
-				"client_id": merchantAPIKey.ClientID,
-			})
-			if _, errTask := scheduler.AsynqClient.Enqueue(
-				asynq.NewTask(task.MerchantPaymentNotifyTask, notifyPayload),
-				asynq.Queue(task.QueueWebhook),
-				asynq.MaxRetry(10),
-				asynq.Timeout(30*time.Second),
-			); errTask != nil {
-				return fmt.Errorf("下发商户回调任务失败: %w", errTask)
-			}
-
-			return nil
+			// 下发商户回调任务，事务提交后由发件箱中继投递
+			return service.EnqueueMerchantPaymentNotify(tx, order.ID, merchantAPIKey.ClientID)
 		},
 	); err != nil {
 		errMsg := err.Error()
@@ -318,6 +305,8 @@ func PayByLink(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, util.Err(common.InsufficientBalance))
 		case common.DailyLimitExceeded:
 			c.JSON(http.StatusBadRequest, util.Err(common.DailyLimitExceeded))
+		case common.TOTPRequired, common.TOTPIncorrect, common.TOTPUnavailable, common.PayKeyLocked:
+			c.JSON(http.StatusBadRequest, util.Err(errMsg))
 		default:
 			c.JSON(http.StatusInternalServerError, util.Err(errMsg))
 		}
@@ -0,0 +1,135 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notification
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linux-do/credit/internal/apps/oauth"
+	"github.com/linux-do/credit/internal/db"
+	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/util"
+)
+
+// ListNotificationsRequest 查询站内通知请求
+type ListNotificationsRequest struct {
+	Page       int  `form:"page" binding:"min=1"`
+	PageSize   int  `form:"page_size" binding:"min=1,max=100"`
+	UnreadOnly bool `form:"unread"`
+}
+
+// ListNotificationsResponse 查询站内通知响应
+type ListNotificationsResponse struct {
+	Total         int64                `json:"total"`
+	UnreadCount   int64                `json:"unread_count"`
+	Page          int                  `json:"page"`
+	PageSize      int                  `json:"page_size"`
+	Notifications []model.Notification `json:"notifications"`
+}
+
+// ListNotifications 获取当前用户的站内通知
+// @Tags notification
+// @Produce json
+// @Param request query ListNotificationsRequest true "request query"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/notifications [get]
+func ListNotifications(c *gin.Context) {
+	req := ListNotificationsRequest{Page: 1, PageSize: 20}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	user, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
+
+	response := ListNotificationsResponse{
+		Page:          req.Page,
+		PageSize:      req.PageSize,
+		Notifications: []model.Notification{},
+	}
+
+	if err := db.DB(c.Request.Context()).Model(&model.Notification{}).
+		Where("user_id = ? AND is_read = ?", user.ID, false).
+		Count(&response.UnreadCount).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	query := db.DB(c.Request.Context()).Model(&model.Notification{}).Where("user_id = ?", user.ID)
+	if req.UnreadOnly {
+		query = query.Where("is_read = ?", false)
+	}
+
+	if err := query.Count(&response.Total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	offset := (req.Page - 1) * req.PageSize
+	if err := query.Order("created_at DESC").
+		Offset(offset).
+		Limit(req.PageSize).
+		Find(&response.Notifications).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OK(response))
+}
+
+// ReadNotification 将单条通知标记为已读
+// @Tags notification
+// @Produce json
+// @Param id path uint64 true "通知 ID"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/notifications/{id}/read [post]
+func ReadNotification(c *gin.Context) {
+	user, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
+
+	result := db.DB(c.Request.Context()).Model(&model.Notification{}).
+		Where("id = ? AND user_id = ?", c.Param("id"), user.ID).
+		Update("is_read", true)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(result.Error.Error()))
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, util.Err(NotificationNotFound))
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OKNil())
+}
+
+// ReadAllNotifications 将当前用户的全部通知标记为已读
+// @Tags notification
+// @Produce json
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/notifications/read-all [post]
+func ReadAllNotifications(c *gin.Context) {
+	user, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
+
+	if err := db.DB(c.Request.Context()).Model(&model.Notification{}).
+		Where("user_id = ? AND is_read = ?", user.ID, false).
+		Update("is_read", true).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OKNil())
+}
@@ -24,6 +24,7 @@ const (
 	UserNameKey = "username"
 	UserIDKey   = "user_id"
 	UserObjKey  = "user_obj"
+	LoginAtKey  = "login_at"
 )
 
 const (
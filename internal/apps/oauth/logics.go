@@ -22,6 +22,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
@@ -47,6 +48,15 @@ func GetUserIDFromContext(c *gin.Context) uint64 {
 	return GetUserIDFromSession(session)
 }
 
+// GetLoginAtFromContext 获取当前会话的登录时间，旧会话未记录时返回零值
+func GetLoginAtFromContext(c *gin.Context) time.Time {
+	loginAt, ok := sessions.Default(c).Get(LoginAtKey).(int64)
+	if !ok {
+		return time.Time{}
+	}
+	return time.Unix(loginAt, 0)
+}
+
 // doOAuth 执行 OAuth2/OIDC 认证流程
 func doOAuth(ctx context.Context, code string, nonce string) (*model.User, error) {
 	ctx, span := otel_trace.Start(ctx, "OAuth")
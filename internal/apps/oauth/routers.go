@@ -19,6 +19,7 @@ package oauth
 import (
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/coreos/go-oidc/v3/oidc"
 	"github.com/gin-contrib/sessions"
@@ -98,6 +99,7 @@ func Callback(c *gin.Context) {
 	session := sessions.Default(c)
 	session.Set(UserIDKey, user.ID)
 	session.Set(UserNameKey, user.Username)
+	session.Set(LoginAtKey, time.Now().Unix())
 	if err := session.Save(); err != nil {
 		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
 		return
@@ -124,6 +126,8 @@ type BasicUserInfo struct {
 	RemainQuota      decimal.Decimal  `json:"remain_quota"`
 	PayLevel         model.PayLevel   `json:"pay_level"`
 	DailyLimit       *int64           `json:"daily_limit"`
+	TOTPEnabled      bool             `json:"totp_enabled"`
+	TOTPThreshold    *decimal.Decimal `json:"totp_threshold"`
 }
 
 // UserInfo godoc
@@ -151,6 +155,17 @@ func UserInfo(c *gin.Context) {
 		remainQuota = decimal.NewFromInt(*payConfig.DailyLimit).Sub(todayUsed)
 	}
 
+	// 两步验证生效阈值，未开启时为空
+	var totpThreshold *decimal.Decimal
+	if user.TOTPEnabled {
+		threshold, err := service.TOTPThreshold(c.Request.Context(), user)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+			return
+		}
+		totpThreshold = &threshold
+	}
+
 	c.JSON(
 		http.StatusOK,
 		util.OK(BasicUserInfo{
@@ -171,6 +186,8 @@ func UserInfo(c *gin.Context) {
 			RemainQuota:      remainQuota,
 			PayLevel:         payConfig.Level,
 			DailyLimit:       payConfig.DailyLimit,
+			TOTPEnabled:      user.TOTPEnabled,
+			TOTPThreshold:    totpThreshold,
 		}),
 	)
 }
@@ -31,7 +31,7 @@ type TransactionListRequest struct {
 	Page          int        `json:"page" form:"page" binding:"min=1"`
 	PageSize      int        `json:"page_size" form:"page_size" binding:"min=1,max=100"`
 	Type          string     `json:"type" form:"type" binding:"omitempty,oneof=receive payment transfer community online"`
-	Status        string     `json:"status" form:"status" binding:"omitempty,oneof=success pending failed expired disputing refund refused"`
+	Status        string     `json:"status" form:"status" binding:"omitempty,oneof=success pending failed expired disputing refund refused partially_refunded closed"`
 	ClientID      string     `json:"client_id" form:"client_id" binding:"omitempty"`
 	StartTime     *time.Time `json:"startTime" form:"startTime" binding:"omitempty"`
 	EndTime       *time.Time `json:"endTime" form:"endTime" binding:"omitempty,gtfield=StartTime"`
@@ -25,8 +25,27 @@ import (
 	"github.com/linux-do/credit/internal/db"
 	"github.com/linux-do/credit/internal/logger"
 	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/service"
 )
 
+// HandleExpirePendingOrders 分批清理已过期的 pending 订单，兜底 Redis 过期事件丢失或延迟的情况
+func HandleExpirePendingOrders(ctx context.Context, t *asynq.Task) error {
+	batchSize := config.Config.Scheduler.ExpirePendingOrdersBatchSize
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+
+	total, err := service.ExpirePendingOrders(ctx, batchSize)
+	if err != nil {
+		logger.ErrorF(ctx, "清理过期订单失败: 已处理 %d 个, error=%v", total, err)
+		return err
+	}
+	if total > 0 {
+		logger.InfoF(ctx, "已将 %d 个已过期的 pending 订单设置为 expired", total)
+	}
+	return nil
+}
+
 // HandleSyncOrdersToClickHouse 同步订单数据
 func HandleSyncOrdersToClickHouse(ctx context.Context, t *asynq.Task) error {
 	if !config.Config.ClickHouse.Enabled {
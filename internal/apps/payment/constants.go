@@ -16,6 +16,8 @@ limitations under the License.
 
 package payment
 
+import "time"
+
 const (
 	APIKeyObjKey          = "payment_api_key_obj"
 	CreateOrderRequestKey = "payment_create_order_request"
@@ -24,6 +26,45 @@ const (
 const (
 	// OrderMerchantIDCacheKeyFormat Redis key 格式，用于存储订单号对应的商户ID
 	OrderMerchantIDCacheKeyFormat = "payment:order:%s"
-	// OrderExpireKeyFormat Redis key 格式，用于订单过期监听，key中包含订单ID
+	// OrderExpireKeyFormat Redis key 格式，用于订单过期监听，key中包含订单ID，value为加密后的订单号
 	OrderExpireKeyFormat = "payment:order:expire:%d"
+	// LDPayNonceKeyFormat Redis key 格式，用于 LDPay 请求防重放，key中包含 ClientID 和 nonce
+	LDPayNonceKeyFormat = "payment:ldpay:nonce:%s:%s"
+)
+
+const (
+	// LDPaySignType LDPay 协议签名算法
+	LDPaySignType = "HMAC-SHA256"
+	// LDPayTimestampTolerance LDPay 请求时间戳允许的最大偏差
+	LDPayTimestampTolerance = 5 * time.Minute
+)
+
+const (
+	// WebhookResponseSnippetLimit 投递记录中保存的商户响应体最大字符数
+	WebhookResponseSnippetLimit = 1024
+)
+
+const (
+	// WebhookEventIDHeader JSON 格式回调的事件 ID 请求头，用于商户去重
+	WebhookEventIDHeader = "X-Credit-Event-Id"
+	// WebhookEventHeader JSON 格式回调的事件类型请求头
+	WebhookEventHeader = "X-Credit-Event"
+	// WebhookTimestampHeader JSON 格式回调的时间戳请求头（Unix 秒）
+	WebhookTimestampHeader = "X-Credit-Timestamp"
+	// WebhookSignatureHeader JSON 格式回调的签名请求头，值为 HMAC-SHA256(ClientSecret, timestamp + "." + body) 的十六进制小写
+	WebhookSignatureHeader = "X-Credit-Signature"
+)
+
+const (
+	// NotifyFailedNotificationTitle 回调失败站内提醒标题
+	NotifyFailedNotificationTitle = "商户回调失败"
+	// NotifyFailedNotificationContent 回调失败站内提醒内容，参数为应用名称与订单号
+	NotifyFailedNotificationContent = "应用「%s」的订单 %d 支付回调重试耗尽仍未成功，请检查通知地址后在集市中心重新发送"
+)
+
+const (
+	// NotifyRetryBatchLimit 单次批量重发回调的最大订单数
+	NotifyRetryBatchLimit = 1000
+	// NotifyRetryTaskIDFormat 批量重发回调任务 ID 格式，用于按订单去重
+	NotifyRetryTaskIDFormat = "payment:notify_retry:%d"
 )
@@ -27,4 +27,35 @@ const (
 	CannotTransferToSelf     = "不能转账给自己"
 	PayConfigNotFound        = "支付配置不存在"
 	SystemConfigValueInvalid = "系统配置 %s 的值无法转换为整数: %v"
+	RefundAmountExceeded     = "退款金额超过订单可退金额"
+	RefundNoConflict         = "退款单号已被使用"
+	RefundNoRequired         = "部分退款须指定退款单号 out_refund_no"
+	OrderNotClosable         = "仅待支付订单可关闭"
+	OrderClosed              = "订单已被商户关闭"
+	OrderNotRefundable       = "订单状态不允许退款"
+	OrderNotNotifiable       = "订单未完成支付，无法发送回调"
+	MerchantOrderNoConflict  = "商户订单号已存在且订单信息不一致"
+	MerchantOrderNoUsed      = "商户订单号已被使用"
+	TradeNoRequired          = "trade_no 与 out_trade_no 不能同时为空"
+	UnsupportedAct           = "不支持的操作类型"
+	UnsupportedPayType       = "不支持的请求类型"
+	SignatureInvalid         = "签名验证失败"
+	LDPayBodyInvalid         = "请求体格式错误，所有字段须为字符串"
+	LDPayMoneyInvalid        = "金额格式错误"
+	LDPayTimestampInvalid    = "请求时间戳无效或已过期"
+	LDPayNonceReused         = "请求 nonce 已被使用"
+)
+
+// 商户 API v2 错误码
+const (
+	ErrCodeInvalidRequest       = "invalid_request"
+	ErrCodeUnauthorized         = "unauthorized"
+	ErrCodeMerchantUnavailable  = "merchant_unavailable"
+	ErrCodeOrderNotFound        = "order_not_found"
+	ErrCodeOrderNotClosable     = "order_not_closable"
+	ErrCodeOrderNotRefundable   = "order_not_refundable"
+	ErrCodeRefundAmountExceeded = "refund_amount_exceeded"
+	ErrCodeRefundNoConflict     = "refund_no_conflict"
+	ErrCodeMerchantOrderNoUsed  = "merchant_order_no_used"
+	ErrCodeInternalError        = "internal_error"
 )
@@ -0,0 +1,436 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package payment
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linux-do/credit/internal/common"
+	"github.com/linux-do/credit/internal/db"
+	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/util"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// MerchantAPIErrorResponse 商户 API v2 错误响应
+type MerchantAPIErrorResponse struct {
+	ErrorCode string      `json:"error_code" example:"order_not_found"`
+	ErrorMsg  string      `json:"error_msg" example:"订单不存在或已完成"`
+	Data      interface{} `json:"data"`
+}
+
+// MerchantAPIOrder 商户 API v2 订单信息
+type MerchantAPIOrder struct {
+	TradeNo         string            `json:"trade_no"`
+	MerchantOrderNo string            `json:"merchant_order_no"`
+	OrderName       string            `json:"order_name"`
+	Amount          string            `json:"amount"`
+	RefundedAmount  string            `json:"refunded_amount"`
+	Status          model.OrderStatus `json:"status"`
+	Remark          string            `json:"remark"`
+	NotifyURL       string            `json:"notify_url"`
+	ReturnURL       string            `json:"return_url"`
+	PayURL          string            `json:"pay_url,omitempty"`
+	TradeTime       *time.Time        `json:"trade_time"`
+	ExpiresAt       time.Time         `json:"expires_at"`
+	CreatedAt       time.Time         `json:"created_at"`
+}
+
+// MerchantAPIRefund 商户 API v2 退款信息
+type MerchantAPIRefund struct {
+	RefundNo  string    `json:"refund_no"`
+	TradeNo   string    `json:"trade_no"`
+	Amount    string    `json:"amount"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// MerchantAPIListOrdersRequest 商户 API v2 订单列表请求
+type MerchantAPIListOrdersRequest struct {
+	Page      int        `form:"page" binding:"omitempty,min=1"`
+	PageSize  int        `form:"page_size" binding:"omitempty,min=1,max=100"`
+	Status    string     `form:"status" binding:"omitempty,oneof=success pending expired disputing refund refused partially_refunded closed"`
+	StartTime *time.Time `form:"start_time" binding:"omitempty"`
+	EndTime   *time.Time `form:"end_time" binding:"omitempty,gtfield=StartTime"`
+}
+
+// MerchantAPIListOrdersResponse 商户 API v2 订单列表响应
+type MerchantAPIListOrdersResponse struct {
+	Total    int64              `json:"total"`
+	Page     int                `json:"page"`
+	PageSize int                `json:"page_size"`
+	Orders   []MerchantAPIOrder `json:"orders"`
+}
+
+// MerchantAPIRefundRequest 商户 API v2 退款请求
+type MerchantAPIRefundRequest struct {
+	Amount   decimal.Decimal `json:"amount" binding:"required"`
+	RefundNo string          `json:"refund_no" binding:"required,max=64"`
+}
+
+// MerchantAPIBalanceResponse 商户 API v2 余额响应
+type MerchantAPIBalanceResponse struct {
+	AvailableBalance string `json:"available_balance"`
+	TotalReceive     string `json:"total_receive"`
+	PayScore         int64  `json:"pay_score"`
+}
+
+// newMerchantAPIOrder 转换为商户 API v2 订单信息
+func newMerchantAPIOrder(order *model.Order) MerchantAPIOrder {
+	result := MerchantAPIOrder{
+		TradeNo:         strconv.FormatUint(order.ID, 10),
+		MerchantOrderNo: order.MerchantOrderNo,
+		OrderName:       order.OrderName,
+		Amount:          order.Amount.StringFixed(2),
+		RefundedAmount:  order.RefundedAmount.StringFixed(2),
+		Status:          order.Status,
+		Remark:          order.Remark,
+		NotifyURL:       order.NotifyURL,
+		ReturnURL:       order.ReturnURL,
+		ExpiresAt:       order.ExpiresAt,
+		CreatedAt:       order.CreatedAt,
+	}
+	if !order.TradeTime.IsZero() {
+		result.TradeTime = &order.TradeTime
+	}
+	return result
+}
+
+// newMerchantAPIRefund 转换为商户 API v2 退款信息
+func newMerchantAPIRefund(refund *model.OrderRefund) MerchantAPIRefund {
+	return MerchantAPIRefund{
+		RefundNo:  refund.MerchantRefundNo,
+		TradeNo:   strconv.FormatUint(refund.OrderID, 10),
+		Amount:    refund.Amount.StringFixed(2),
+		CreatedAt: refund.CreatedAt,
+	}
+}
+
+// abortWithMerchantAPIError 返回商户 API v2 错误响应
+func abortWithMerchantAPIError(c *gin.Context, status int, code string, msg string) {
+	c.AbortWithStatusJSON(status, MerchantAPIErrorResponse{ErrorCode: code, ErrorMsg: msg})
+}
+
+// handleMerchantAPIError 将业务错误映射为商户 API v2 的 HTTP 状态码与错误码
+func handleMerchantAPIError(c *gin.Context, err error) {
+	errMsg := err.Error()
+	switch errMsg {
+	case common.AmountMustBeGreaterThanZero, common.AmountDecimalPlacesExceeded, RefundNoRequired:
+		abortWithMerchantAPIError(c, http.StatusBadRequest, ErrCodeInvalidRequest, errMsg)
+	case util.WebhookURLInvalid, util.WebhookHostDenied, util.WebhookAddressDenied, util.WebhookHostResolveFailed:
+		abortWithMerchantAPIError(c, http.StatusBadRequest, ErrCodeInvalidRequest, errMsg)
+	case MerchantInfoNotFound:
+		abortWithMerchantAPIError(c, http.StatusForbidden, ErrCodeMerchantUnavailable, errMsg)
+	case OrderNotFound:
+		abortWithMerchantAPIError(c, http.StatusNotFound, ErrCodeOrderNotFound, errMsg)
+	case OrderNotClosable:
+		abortWithMerchantAPIError(c, http.StatusConflict, ErrCodeOrderNotClosable, errMsg)
+	case OrderNotRefundable:
+		abortWithMerchantAPIError(c, http.StatusConflict, ErrCodeOrderNotRefundable, errMsg)
+	case RefundAmountExceeded:
+		abortWithMerchantAPIError(c, http.StatusConflict, ErrCodeRefundAmountExceeded, errMsg)
+	case RefundNoConflict:
+		abortWithMerchantAPIError(c, http.StatusConflict, ErrCodeRefundNoConflict, errMsg)
+	case MerchantOrderNoConflict, MerchantOrderNoUsed:
+		abortWithMerchantAPIError(c, http.StatusConflict, ErrCodeMerchantOrderNoUsed, errMsg)
+	default:
+		abortWithMerchantAPIError(c, http.StatusInternalServerError, ErrCodeInternalError, errMsg)
+	}
+}
+
+// validateMerchantAPIAmount 验证金额大于0且小数位数不超过2位
+func validateMerchantAPIAmount(amount decimal.Decimal) error {
+	if amount.LessThanOrEqual(decimal.Zero) {
+		return errors.New(common.AmountMustBeGreaterThanZero)
+	}
+	if amount.Exponent() < -2 {
+		return errors.New(common.AmountDecimalPlacesExceeded)
+	}
+	return nil
+}
+
+// parseTradeNoParam 解析路径中的平台订单号
+func parseTradeNoParam(c *gin.Context) (uint64, bool) {
+	tradeNo, err := strconv.ParseUint(c.Param("trade_no"), 10, 64)
+	if err != nil {
+		abortWithMerchantAPIError(c, http.StatusBadRequest, ErrCodeInvalidRequest, OrderNoFormatError)
+		return 0, false
+	}
+	return tradeNo, true
+}
+
+// MerchantAPICreateOrder 商户 API v2 创建订单
+// @Tags merchant-api
+// @Accept json
+// @Produce json
+// @Param request body CreateOrderRequest true "request body"
+// @Success 201 {object} util.ResponseAny
+// @Failure 400 {object} MerchantAPIErrorResponse
+// @Router /api/v2/merchant/orders [post]
+func MerchantAPICreateOrder(c *gin.Context) {
+	var req CreateOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithMerchantAPIError(c, http.StatusBadRequest, ErrCodeInvalidRequest, err.Error())
+		return
+	}
+
+	if err := validateMerchantAPIAmount(req.Amount); err != nil {
+		handleMerchantAPIError(c, err)
+		return
+	}
+
+	// v2 接口创建的订单使用 LDPay 协议回调
+	req.PaymentType = common.PayTypeLDPay
+
+	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, APIKeyObjKey)
+
+	order, payURL, err := createMerchantOrder(c, &req, apiKey)
+	if err != nil {
+		handleMerchantAPIError(c, err)
+		return
+	}
+
+	result := newMerchantAPIOrder(order)
+	result.PayURL = payURL
+	c.JSON(http.StatusCreated, util.OK(result))
+}
+
+// MerchantAPIGetOrder 商户 API v2 查询订单
+// @Tags merchant-api
+// @Produce json
+// @Param trade_no path string true "平台订单号"
+// @Success 200 {object} util.ResponseAny
+// @Failure 404 {object} MerchantAPIErrorResponse
+// @Router /api/v2/merchant/orders/{trade_no} [get]
+func MerchantAPIGetOrder(c *gin.Context) {
+	tradeNo, ok := parseTradeNoParam(c)
+	if !ok {
+		return
+	}
+
+	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, APIKeyObjKey)
+
+	var order model.Order
+	if err := db.DB(c.Request.Context()).
+		Where("id = ? AND client_id = ?", tradeNo, apiKey.ClientID).
+		First(&order).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = errors.New(OrderNotFound)
+		}
+		handleMerchantAPIError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OK(newMerchantAPIOrder(&order)))
+}
+
+// MerchantAPIListOrders 商户 API v2 订单列表
+// @Tags merchant-api
+// @Produce json
+// @Param request query MerchantAPIListOrdersRequest false "查询参数"
+// @Success 200 {object} util.ResponseAny
+// @Failure 400 {object} MerchantAPIErrorResponse
+// @Router /api/v2/merchant/orders [get]
+func MerchantAPIListOrders(c *gin.Context) {
+	var req MerchantAPIListOrdersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		abortWithMerchantAPIError(c, http.StatusBadRequest, ErrCodeInvalidRequest, err.Error())
+		return
+	}
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.PageSize == 0 {
+		req.PageSize = 20
+	}
+
+	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, APIKeyObjKey)
+
+	query := db.DB(c.Request.Context()).Model(&model.Order{}).Where("client_id = ?", apiKey.ClientID)
+	if req.Status != "" {
+		query = query.Where("status = ?", model.OrderStatus(req.Status))
+	}
+	if req.StartTime != nil {
+		query = query.Where("created_at >= ?", *req.StartTime)
+	}
+	if req.EndTime != nil {
+		query = query.Where("created_at <= ?", *req.EndTime)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		handleMerchantAPIError(c, err)
+		return
+	}
+
+	var orders []model.Order
+	if err := query.Order("created_at DESC").
+		Offset((req.Page - 1) * req.PageSize).
+		Limit(req.PageSize).
+		Find(&orders).Error; err != nil {
+		handleMerchantAPIError(c, err)
+		return
+	}
+
+	results := make([]MerchantAPIOrder, 0, len(orders))
+	for i := range orders {
+		results = append(results, newMerchantAPIOrder(&orders[i]))
+	}
+
+	c.JSON(http.StatusOK, util.OK(MerchantAPIListOrdersResponse{
+		Total:    total,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+		Orders:   results,
+	}))
+}
+
+// MerchantAPICloseOrder 商户 API v2 关闭订单
+// @Tags merchant-api
+// @Produce json
+// @Param trade_no path string true "平台订单号"
+// @Success 200 {object} util.ResponseAny
+// @Failure 409 {object} MerchantAPIErrorResponse
+// @Router /api/v2/merchant/orders/{trade_no}/close [post]
+func MerchantAPICloseOrder(c *gin.Context) {
+	tradeNo, ok := parseTradeNoParam(c)
+	if !ok {
+		return
+	}
+
+	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, APIKeyObjKey)
+
+	order, err := closeMerchantOrder(c.Request.Context(), apiKey.ClientID, tradeNo)
+	if err != nil {
+		handleMerchantAPIError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OK(newMerchantAPIOrder(order)))
+}
+
+// MerchantAPICreateRefund 商户 API v2 发起退款
+// @Tags merchant-api
+// @Accept json
+// @Produce json
+// @Param trade_no path string true "平台订单号"
+// @Param request body MerchantAPIRefundRequest true "request body"
+// @Success 201 {object} util.ResponseAny
+// @Failure 409 {object} MerchantAPIErrorResponse
+// @Router /api/v2/merchant/orders/{trade_no}/refunds [post]
+func MerchantAPICreateRefund(c *gin.Context) {
+	tradeNo, ok := parseTradeNoParam(c)
+	if !ok {
+		return
+	}
+
+	var req MerchantAPIRefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithMerchantAPIError(c, http.StatusBadRequest, ErrCodeInvalidRequest, err.Error())
+		return
+	}
+
+	if err := validateMerchantAPIAmount(req.Amount); err != nil {
+		handleMerchantAPIError(c, err)
+		return
+	}
+
+	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, APIKeyObjKey)
+
+	_, refund, err := refundMerchantOrder(c.Request.Context(), apiKey, tradeNo, req.Amount, req.RefundNo)
+	if err != nil {
+		handleMerchantAPIError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, util.OK(newMerchantAPIRefund(refund)))
+}
+
+// MerchantAPIListRefunds 商户 API v2 查询订单退款记录
+// @Tags merchant-api
+// @Produce json
+// @Param trade_no path string true "平台订单号"
+// @Success 200 {object} util.ResponseAny
+// @Failure 404 {object} MerchantAPIErrorResponse
+// @Router /api/v2/merchant/orders/{trade_no}/refunds [get]
+func MerchantAPIListRefunds(c *gin.Context) {
+	tradeNo, ok := parseTradeNoParam(c)
+	if !ok {
+		return
+	}
+
+	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, APIKeyObjKey)
+
+	var count int64
+	if err := db.DB(c.Request.Context()).Model(&model.Order{}).
+		Where("id = ? AND client_id = ?", tradeNo, apiKey.ClientID).
+		Count(&count).Error; err != nil {
+		handleMerchantAPIError(c, err)
+		return
+	}
+	if count == 0 {
+		handleMerchantAPIError(c, errors.New(OrderNotFound))
+		return
+	}
+
+	var refunds []model.OrderRefund
+	if err := db.DB(c.Request.Context()).
+		Where("order_id = ?", tradeNo).
+		Order("created_at ASC").
+		Find(&refunds).Error; err != nil {
+		handleMerchantAPIError(c, err)
+		return
+	}
+
+	results := make([]MerchantAPIRefund, 0, len(refunds))
+	for i := range refunds {
+		results = append(results, newMerchantAPIRefund(&refunds[i]))
+	}
+
+	c.JSON(http.StatusOK, util.OK(results))
+}
+
+// MerchantAPIGetBalance 商户 API v2 查询余额
+// @Tags merchant-api
+// @Produce json
+// @Success 200 {object} util.ResponseAny
+// @Failure 403 {object} MerchantAPIErrorResponse
+// @Router /api/v2/merchant/balance [get]
+func MerchantAPIGetBalance(c *gin.Context) {
+	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, APIKeyObjKey)
+
+	var merchantUser model.User
+	if err := db.DB(c.Request.Context()).
+		Where("id = ? AND is_active = ?", apiKey.UserID, true).
+		First(&merchantUser).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = errors.New(MerchantInfoNotFound)
+		}
+		handleMerchantAPIError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OK(MerchantAPIBalanceResponse{
+		AvailableBalance: merchantUser.AvailableBalance.StringFixed(2),
+		TotalReceive:     merchantUser.TotalReceive.StringFixed(2),
+		PayScore:         merchantUser.PayScore,
+	}))
+}
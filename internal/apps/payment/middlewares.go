@@ -22,6 +22,7 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/linux-do/credit/internal/common"
 	"github.com/linux-do/credit/internal/db"
 	"github.com/linux-do/credit/internal/model"
@@ -36,6 +37,8 @@ type CreateOrderRequest struct {
 	Amount          decimal.Decimal `json:"amount" binding:"required"`
 	Remark          string          `json:"remark" binding:"max=100"`
 	PaymentType     string          `json:"payment_type"`
+	NotifyURL       string          `json:"notify_url" binding:"omitempty,max=255,url"`
+	ReturnURL       string          `json:"return_url" binding:"omitempty,max=255,url"`
 }
 
 // EPayRequest 易支付请求
@@ -44,8 +47,8 @@ type EPayRequest struct {
 	OrderName       string          `form:"name" binding:"required,max=64"`
 	MerchantOrderNo string          `form:"out_trade_no" binding:"required"`
 	Amount          decimal.Decimal `form:"money" binding:"required"`
-	NotifyURL       string          `form:"notify_url"`
-	ReturnURL       string          `form:"return_url"`
+	NotifyURL       string          `form:"notify_url" binding:"omitempty,max=255,url"`
+	ReturnURL       string          `form:"return_url" binding:"omitempty,max=255,url"`
 	Device          string          `form:"device"`
 	Sign            string          `form:"sign" binding:"required"`
 	PayType         string          `form:"type" binding:"required"`
@@ -59,37 +62,54 @@ func (r *EPayRequest) ToCreateOrderRequest() *CreateOrderRequest {
 		MerchantOrderNo: r.MerchantOrderNo,
 		Amount:          r.Amount,
 		PaymentType:     r.PayType,
+		NotifyURL:       r.NotifyURL,
+		ReturnURL:       r.ReturnURL,
 	}
 }
 
+// LDPayRequest LDPay 原生协议请求，请求体为 JSON 且所有字段均为字符串
+type LDPayRequest struct {
+	ClientID        string `json:"pid" binding:"required"`
+	PayType         string `json:"type" binding:"required"`
+	OrderName       string `json:"name" binding:"required,max=64"`
+	MerchantOrderNo string `json:"out_trade_no" binding:"required,max=64"`
+	Money           string `json:"money" binding:"required"`
+	NotifyURL       string `json:"notify_url" binding:"omitempty,max=255,url"`
+	ReturnURL       string `json:"return_url" binding:"omitempty,max=255,url"`
+	Remark          string `json:"remark" binding:"max=100"`
+	Timestamp       string `json:"timestamp" binding:"required"`
+	Nonce           string `json:"nonce" binding:"required,min=8,max=64"`
+	Sign            string `json:"sign" binding:"required"`
+}
+
 // RequireMerchantAuth 验证商户 ClientID/ClientSecret（Basic Auth）
 func RequireMerchantAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Authorization: Basic base64(ClientID:ClientSecret)
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, util.Err("缺少认证信息"))
+			abortWithMerchantAPIError(c, http.StatusUnauthorized, ErrCodeUnauthorized, "缺少认证信息")
 			return
 		}
 
 		// 解析 Basic Auth
 		parts := strings.SplitN(authHeader, " ", 2)
 		if len(parts) != 2 || parts[0] != "Basic" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, util.Err("认证格式错误"))
+			abortWithMerchantAPIError(c, http.StatusUnauthorized, ErrCodeUnauthorized, "认证格式错误")
 			return
 		}
 
 		// 解码 base64
 		decoded, err := base64.StdEncoding.DecodeString(parts[1])
 		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, util.Err("认证信息解码失败"))
+			abortWithMerchantAPIError(c, http.StatusUnauthorized, ErrCodeUnauthorized, "认证信息解码失败")
 			return
 		}
 
 		// 解析 ClientID:ClientSecret
 		credentials := strings.SplitN(string(decoded), ":", 2)
 		if len(credentials) != 2 {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, util.Err("认证信息格式错误"))
+			abortWithMerchantAPIError(c, http.StatusUnauthorized, ErrCodeUnauthorized, "认证信息格式错误")
 			return
 		}
 
@@ -100,7 +120,7 @@ func RequireMerchantAuth() gin.HandlerFunc {
 		if err := db.DB(c.Request.Context()).
 			Where("client_secret = ? AND client_id = ?", clientSecret, clientID).
 			First(&apiKey).Error; err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, util.Err("认证失败"))
+			abortWithMerchantAPIError(c, http.StatusUnauthorized, ErrCodeUnauthorized, "认证失败")
 			return
 		}
 
@@ -115,6 +135,11 @@ func RequireSignatureAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		PayType := c.PostForm("type")
 
+		// LDPay 协议使用 JSON 请求体
+		if c.ContentType() == binding.MIMEJSON {
+			PayType = common.PayTypeLDPay
+		}
+
 		var apiKey model.MerchantAPIKey
 
 		switch PayType {
@@ -125,8 +150,15 @@ func RequireSignatureAuth() gin.HandlerFunc {
 			} else {
 				util.SetToContext(c, CreateOrderRequestKey, createOrderReq)
 			}
+		case common.PayTypeLDPay:
+			if createOrderReq, err := VerifyLDPaySignature(c, &apiKey); err != nil {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, util.Err(err.Error()))
+				return
+			} else {
+				util.SetToContext(c, CreateOrderRequestKey, createOrderReq)
+			}
 		default:
-			c.AbortWithStatusJSON(http.StatusBadRequest, util.Err("不支持的请求类型"))
+			c.AbortWithStatusJSON(http.StatusBadRequest, util.Err(UnsupportedPayType))
 			return
 		}
 
@@ -17,7 +17,7 @@ limitations under the License.
 package payment
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
 	"log"
@@ -26,18 +26,16 @@ import (
 	"strconv"
 	"time"
 
-	"github.com/hibiken/asynq"
 	"github.com/linux-do/credit/internal/apps/oauth"
 	"github.com/linux-do/credit/internal/common"
 	"github.com/linux-do/credit/internal/config"
 	"github.com/linux-do/credit/internal/service"
-	"github.com/linux-do/credit/internal/task"
-    "github.com/linux-do/credit/internal/task/scheduler"
 
 	"github.com/gin-gonic/gin"
 	"github.com/linux-do/credit/internal/db"
 	"github.com/linux-do/credit/internal/model"
 	"github.com/linux-do/credit/internal/util"
+	"github.com/redis/go-redis/v9"
 	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -45,8 +43,14 @@ import (
 
 // PayOrderRequest 用户支付订单请求
 type PayOrderRequest struct {
-	OrderNo string `json:"order_no" binding:"required"`
-	PayKey  string `json:"pay_key" binding:"required,max=6"`
+	OrderNo  string `json:"order_no" binding:"required"`
+	PayKey   string `json:"pay_key" binding:"required,max=6"`
+	TOTPCode string `json:"totp_code" binding:"max=16"`
+}
+
+// PayOrderResponse 用户支付订单响应
+type PayOrderResponse struct {
+	ReturnURL string `json:"return_url"`
 }
 
 // GetOrderRequest 查询订单请求
@@ -73,6 +77,7 @@ type TransferRequest struct {
 	RecipientUsername string          `json:"recipient_username" binding:"required"`
 	Amount            decimal.Decimal `json:"amount" binding:"required"`
 	PayKey            string          `json:"pay_key" binding:"required,max=6"`
+	TOTPCode          string          `json:"totp_code" binding:"max=16"`
 	Remark            string          `json:"remark" binding:"max=100"`
 }
 
@@ -82,7 +87,7 @@ type QueryOrderRequest struct {
 	ClientID        string `form:"pid" json:"pid" binding:"required"`
 	ClientSecret    string `form:"key" json:"key" binding:"required"`
 	MerchantOrderNo string `form:"out_trade_no" json:"out_trade_no"`
-	TradeNo         uint64 `form:"trade_no" json:"trade_no" binding:"required"`
+	TradeNo         uint64 `form:"trade_no" json:"trade_no"`
 }
 
 // RefundOrderRequest 商户退款请求
@@ -92,6 +97,7 @@ type RefundOrderRequest struct {
 	MerchantOrderNo string          `form:"out_trade_no" json:"out_trade_no"`
 	TradeNo         uint64          `form:"trade_no" json:"trade_no" binding:"required"`
 	Amount          decimal.Decimal `form:"money" json:"money" binding:"required"`
+	RefundNo        string          `form:"out_refund_no" json:"out_refund_no" binding:"max=64"`
 }
 
 // CreateMerchantOrder 商户创建订单接口
@@ -105,26 +111,117 @@ func CreateMerchantOrder(c *gin.Context) {
 	req, _ := util.GetFromContext[*CreateOrderRequest](c, CreateOrderRequestKey)
 	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, APIKeyObjKey)
 
+	_, payURL, err := createMerchantOrder(c, req, apiKey)
+	if err != nil {
+		if isCreateOrderRequestError(err) {
+			c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	c.Redirect(http.StatusFound, payURL)
+}
+
+// CreateMerchantOrderAPIResponse 商户 API 创建订单响应
+type CreateMerchantOrderAPIResponse struct {
+	Code       int    `json:"code" example:"1"`
+	Msg        string `json:"msg" example:"创建订单成功"`
+	TradeNo    string `json:"trade_no" example:"123456"`
+	OutTradeNo string `json:"out_trade_no" example:"M202312080001"`
+	PayURL     string `json:"payurl" example:"https://credit.linux.do/paying?order_no=xxx"`
+	QRCode     string `json:"qrcode" example:"https://credit.linux.do/paying?order_no=xxx"`
+	Money      string `json:"money" example:"10.00"`
+	ExpireTime string `json:"expire_time" example:"2023-12-08 12:05:00"`
+}
+
+// CreateMerchantOrderAPI 商户 API 创建订单接口（服务端调用，返回 JSON）
+// @Tags payment
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param request body EPayRequest true "request body"
+// @Success 200 {object} CreateMerchantOrderAPIResponse
+// @Router /mapi.php [post]
+func CreateMerchantOrderAPI(c *gin.Context) {
+	req, _ := util.GetFromContext[*CreateOrderRequest](c, CreateOrderRequestKey)
+	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, APIKeyObjKey)
+
+	order, payURL, err := createMerchantOrder(c, req, apiKey)
+	if err != nil {
+		if isCreateOrderRequestError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"code": -1, "msg": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"code": -1, "msg": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, CreateMerchantOrderAPIResponse{
+		Code:       1,
+		Msg:        "创建订单成功",
+		TradeNo:    strconv.FormatUint(order.ID, 10),
+		OutTradeNo: order.MerchantOrderNo,
+		PayURL:     payURL,
+		QRCode:     payURL,
+		Money:      order.Amount.Truncate(2).StringFixed(2),
+		ExpireTime: order.ExpiresAt.Format("2006-01-02 15:04:05"),
+	})
+}
+
+// isCreateOrderRequestError 判断创建订单失败是否由请求参数导致
+func isCreateOrderRequestError(err error) bool {
+	switch err.Error() {
+	case MerchantOrderNoConflict, MerchantOrderNoUsed,
+		util.WebhookURLInvalid, util.WebhookHostDenied, util.WebhookAddressDenied, util.WebhookHostResolveFailed:
+		return true
+	default:
+		return false
+	}
+}
+
+// createMerchantOrder 创建待支付的商户订单，并写入收银台所需的 Redis 缓存
+// 返回：订单、收银台支付链接
+func createMerchantOrder(c *gin.Context, req *CreateOrderRequest, apiKey *model.MerchantAPIKey) (*model.Order, string, error) {
+	// 订单级回调地址与 API Key 回调地址同样校验，防止借助订单回调访问受限地址
+	if req.NotifyURL != "" {
+		if err := util.ValidateWebhookURL(c.Request.Context(), req.NotifyURL); err != nil {
+			return nil, "", err
+		}
+	}
+
 	// 获取商户用户信息
 	var merchantUser model.User
 	if err := db.DB(c.Request.Context()).Where("id = ? AND is_active = ?", apiKey.UserID, true).First(&merchantUser).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, util.Err(MerchantInfoNotFound))
-		return
+		return nil, "", errors.New(MerchantInfoNotFound)
 	}
 
 	// 获取商家订单过期时间（分钟）
 	expireMinutes, errGet := model.GetIntByKey(c.Request.Context(), model.ConfigKeyMerchantOrderExpireMinutes)
 	if errGet != nil {
-		c.JSON(http.StatusInternalServerError, util.Err(errGet.Error()))
-		return
+		return nil, "", errGet
 	}
 
+	var order model.Order
 	var payURL string
 
 	if err := db.DB(c.Request.Context()).Transaction(
 		func(tx *gorm.DB) error {
+			// 商户订单号幂等：相同订单号的重试返回已有的待支付订单
+			if req.MerchantOrderNo != "" {
+				existing, existingPayURL, err := findExistingMerchantOrder(c.Request.Context(), tx, apiKey.ClientID, req)
+				if err != nil {
+					return err
+				}
+				if existing != nil {
+					order = *existing
+					payURL = existingPayURL
+					return nil
+				}
+			}
+
 			// 创建订单
-			order := model.Order{
+			order = model.Order{
 				OrderName:       req.OrderName,
 				ClientID:        apiKey.ClientID,
 				MerchantOrderNo: req.MerchantOrderNo,
@@ -134,6 +231,8 @@ func CreateMerchantOrder(c *gin.Context) {
 				Type:            model.OrderTypePayment,
 				Remark:          req.Remark,
 				PaymentType:     req.PaymentType,
+				NotifyURL:       req.NotifyURL,
+				ReturnURL:       req.ReturnURL,
 				ExpiresAt:       time.Now().Add(time.Duration(expireMinutes) * time.Minute),
 			}
 			if err := tx.Create(&order).Error; err != nil {
@@ -151,7 +250,7 @@ func CreateMerchantOrder(c *gin.Context) {
 			}
 
 			expireKey := db.PrefixedKey(fmt.Sprintf(OrderExpireKeyFormat, order.ID))
-			if errSet := db.Redis.Set(c.Request.Context(), expireKey, order.ID, time.Duration(expireMinutes)*time.Minute).Err(); errSet != nil {
+			if errSet := db.Redis.Set(c.Request.Context(), expireKey, encryptString, time.Duration(expireMinutes)*time.Minute).Err(); errSet != nil {
 				return fmt.Errorf("failed to set order expire key: %w", errSet)
 			}
 
@@ -159,17 +258,52 @@ func CreateMerchantOrder(c *gin.Context) {
 			return nil
 		},
 	); err != nil {
-		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
-		return
+		return nil, "", err
 	}
 
-	c.Redirect(http.StatusFound, payURL)
+	return &order, payURL, nil
 }
 
-// QueryMerchantOrderResponse 查询订单响应
-type QueryMerchantOrderResponse struct {
-	Code       int    `json:"code" example:"1"`
-	Msg        string `json:"msg" example:"查询订单号成功！"`
+// findExistingMerchantOrder 在事务内按 (client_id, merchant_order_no) 查找已有订单
+// 返回：可复用的待支付订单及其支付链接，不存在时返回 nil；订单信息不一致或已不可支付时返回错误
+func findExistingMerchantOrder(ctx context.Context, tx *gorm.DB, clientID string, req *CreateOrderRequest) (*model.Order, string, error) {
+	// 以商户订单号加事务级咨询锁，串行化同一订单号的并发创建
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", clientID+":"+req.MerchantOrderNo).Error; err != nil {
+		return nil, "", err
+	}
+
+	var existing model.Order
+	if err := tx.Where("client_id = ? AND merchant_order_no = ?", clientID, req.MerchantOrderNo).
+		Order("created_at DESC").
+		First(&existing).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", nil
+		}
+		return nil, "", err
+	}
+
+	if !existing.Amount.Equal(req.Amount) || existing.OrderName != req.OrderName {
+		return nil, "", errors.New(MerchantOrderNoConflict)
+	}
+
+	if existing.Status != model.OrderStatusPending || existing.ExpiresAt.Before(time.Now()) {
+		return nil, "", errors.New(MerchantOrderNoUsed)
+	}
+
+	// 过期 key 的值为加密后的订单号，据此还原支付链接
+	encryptString, err := db.Redis.Get(ctx, db.PrefixedKey(fmt.Sprintf(OrderExpireKeyFormat, existing.ID))).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, "", errors.New(MerchantOrderNoUsed)
+		}
+		return nil, "", err
+	}
+
+	return &existing, fmt.Sprintf("%s?order_no=%s", config.Config.App.FrontendPayURL, url.QueryEscape(encryptString)), nil
+}
+
+// EPayOrderInfo 易支付订单信息
+type EPayOrderInfo struct {
 	TradeNo    string `json:"trade_no" example:"123456"`
 	OutTradeNo string `json:"out_trade_no" example:"M202312080001"`
 	Type       string `json:"type" example:"epay"`
@@ -181,13 +315,78 @@ type QueryMerchantOrderResponse struct {
 	Status     int    `json:"status" example:"1"`
 }
 
-// QueryMerchantOrder 商户主动查询订单状态接口
+// newEPayOrderInfo 转换为易支付订单信息
+func newEPayOrderInfo(order *model.Order) EPayOrderInfo {
+	statusInt := 0
+	if order.Status == model.OrderStatusSuccess || order.Status == model.OrderStatusPartiallyRefunded {
+		statusInt = 1
+	}
+
+	return EPayOrderInfo{
+		TradeNo:    strconv.FormatUint(order.ID, 10),
+		OutTradeNo: order.MerchantOrderNo,
+		Type:       order.PaymentType,
+		Pid:        order.ClientID,
+		AddTime:    order.CreatedAt.Format("2006-01-02 15:04:05"),
+		EndTime:    order.TradeTime.Format("2006-01-02 15:04:05"),
+		Name:       order.OrderName,
+		Money:      order.Amount.Truncate(2).StringFixed(2),
+		Status:     statusInt,
+	}
+}
+
+// QueryMerchantOrderResponse 查询订单响应
+type QueryMerchantOrderResponse struct {
+	Code int    `json:"code" example:"1"`
+	Msg  string `json:"msg" example:"查询订单号成功！"`
+	EPayOrderInfo
+}
+
+// ListMerchantOrdersRequest 商户批量查询订单请求
+type ListMerchantOrdersRequest struct {
+	ClientID     string    `form:"pid" json:"pid" binding:"required"`
+	ClientSecret string    `form:"key" json:"key" binding:"required"`
+	Page         int       `form:"page" json:"page" binding:"omitempty,min=1"`
+	Limit        int       `form:"limit" json:"limit" binding:"omitempty,min=1,max=100"`
+	Status       string    `form:"status" json:"status" binding:"omitempty,oneof=success pending expired disputing refund refused partially_refunded closed"`
+	StartTime    time.Time `form:"start_time" json:"start_time" time_format:"2006-01-02 15:04:05"`
+	EndTime      time.Time `form:"end_time" json:"end_time" time_format:"2006-01-02 15:04:05"`
+}
+
+// ListMerchantOrdersResponse 商户批量查询订单响应
+type ListMerchantOrdersResponse struct {
+	Code  int             `json:"code" example:"1"`
+	Msg   string          `json:"msg" example:"查询订单成功"`
+	Total int64           `json:"total" example:"1"`
+	Page  int             `json:"page" example:"1"`
+	Limit int             `json:"limit" example:"20"`
+	Data  []EPayOrderInfo `json:"data"`
+}
+
+// EPayQuery 易支付商户查询接口，按 act 分发
 // @Tags payment
 // @Accept json
 // @Produce json
+// @Param act query string false "操作类型：order（默认）、orders、query、settle"
 // @Param request query QueryOrderRequest true "查询参数"
 // @Success 200 {object} QueryMerchantOrderResponse
 // @Router /api.php [get]
+func EPayQuery(c *gin.Context) {
+	switch c.Query("act") {
+	case "", "order":
+		QueryMerchantOrder(c)
+	case "orders":
+		ListMerchantOrders(c)
+	case "query":
+		QueryMerchantAccount(c)
+	case "settle":
+		QueryMerchantSettlement(c)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"code": -1, "msg": UnsupportedAct})
+	}
+}
+
+// QueryMerchantOrder 商户主动查询订单状态接口，支持按平台订单号或商户订单号查询
 func QueryMerchantOrder(c *gin.Context) {
 	var req QueryOrderRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
@@ -195,14 +394,26 @@ func QueryMerchantOrder(c *gin.Context) {
 		return
 	}
 
+	if req.TradeNo == 0 && req.MerchantOrderNo == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": -1, "msg": TradeNoRequired})
+		return
+	}
+
 	var apiKey model.MerchantAPIKey
 	if err := db.DB(c.Request.Context()).Where("client_id = ? AND client_secret = ?", req.ClientID, req.ClientSecret).First(&apiKey).Error; err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"code": -1, "msg": MerchantInfoNotFound})
 		return
 	}
 
+	query := db.DB(c.Request.Context()).Where("client_id = ?", req.ClientID)
+	if req.TradeNo != 0 {
+		query = query.Where("id = ?", req.TradeNo)
+	} else {
+		query = query.Where("merchant_order_no = ?", req.MerchantOrderNo).Order("created_at DESC")
+	}
+
 	var order model.Order
-	if err := db.DB(c.Request.Context()).Where("id = ? AND client_id = ?", req.TradeNo, req.ClientID).First(&order).Error; err != nil {
+	if err := query.First(&order).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"code": -1, "msg": OrderNotFound})
 			return
@@ -211,39 +422,346 @@ func QueryMerchantOrder(c *gin.Context) {
 		return
 	}
 
-	statusInt := 0
-	if order.Status == model.OrderStatusSuccess {
-		statusInt = 1
+	c.JSON(http.StatusOK, QueryMerchantOrderResponse{
+		Code:          1,
+		Msg:           "查询订单号成功！",
+		EPayOrderInfo: newEPayOrderInfo(&order),
+	})
+}
+
+// ListMerchantOrders 商户批量查询订单接口（act=orders），仅返回当前商户的订单
+func ListMerchantOrders(c *gin.Context) {
+	var req ListMerchantOrdersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": -1, "msg": err.Error()})
+		return
+	}
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.Limit == 0 {
+		req.Limit = 20
+	}
+
+	var apiKey model.MerchantAPIKey
+	if err := db.DB(c.Request.Context()).Where("client_id = ? AND client_secret = ?", req.ClientID, req.ClientSecret).First(&apiKey).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": -1, "msg": MerchantInfoNotFound})
+		return
+	}
+
+	query := db.DB(c.Request.Context()).Model(&model.Order{}).Where("client_id = ?", apiKey.ClientID)
+	if req.Status != "" {
+		query = query.Where("status = ?", model.OrderStatus(req.Status))
+	}
+	if !req.StartTime.IsZero() {
+		query = query.Where("created_at >= ?", req.StartTime)
+	}
+	if !req.EndTime.IsZero() {
+		query = query.Where("created_at <= ?", req.EndTime)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": -1, "msg": err.Error()})
+		return
+	}
+
+	var orders []model.Order
+	if err := query.Order("created_at DESC").
+		Offset((req.Page - 1) * req.Limit).
+		Limit(req.Limit).
+		Find(&orders).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": -1, "msg": err.Error()})
+		return
+	}
+
+	data := make([]EPayOrderInfo, 0, len(orders))
+	for i := range orders {
+		data = append(data, newEPayOrderInfo(&orders[i]))
+	}
+
+	c.JSON(http.StatusOK, ListMerchantOrdersResponse{
+		Code:  1,
+		Msg:   "查询订单成功",
+		Total: total,
+		Page:  req.Page,
+		Limit: req.Limit,
+		Data:  data,
+	})
+}
+
+// MerchantCredentialRequest 商户凭证请求
+type MerchantCredentialRequest struct {
+	ClientID     string `form:"pid" json:"pid" binding:"required"`
+	ClientSecret string `form:"key" json:"key" binding:"required"`
+}
+
+// QueryMerchantAccountResponse 商户信息查询响应
+type QueryMerchantAccountResponse struct {
+	Code         int    `json:"code" example:"1"`
+	Msg          string `json:"msg" example:"查询商户信息成功"`
+	Pid          string `json:"pid" example:"1001"`
+	Active       int    `json:"active" example:"1"`
+	Money        string `json:"money" example:"100.00"`
+	TotalReceive string `json:"total_receive" example:"1000.00"`
+	PayLevel     int    `json:"pay_level" example:"1"`
+	FeeRate      string `json:"fee_rate" example:"0.05"`
+	Orders       int64  `json:"orders" example:"10"`
+	OrderToday   int64  `json:"order_today" example:"2"`
+	OrderLastDay int64  `json:"order_lastday" example:"3"`
+}
+
+// QueryMerchantSettlementRequest 商户结算汇总查询请求
+type QueryMerchantSettlementRequest struct {
+	MerchantCredentialRequest
+	Days int `form:"days" json:"days" binding:"omitempty,min=1,max=90"`
+}
+
+// MerchantSettlementItem 商户单日结算汇总
+type MerchantSettlementItem struct {
+	Date        string `json:"date" example:"2025-01-01"`
+	Orders      int64  `json:"orders" example:"3"`
+	Money       string `json:"money" example:"30.00"`
+	RefundMoney string `json:"refund_money" example:"5.00"`
+	NetMoney    string `json:"net_money" example:"25.00"`
+}
+
+// QueryMerchantSettlementResponse 商户结算汇总查询响应
+type QueryMerchantSettlementResponse struct {
+	Code        int                      `json:"code" example:"1"`
+	Msg         string                   `json:"msg" example:"查询结算记录成功"`
+	Orders      int64                    `json:"orders" example:"3"`
+	Money       string                   `json:"money" example:"30.00"`
+	RefundMoney string                   `json:"refund_money" example:"5.00"`
+	NetMoney    string                   `json:"net_money" example:"25.00"`
+	Data        []MerchantSettlementItem `json:"data"`
+}
+
+// QueryMerchantAccount 商户信息与余额查询接口（act=query）
+func QueryMerchantAccount(c *gin.Context) {
+	var req MerchantCredentialRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": -1, "msg": err.Error()})
+		return
+	}
+
+	var apiKey model.MerchantAPIKey
+	if err := db.DB(c.Request.Context()).Where("client_id = ? AND client_secret = ?", req.ClientID, req.ClientSecret).First(&apiKey).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": -1, "msg": MerchantInfoNotFound})
+		return
+	}
+
+	var merchantUser model.User
+	if err := merchantUser.GetByID(db.DB(c.Request.Context()), apiKey.UserID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": -1, "msg": MerchantInfoNotFound})
+		return
+	}
+
+	var payConfig model.UserPayConfig
+	if err := payConfig.GetByPayScore(db.DB(c.Request.Context()), merchantUser.PayScore); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": -1, "msg": PayConfigNotFound})
+		return
+	}
+
+	now := time.Now()
+	todayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	yesterdayStart := todayStart.AddDate(0, 0, -1)
+
+	var stats struct {
+		Orders       int64
+		OrderToday   int64
+		OrderLastDay int64
+	}
+	if err := db.DB(c.Request.Context()).Model(&model.Order{}).
+		Select("COUNT(*) AS orders, "+
+			"COUNT(*) FILTER (WHERE trade_time >= ?) AS order_today, "+
+			"COUNT(*) FILTER (WHERE trade_time >= ? AND trade_time < ?) AS order_last_day",
+			todayStart, yesterdayStart, todayStart).
+		Where("client_id = ? AND status IN ?", apiKey.ClientID, model.PaidOrderStatuses).
+		Scan(&stats).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": -1, "msg": err.Error()})
+		return
+	}
+
+	active := 0
+	if merchantUser.IsActive {
+		active = 1
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"code":         1,
-		"msg":          "查询订单号成功！",
-		"trade_no":     strconv.FormatUint(order.ID, 10),
-		"out_trade_no": order.MerchantOrderNo,
-		"type":         order.PaymentType,
-		"pid":          order.ClientID,
-		"addtime":      order.CreatedAt.Format("2006-01-02 15:04:05"),
-		"endtime":      order.TradeTime.Format("2006-01-02 15:04:05"),
-		"name":         order.OrderName,
-		"money":        order.Amount.Truncate(2).StringFixed(2),
-		"status":       statusInt,
+	c.JSON(http.StatusOK, QueryMerchantAccountResponse{
+		Code:         1,
+		Msg:          "查询商户信息成功",
+		Pid:          apiKey.ClientID,
+		Active:       active,
+		Money:        merchantUser.AvailableBalance.StringFixed(2),
+		TotalReceive: merchantUser.TotalReceive.StringFixed(2),
+		PayLevel:     int(payConfig.Level),
+		FeeRate:      payConfig.FeeRate.StringFixed(2),
+		Orders:       stats.Orders,
+		OrderToday:   stats.OrderToday,
+		OrderLastDay: stats.OrderLastDay,
 	})
 }
 
+// QueryMerchantSettlement 商户结算汇总查询接口（act=settle），按交易日汇总当前应用的收款与退款
+func QueryMerchantSettlement(c *gin.Context) {
+	var req QueryMerchantSettlementRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": -1, "msg": err.Error()})
+		return
+	}
+	if req.Days == 0 {
+		req.Days = 7
+	}
+
+	var apiKey model.MerchantAPIKey
+	if err := db.DB(c.Request.Context()).Where("client_id = ? AND client_secret = ?", req.ClientID, req.ClientSecret).First(&apiKey).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": -1, "msg": MerchantInfoNotFound})
+		return
+	}
+
+	now := time.Now()
+	startDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, -(req.Days - 1))
+
+	var rows []struct {
+		Date        time.Time
+		Orders      int64
+		Money       decimal.Decimal
+		RefundMoney decimal.Decimal
+	}
+	if err := db.DB(c.Request.Context()).Model(&model.Order{}).
+		Select("DATE(trade_time) AS date, COUNT(*) AS orders, COALESCE(SUM(amount), 0) AS money, COALESCE(SUM(refunded_amount), 0) AS refund_money").
+		Where("client_id = ? AND status IN ? AND trade_time >= ?", apiKey.ClientID, model.PaidOrderStatuses, startDate).
+		Group("DATE(trade_time)").
+		Order("date DESC").
+		Scan(&rows).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": -1, "msg": err.Error()})
+		return
+	}
+
+	response := QueryMerchantSettlementResponse{
+		Code: 1,
+		Msg:  "查询结算记录成功",
+		Data: make([]MerchantSettlementItem, 0, len(rows)),
+	}
+	totalMoney := decimal.Zero
+	totalRefund := decimal.Zero
+	for _, row := range rows {
+		response.Orders += row.Orders
+		totalMoney = totalMoney.Add(row.Money)
+		totalRefund = totalRefund.Add(row.RefundMoney)
+		response.Data = append(response.Data, MerchantSettlementItem{
+			Date:        row.Date.Format("2006-01-02"),
+			Orders:      row.Orders,
+			Money:       row.Money.StringFixed(2),
+			RefundMoney: row.RefundMoney.StringFixed(2),
+			NetMoney:    row.Money.Sub(row.RefundMoney).StringFixed(2),
+		})
+	}
+	response.Money = totalMoney.StringFixed(2)
+	response.RefundMoney = totalRefund.StringFixed(2)
+	response.NetMoney = totalMoney.Sub(totalRefund).StringFixed(2)
+
+	c.JSON(http.StatusOK, response)
+}
+
 // RefundMerchantOrderResponse 退款响应
 type RefundMerchantOrderResponse struct {
+	Code          int    `json:"code" example:"1"`
+	Msg           string `json:"msg" example:"退款成功"`
+	OutRefundNo   string `json:"out_refund_no" example:"R20240101001"`
+	RefundedMoney string `json:"refunded_money" example:"10.00"`
+	Status        string `json:"status" example:"partially_refunded"`
+}
+
+// CloseOrderRequest 商户关闭订单请求
+type CloseOrderRequest struct {
+	ClientID        string `form:"pid" json:"pid" binding:"required"`
+	ClientSecret    string `form:"key" json:"key" binding:"required"`
+	MerchantOrderNo string `form:"out_trade_no" json:"out_trade_no"`
+	TradeNo         uint64 `form:"trade_no" json:"trade_no"`
+}
+
+// CloseMerchantOrderResponse 关闭订单响应
+type CloseMerchantOrderResponse struct {
 	Code int    `json:"code" example:"1"`
-	Msg  string `json:"msg" example:"退款成功"`
+	Msg  string `json:"msg" example:"关闭订单成功"`
 }
 
-// RefundMerchantOrder 商户退款接口
+// EPayAction 易支付商户操作接口，按 act 分发（act 通过表单字段或查询参数传递）
 // @Tags payment
 // @Accept json
 // @Produce json
+// @Param act query string false "操作类型：refund（默认）、close"
 // @Param request body RefundOrderRequest true "退款请求"
 // @Success 200 {object} RefundMerchantOrderResponse
 // @Router /api.php [post]
+func EPayAction(c *gin.Context) {
+	switch c.DefaultPostForm("act", c.Query("act")) {
+	case "", "refund":
+		RefundMerchantOrder(c)
+	case "close":
+		CloseMerchantOrder(c)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"code": -1, "msg": UnsupportedAct})
+	}
+}
+
+// CloseMerchantOrder 商户关闭待支付订单接口（act=close），支持按平台订单号或商户订单号关闭
+func CloseMerchantOrder(c *gin.Context) {
+	var req CloseOrderRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": -1, "msg": err.Error()})
+		return
+	}
+
+	if req.TradeNo == 0 && req.MerchantOrderNo == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": -1, "msg": TradeNoRequired})
+		return
+	}
+
+	var apiKey model.MerchantAPIKey
+	if err := db.DB(c.Request.Context()).Where("client_id = ? AND client_secret = ?", req.ClientID, req.ClientSecret).First(&apiKey).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": -1, "msg": MerchantInfoNotFound})
+		return
+	}
+
+	tradeNo := req.TradeNo
+	if tradeNo == 0 {
+		var order model.Order
+		if err := db.DB(c.Request.Context()).
+			Where("client_id = ? AND merchant_order_no = ?", apiKey.ClientID, req.MerchantOrderNo).
+			Order("created_at DESC").
+			First(&order).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"code": -1, "msg": OrderNotFound})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"code": -1, "msg": err.Error()})
+			return
+		}
+		tradeNo = order.ID
+	}
+
+	if _, err := closeMerchantOrder(c.Request.Context(), apiKey.ClientID, tradeNo); err != nil {
+		switch err.Error() {
+		case OrderNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"code": -1, "msg": OrderNotFound})
+		case OrderNotClosable:
+			c.JSON(http.StatusOK, gin.H{"code": -1, "msg": OrderNotClosable})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"code": -1, "msg": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, CloseMerchantOrderResponse{
+		Code: 1,
+		Msg:  "关闭订单成功",
+	})
+}
+
+// RefundMerchantOrder 商户退款接口（act=refund，支持部分退款与多次退款）
 func RefundMerchantOrder(c *gin.Context) {
 	var req RefundOrderRequest
 	if err := c.ShouldBind(&req); err != nil {
@@ -267,10 +785,30 @@ func RefundMerchantOrder(c *gin.Context) {
 		return
 	}
 
-	if err := db.DB(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
-		var order model.Order
+	order, refund, err := refundMerchantOrder(c.Request.Context(), &apiKey, req.TradeNo, req.Amount, req.RefundNo)
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{"code": -1, "msg": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, RefundMerchantOrderResponse{
+		Code:          1,
+		Msg:           "退款成功",
+		OutRefundNo:   refund.MerchantRefundNo,
+		RefundedMoney: order.RefundedAmount.Truncate(2).StringFixed(2),
+		Status:        string(order.Status),
+	})
+}
+
+// refundMerchantOrder 对商户订单发起一笔退款，同一退款单号重复请求时返回已有退款记录
+// 未指定退款单号时以平台订单号作为退款单号，仅允许退回订单剩余全部可退金额
+// 返回：退款后的订单、退款记录
+func refundMerchantOrder(ctx context.Context, apiKey *model.MerchantAPIKey, tradeNo uint64, amount decimal.Decimal, refundNo string) (*model.Order, *model.OrderRefund, error) {
+	var order model.Order
+	var refund model.OrderRefund
+	if err := db.DB(ctx).Transaction(func(tx *gorm.DB) error {
 		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
-			Where("id = ? AND client_id = ? AND status = ? AND amount = ?", req.TradeNo, req.ClientID, model.OrderStatusSuccess, req.Amount).
+			Where("id = ? AND client_id = ?", tradeNo, apiKey.ClientID).
 			First(&order).Error; err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
 				return errors.New(OrderNotFound)
@@ -278,6 +816,36 @@ func RefundMerchantOrder(c *gin.Context) {
 			return err
 		}
 
+		defaultRefundNo := refundNo == ""
+		if defaultRefundNo {
+			refundNo = strconv.FormatUint(order.ID, 10)
+		}
+
+		// 退款单号幂等：相同单号的重复请求直接返回成功
+		if err := tx.Where("client_id = ? AND merchant_refund_no = ?", apiKey.ClientID, refundNo).
+			First(&refund).Error; err == nil {
+			if refund.OrderID != order.ID || !refund.Amount.Equal(amount) {
+				return errors.New(RefundNoConflict)
+			}
+			return nil
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if order.Status != model.OrderStatusSuccess && order.Status != model.OrderStatusPartiallyRefunded {
+			return errors.New(OrderNotRefundable)
+		}
+
+		refundedAmount := order.RefundedAmount.Add(amount)
+		if refundedAmount.GreaterThan(order.Amount) {
+			return errors.New(RefundAmountExceeded)
+		}
+
+		// 部分退款必须由商户指定退款单号，否则后续退款会与默认单号冲突
+		if defaultRefundNo && refundedAmount.LessThan(order.Amount) {
+			return errors.New(RefundNoRequired)
+		}
+
 		var payerUser model.User
 		if err := payerUser.GetByID(tx, order.PayerUserID); err != nil {
 			return err
@@ -285,6 +853,9 @@ func RefundMerchantOrder(c *gin.Context) {
 
 		var merchantUser model.User
 		if err := tx.Where("id = ? AND is_active = ?", apiKey.UserID, true).First(&merchantUser).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New(MerchantInfoNotFound)
+			}
 			return err
 		}
 
@@ -293,43 +864,100 @@ func RefundMerchantOrder(c *gin.Context) {
 			return err
 		}
 
-		merchantScoreDecrease := order.Amount.Mul(merchantPayConfig.ScoreRate).Round(0).IntPart()
-		if err := tx.Model(&model.User{}).
-			Where("id = ?", merchantUser.ID).
-			UpdateColumns(map[string]interface{}{
-				"available_balance": gorm.Expr("available_balance - ?", order.Amount),
-				"total_receive":     gorm.Expr("total_receive - ?", order.Amount),
-				"pay_score":         gorm.Expr("pay_score - ?", merchantScoreDecrease),
-			}).Error; err != nil {
+		// 按退款手续费策略计算本次退还的手续费
+		feeRefund, err := service.CalculateRefundFee(ctx, &order, amount)
+		if err != nil {
 			return err
 		}
+		refundedFee := order.RefundedFee.Add(feeRefund)
 
-		if err := tx.Model(&model.User{}).
-			Where("id = ?", payerUser.ID).
-			UpdateColumns(map[string]interface{}{
-				"available_balance": gorm.Expr("available_balance + ?", order.Amount),
-				"total_payment":     gorm.Expr("total_payment - ?", order.Amount),
-				"pay_score":         gorm.Expr("pay_score - ?", order.Amount.Round(0).IntPart()),
-			}).Error; err != nil {
+		merchantScoreDecrease := amount.Mul(merchantPayConfig.ScoreRate).Round(0).IntPart()
+		if err := service.PostOrderRefund(tx, model.LedgerEntryRefund, &order, amount, feeRefund, merchantScoreDecrease, refundNo); err != nil {
+			return err
+		}
+
+		refund = model.OrderRefund{
+			OrderID:          order.ID,
+			ClientID:         apiKey.ClientID,
+			MerchantRefundNo: refundNo,
+			Amount:           amount,
+		}
+		if err := tx.Create(&refund).Error; err != nil {
 			return err
 		}
 
+		// 累计退款达到订单金额时视为全额退款
+		status := model.OrderStatusPartiallyRefunded
+		if refundedAmount.Equal(order.Amount) {
+			status = model.OrderStatusRefund
+		}
+
 		if err := tx.Model(&model.Order{}).
 			Where("id = ?", order.ID).
-			Update("status", model.OrderStatusRefund).Error; err != nil {
+			UpdateColumns(map[string]interface{}{
+				"refunded_amount": refundedAmount,
+				"refunded_fee":    refundedFee,
+				"status":          status,
+			}).Error; err != nil {
 			return err
 		}
 
-		return nil
+		order.RefundedAmount = refundedAmount
+		order.RefundedFee = refundedFee
+		order.Status = status
+
+		return service.EnqueueWebhookEvent(tx, &order, model.WebhookEventOrderRefunded, map[string]string{
+			"out_refund_no":  refundNo,
+			"refund_money":   amount.StringFixed(2),
+			"refunded_money": refundedAmount.StringFixed(2),
+		})
 	}); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": -1, "msg": err.Error()})
-		return
+		return nil, nil, err
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"code": 1,
-		"msg":  "退款成功",
-	})
+	return &order, &refund, nil
+}
+
+// closeMerchantOrder 关闭待支付的商户订单，并清理收银台相关的 Redis 缓存
+func closeMerchantOrder(ctx context.Context, clientID string, orderID uint64) (*model.Order, error) {
+	var order model.Order
+	if err := db.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND client_id = ?", orderID, clientID).
+			First(&order).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New(OrderNotFound)
+			}
+			return err
+		}
+
+		if order.Status != model.OrderStatusPending {
+			return errors.New(OrderNotClosable)
+		}
+
+		order.Status = model.OrderStatusClosed
+		return tx.Model(&model.Order{}).
+			Where("id = ?", order.ID).
+			Update("status", model.OrderStatusClosed).Error
+	}); err != nil {
+		return nil, err
+	}
+
+	// 过期 key 的值为加密后的订单号，据此删除订单号缓存，使收银台无法再打开该订单
+	expireKey := db.PrefixedKey(fmt.Sprintf(OrderExpireKeyFormat, order.ID))
+	encryptString, err := db.Redis.Get(ctx, expireKey).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		log.Printf("[Payment] 查询订单过期key失败: order_id=%d, error=%v", order.ID, err)
+	}
+	keys := []string{expireKey}
+	if encryptString != "" {
+		keys = append(keys, db.PrefixedKey(fmt.Sprintf(OrderMerchantIDCacheKeyFormat, encryptString)))
+	}
+	if err := db.Redis.Del(ctx, keys...).Err(); err != nil {
+		log.Printf("[Payment] 删除订单缓存key失败: order_id=%d, error=%v", order.ID, err)
+	}
+
+	return &order, nil
 }
 
 // GetPaymentPageDetails 查询支付订单信息接口（用于收银台页面）
@@ -358,6 +986,14 @@ func GetPaymentPageDetails(c *gin.Context) {
 		Where("orders.id = ? AND orders.status = ?", orderCtx.OrderID, model.OrderStatusPending).
 		First(&order).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// 商户已关闭的订单给出明确提示
+			var count int64
+			if errCount := db.DB(c.Request.Context()).Model(&model.Order{}).
+				Where("id = ? AND status = ?", orderCtx.OrderID, model.OrderStatusClosed).
+				Count(&count).Error; errCount == nil && count > 0 {
+				c.JSON(http.StatusNotFound, util.Err(OrderClosed))
+				return
+			}
 			c.JSON(http.StatusNotFound, util.Err(OrderNotFound))
 			return
 		}
@@ -388,6 +1024,7 @@ func GetPaymentPageDetails(c *gin.Context) {
 // @Tags payment
 // @Accept json
 // @Produce json
+// @Param Idempotency-Key header string false "幂等键，重复请求返回首次响应"
 // @Param request body PayOrderRequest true "支付订单请求"
 // @Success 200 {object} util.ResponseAny
 // @Router /api/v1/merchant/payment [post]
@@ -402,14 +1039,14 @@ func PayMerchantOrder(c *gin.Context) {
 		return
 	}
 
-	if !orderCtx.CurrentUser.VerifyPayKey(req.PayKey) {
-		c.JSON(http.StatusBadRequest, util.Err(common.PayKeyIncorrect))
+	if err := service.VerifyPayKey(c.Request.Context(), orderCtx.CurrentUser, req.PayKey); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
 		return
 	}
 
+	var order model.Order
 	if err := db.DB(c.Request.Context()).Transaction(
 		func(tx *gorm.DB) error {
-			var order model.Order
 			if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "NOWAIT"}).
 				Where("id = ? AND status = ?", orderCtx.OrderID, model.OrderStatusPending).
 				First(&order).Error; err != nil {
@@ -424,13 +1061,18 @@ func PayMerchantOrder(c *gin.Context) {
 				return errors.New(OrderExpired)
 			}
 
+			// 超过两步验证阈值时校验验证码，验证码在支付事务内消耗，支付失败时随事务回滚
+			if err := service.VerifyTOTPForPayment(c.Request.Context(), tx, orderCtx.CurrentUser, order.Amount, req.TOTPCode); err != nil {
+				return err
+			}
+
 			// 检查每日限额
 			if err := service.CheckDailyLimit(tx, orderCtx.CurrentUser.ID, order.Amount, orderCtx.PayerPayConfig.DailyLimit); err != nil {
 				return err
 			}
 
 			// 计算手续费
-			_, merchantAmount, feePercent := service.CalculateFee(order.Amount, orderCtx.MerchantPayConfig.FeeRate)
+			fee, merchantAmount, feePercent := service.CalculateFee(order.Amount, orderCtx.MerchantPayConfig.FeeRate)
 			feeRemark := fmt.Sprintf("[系统]: 收取商家%d%%手续费", feePercent)
 
 			// 更新订单状态和备注
@@ -440,43 +1082,21 @@ func PayMerchantOrder(c *gin.Context) {
 				order.Remark = feeRemark
 			}
 			order.Status = model.OrderStatusSuccess
+			order.Fee = fee
 			order.PayerUserID = orderCtx.CurrentUser.ID
 			order.TradeTime = time.Now()
 			if err := tx.Save(&order).Error; err != nil {
 				return err
 			}
 
-			// 扣减用户余额
-			if err := service.DeductUserBalance(tx, orderCtx.CurrentUser.ID, order.Amount); err != nil {
-				return err
-			}
-
-			// 增加商户余额和积分
+			// 记账：扣减用户余额，增加商户余额和积分，手续费计入平台账户
 			merchantScoreIncrease := order.Amount.Mul(orderCtx.MerchantPayConfig.ScoreRate).Round(0).IntPart()
-			if err := service.AddMerchantBalance(tx, orderCtx.MerchantUser.ID, merchantAmount, merchantScoreIncrease); err != nil {
+			if err := service.PostOrderPayment(tx, &order, orderCtx.MerchantUser.ID, merchantAmount, merchantScoreIncrease); err != nil {
 				return err
 			}
 
-			expireKey := db.PrefixedKey(fmt.Sprintf(OrderExpireKeyFormat, order.ID))
-			if err := db.Redis.Del(c.Request.Context(), expireKey).Err(); err != nil {
-				log.Printf("[Payment] 删除订单过期key失败: order_id=%d, error=%v", order.ID, err)
-			}
-
-			// 下发商户回调任务
-			notifyPayload, _ := json.Marshal(map[string]interface{}{
-				"order_id":  order.ID,
-				"client_id": order.ClientID,
-			})
-			if _, errTask := scheduler.AsynqClient.Enqueue(
-				asynq.NewTask(task.MerchantPaymentNotifyTask, notifyPayload),
-				asynq.Queue(task.QueueWebhook),
-				asynq.MaxRetry(10),
-				asynq.Timeout(30*time.Second),
-			); errTask != nil {
-				return fmt.Errorf("下发商户回调任务失败: %w", errTask)
-			}
-
-			return nil
+			// 下发商户回调任务，事务提交后由发件箱中继投递
+			return service.EnqueueMerchantPaymentNotify(tx, order.ID, order.ClientID)
 		},
 	); err != nil {
 		errMsg := err.Error()
@@ -488,19 +1108,43 @@ func PayMerchantOrder(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, util.Err(OrderExpired))
 		} else if errMsg == common.DailyLimitExceeded {
 			c.JSON(http.StatusBadRequest, util.Err(common.DailyLimitExceeded))
+		} else if errMsg == common.TOTPRequired || errMsg == common.TOTPIncorrect || errMsg == common.TOTPUnavailable || errMsg == common.PayKeyLocked {
+			c.JSON(http.StatusBadRequest, util.Err(errMsg))
 		} else {
 			c.JSON(http.StatusInternalServerError, util.Err(errMsg))
 		}
 		return
 	}
 
-	c.JSON(http.StatusOK, util.OKNil())
+	// 事务提交后再删除订单过期 key，避免事务回滚后订单失去过期处理
+	expireKey := db.PrefixedKey(fmt.Sprintf(OrderExpireKeyFormat, order.ID))
+	if err := db.Redis.Del(c.Request.Context(), expireKey).Err(); err != nil {
+		log.Printf("[Payment] 删除订单过期key失败: order_id=%d, error=%v", order.ID, err)
+	}
+
+	// 构建商户同步跳转地址
+	response := PayOrderResponse{}
+	if order.ReturnURL != "" {
+		var apiKey model.MerchantAPIKey
+		if err := apiKey.GetByClientID(db.DB(c.Request.Context()), order.ClientID); err != nil {
+			log.Printf("[Payment] 查询商户信息失败，跳过同步跳转: order_id=%d, error=%v", order.ID, err)
+		} else {
+			if params, errBuild := buildResultParams(&order, apiKey.ClientSecret); errBuild != nil {
+				log.Printf("[Payment] 构建同步跳转参数失败: order_id=%d, error=%v", order.ID, errBuild)
+			} else {
+				response.ReturnURL = appendQueryParams(order.ReturnURL, params)
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, util.OK(response))
 }
 
 // Transfer 用户转账接口
 // @Tags payment
 // @Accept json
 // @Produce json
+// @Param Idempotency-Key header string false "幂等键，重复请求返回首次响应"
 // @Param request body TransferRequest true "转账请求"
 // @Success 200 {object} util.ResponseAny
 // @Router /api/v1/payment/transfer [post]
@@ -523,8 +1167,8 @@ func Transfer(c *gin.Context) {
 
 	currentUser, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
 
-	if !currentUser.VerifyPayKey(req.PayKey) {
-		c.JSON(http.StatusBadRequest, util.Err(common.PayKeyIncorrect))
+	if err := service.VerifyPayKey(c.Request.Context(), currentUser, req.PayKey); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
 		return
 	}
 
@@ -551,6 +1195,11 @@ func Transfer(c *gin.Context) {
 				return err
 			}
 
+			// 超过两步验证阈值时校验验证码，验证码在转账事务内消耗，转账失败时随事务回滚
+			if err := service.VerifyTOTPForPayment(c.Request.Context(), tx, currentUser, req.Amount, req.TOTPCode); err != nil {
+				return err
+			}
+
 			if payer.AvailableBalance.LessThan(req.Amount) {
 				return errors.New(common.InsufficientBalance)
 			}
@@ -572,27 +1221,8 @@ func Transfer(c *gin.Context) {
 				return err
 			}
 
-			// 扣减付款人余额
-			if err := tx.Model(&model.User{}).
-				Where("id = ?", payer.ID).
-				UpdateColumns(map[string]interface{}{
-					"available_balance": gorm.Expr("available_balance - ?", req.Amount),
-					"total_transfer":    gorm.Expr("total_transfer + ?", req.Amount),
-				}).Error; err != nil {
-				return err
-			}
-
-			// 增加收款人余额
-			if err := tx.Model(&model.User{}).
-				Where("id = ?", recipient.ID).
-				UpdateColumns(map[string]interface{}{
-					"available_balance": gorm.Expr("available_balance + ?", req.Amount),
-					"total_receive":     gorm.Expr("total_receive + ?", req.Amount),
-				}).Error; err != nil {
-				return err
-			}
-
-			return nil
+			// 记账：扣减付款人余额，增加收款人余额
+			return service.PostTransfer(tx, &order)
 		},
 	); err != nil {
 		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
@@ -17,15 +17,16 @@ limitations under the License.
 package payment
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/hibiken/asynq"
 	"github.com/linux-do/credit/internal/common"
@@ -48,9 +49,9 @@ func HandleMerchantPaymentNotify(ctx context.Context, t *asynq.Task) error {
 		return fmt.Errorf("解析任务参数失败: %w", err)
 	}
 
-	// 查询订单信息
+	// 查询订单信息，重发回调时订单可能已发生退款或争议
 	var order model.Order
-	if err := db.DB(ctx).Where("id = ? AND status = ?", payload.OrderID, model.OrderStatusSuccess).First(&order).Error; err != nil {
+	if err := db.DB(ctx).Where("id = ? AND status IN ?", payload.OrderID, model.PaidOrderStatuses).First(&order).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			logger.ErrorF(ctx, "订单[ID:%d]不存在，跳过回调", payload.OrderID)
 			return nil
@@ -65,24 +66,20 @@ func HandleMerchantPaymentNotify(ctx context.Context, t *asynq.Task) error {
 		return fmt.Errorf("查询商户信息失败: %w", err)
 	}
 
-	// 构建回调参数
-	callbackParams := map[string]string{
-		"pid":          payload.ClientID,
-		"trade_no":     strconv.FormatUint(order.ID, 10),
-		"out_trade_no": order.MerchantOrderNo,
-		"type":         common.PayTypeEPay,
-		"name":         order.OrderName,
-		"money":        order.Amount.Truncate(2).StringFixed(2),
-		"trade_status": "TRADE_SUCCESS",
-		"sign_type":    "MD5",
+	if !apiKey.WebhookEvents.Contains(model.WebhookEventOrderPaid) {
+		logger.InfoF(ctx, "商户[ClientID:%s]未订阅 %s 事件，跳过回调", payload.ClientID, model.WebhookEventOrderPaid)
+		return nil
 	}
 
-	callbackParams["sign"] = GenerateSignature(callbackParams, apiKey.ClientSecret)
-
-	if err := sendCallbackRequest(ctx, apiKey.NotifyURL, callbackParams); err != nil {
-		retried, _ := asynq.GetRetryCount(ctx)
+	retried, _ := asynq.GetRetryCount(ctx)
+	if _, err := deliverWebhook(ctx, model.WebhookEventOrderPaid, taskEventID(ctx), &order, &apiKey, nil, retried, false); err != nil {
 		logger.ErrorF(ctx, "商户回调失败: 订单[ID:%d] 重试次数[%d] 错误: %v",
 			payload.OrderID, retried+1, err)
+
+		// 重试次数耗尽，标记订单回调失败并提醒商户
+		if maxRetry, ok := asynq.GetMaxRetry(ctx); ok && retried >= maxRetry {
+			markNotifyFailed(ctx, &order, &apiKey)
+		}
 		return err
 	}
 
@@ -90,44 +87,278 @@ func HandleMerchantPaymentNotify(ctx context.Context, t *asynq.Task) error {
 	return nil
 }
 
-// sendCallbackRequest 发送HTTP回调请求
-func sendCallbackRequest(ctx context.Context, callbackURL string, params map[string]string) error {
-	vals := url.Values{}
-	for k, v := range params {
-		vals.Add(k, v)
+// HandleMerchantWebhookEvent 处理商户 Webhook 事件通知任务（退款、过期、争议等）
+func HandleMerchantWebhookEvent(ctx context.Context, t *asynq.Task) error {
+	var payload struct {
+		Event    model.WebhookEvent `json:"event"`
+		OrderID  uint64             `json:"order_id"`
+		ClientID string             `json:"client_id"`
+		Data     map[string]string  `json:"data"`
+	}
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		logger.ErrorF(ctx, "解析商户事件通知任务参数失败: %v", err)
+		return fmt.Errorf("解析任务参数失败: %w", err)
+	}
+
+	var apiKey model.MerchantAPIKey
+	if err := apiKey.GetByClientID(db.DB(ctx), payload.ClientID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.InfoF(ctx, "商户[ClientID:%s]不存在，跳过事件通知", payload.ClientID)
+			return nil
+		}
+		return fmt.Errorf("查询商户信息失败: %w", err)
+	}
+
+	if !apiKey.WebhookEvents.Contains(payload.Event) {
+		return nil
+	}
+
+	var order model.Order
+	if err := db.DB(ctx).Where("id = ?", payload.OrderID).First(&order).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.ErrorF(ctx, "订单[ID:%d]不存在，跳过事件通知", payload.OrderID)
+			return nil
+		}
+		return fmt.Errorf("查询订单失败: %w", err)
+	}
+
+	retried, _ := asynq.GetRetryCount(ctx)
+	if _, err := deliverWebhook(ctx, payload.Event, taskEventID(ctx), &order, &apiKey, payload.Data, retried, false); err != nil {
+		logger.ErrorF(ctx, "商户事件通知失败: 事件[%s] 订单[ID:%d] 重试次数[%d] 错误: %v",
+			payload.Event, payload.OrderID, retried+1, err)
+		return err
+	}
+
+	logger.InfoF(ctx, "商户事件通知成功: 事件[%s] 订单[ID:%d] ClientID[%s]", payload.Event, payload.OrderID, payload.ClientID)
+	return nil
+}
+
+// callbackResult 商户回调响应结果
+type callbackResult struct {
+	StatusCode int
+	Body       string
+}
+
+// taskEventID 生成事件 ID，取自任务 ID 以保证重试时保持不变
+func taskEventID(ctx context.Context) string {
+	if taskID, ok := asynq.GetTaskID(ctx); ok {
+		return "evt_" + taskID
+	}
+	return "evt_" + util.GenerateUniqueIDSimple()
+}
+
+// deliverWebhook 按商户配置的回调格式发送一次事件通知，并持久化本次投递记录
+func deliverWebhook(ctx context.Context, event model.WebhookEvent, eventID string, order *model.Order, apiKey *model.MerchantAPIKey, data map[string]string, retryCount int, manual bool) (*model.WebhookDelivery, error) {
+	// 优先使用订单级别的回调地址
+	notifyURL := order.NotifyURL
+	if notifyURL == "" {
+		notifyURL = apiKey.NotifyURL
+	}
+
+	var (
+		method    string
+		payload   string
+		startTime time.Time
+		result    *callbackResult
+		errSend   error
+	)
+
+	if apiKey.CallbackFormat == model.CallbackFormatJSON {
+		body, err := buildWebhookPayload(eventID, event, order, data)
+		if err != nil {
+			return nil, fmt.Errorf("构建回调参数失败: %w", err)
+		}
+
+		method, payload = http.MethodPost, string(body)
+		startTime = time.Now()
+		result, errSend = sendWebhookRequest(ctx, notifyURL, event, eventID, body, apiKey.ClientSecret)
+		if errSend == nil {
+			errSend = checkWebhookResponse(ctx, notifyURL, result)
+		}
+	} else {
+		callbackParams, err := buildCallbackParams(event, order, data, apiKey.ClientSecret)
+		if err != nil {
+			return nil, fmt.Errorf("构建回调参数失败: %w", err)
+		}
+
+		// LDPay 订单以 JSON POST 方式回调，其余沿用易支付 GET 方式
+		send := sendCallbackRequest
+		method = http.MethodGet
+		if order.PaymentType == common.PayTypeLDPay {
+			method, send = http.MethodPost, sendLDPayCallbackRequest
+		}
+
+		paramsJSON, _ := json.Marshal(callbackParams)
+		payload = string(paramsJSON)
+		startTime = time.Now()
+		result, errSend = send(ctx, notifyURL, callbackParams)
+		if errSend == nil {
+			errSend = checkCallbackResponse(ctx, notifyURL, result)
+		}
 	}
 
-	// 拼接URL
-	separator := "?"
-	if strings.Contains(callbackURL, "?") {
-		separator = "&"
+	delivery := &model.WebhookDelivery{
+		OrderID:    order.ID,
+		ClientID:   order.ClientID,
+		Event:      string(event),
+		EventID:    eventID,
+		URL:        notifyURL,
+		Method:     method,
+		Params:     payload,
+		LatencyMs:  time.Since(startTime).Milliseconds(),
+		RetryCount: retryCount,
+		Manual:     manual,
+		Success:    errSend == nil,
+	}
+	if result != nil {
+		delivery.HTTPStatus = result.StatusCode
+		delivery.ResponseBody = truncateSnippet(result.Body, WebhookResponseSnippetLimit)
+	}
+	if errSend != nil {
+		delivery.Error = errSend.Error()
 	}
-	targetURL := callbackURL + separator + vals.Encode()
+
+	if err := db.DB(ctx).Create(delivery).Error; err != nil {
+		logger.ErrorF(ctx, "记录商户回调投递失败: 订单[ID:%d] 错误: %v", order.ID, err)
+	}
+
+	// 支付回调重发成功后清除失败标记
+	if delivery.Success && event == model.WebhookEventOrderPaid && order.NotifyFailed {
+		if err := db.DB(ctx).Model(&model.Order{}).
+			Where("id = ?", order.ID).
+			UpdateColumn("notify_failed", false).Error; err != nil {
+			logger.ErrorF(ctx, "清除订单回调失败标记失败: 订单[ID:%d] 错误: %v", order.ID, err)
+		}
+	}
+
+	return delivery, errSend
+}
+
+// markNotifyFailed 标记订单支付回调失败，并向商户发送站内提醒（同一应用存在未读提醒时不重复发送）
+func markNotifyFailed(ctx context.Context, order *model.Order, apiKey *model.MerchantAPIKey) {
+	result := db.DB(ctx).Model(&model.Order{}).
+		Where("id = ? AND notify_failed = ?", order.ID, false).
+		UpdateColumn("notify_failed", true)
+	if result.Error != nil {
+		logger.ErrorF(ctx, "标记订单回调失败出错: 订单[ID:%d] 错误: %v", order.ID, result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		return
+	}
+
+	notification := model.Notification{
+		UserID:  apiKey.UserID,
+		Type:    model.NotificationTypeWebhookFailed,
+		RefKey:  apiKey.ClientID,
+		Title:   NotifyFailedNotificationTitle,
+		Content: fmt.Sprintf(NotifyFailedNotificationContent, apiKey.AppName, order.ID),
+		Link:    "/merchant",
+	}
+	if err := notification.CreateUnlessUnread(db.DB(ctx)); err != nil {
+		logger.ErrorF(ctx, "创建回调失败提醒出错: 订单[ID:%d] 错误: %v", order.ID, err)
+	}
+}
+
+// sendCallbackRequest 发送HTTP回调请求
+func sendCallbackRequest(ctx context.Context, callbackURL string, params map[string]string) (*callbackResult, error) {
+	targetURL := appendQueryParams(callbackURL, params)
 
 	headers := map[string]string{
 		"User-Agent": "LinuxDo-Credit/1.0",
 	}
 
-	resp, err := util.Request(ctx, http.MethodGet, targetURL, nil, headers, nil)
+	resp, err := util.WebhookRequest(ctx, http.MethodGet, targetURL, nil, headers)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("回调返回异常状态码: %d", resp.StatusCode)
+	return readCallbackResponse(resp)
+}
+
+// sendLDPayCallbackRequest 发送 LDPay JSON 回调请求
+func sendLDPayCallbackRequest(ctx context.Context, callbackURL string, params map[string]string) (*callbackResult, error) {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("序列化回调参数失败: %w", err)
+	}
+
+	headers := map[string]string{
+		"User-Agent":   "LinuxDo-Credit/1.0",
+		"Content-Type": "application/json",
+	}
+
+	resp, err := util.WebhookRequest(ctx, http.MethodPost, callbackURL, bytes.NewReader(body), headers)
+	if err != nil {
+		return nil, err
 	}
 
+	return readCallbackResponse(resp)
+}
+
+// sendWebhookRequest 发送 JSON 格式回调请求，签名与事件信息通过请求头传递
+func sendWebhookRequest(ctx context.Context, callbackURL string, event model.WebhookEvent, eventID string, body []byte, secret string) (*callbackResult, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	headers := map[string]string{
+		"User-Agent":           "LinuxDo-Credit/1.0",
+		"Content-Type":         "application/json",
+		WebhookEventIDHeader:   eventID,
+		WebhookEventHeader:     string(event),
+		WebhookTimestampHeader: timestamp,
+		WebhookSignatureHeader: GenerateWebhookSignature(timestamp, body, secret),
+	}
+
+	resp, err := util.WebhookRequest(ctx, http.MethodPost, callbackURL, bytes.NewReader(body), headers)
+	if err != nil {
+		return nil, err
+	}
+
+	return readCallbackResponse(resp)
+}
+
+// readCallbackResponse 读取商户回调响应并关闭响应体
+func readCallbackResponse(resp *http.Response) (*callbackResult, error) {
+	defer resp.Body.Close()
+
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("读取响应失败: %w", err)
+		return &callbackResult{StatusCode: resp.StatusCode}, fmt.Errorf("读取响应失败: %w", err)
 	}
 
-	responseText := strings.TrimSpace(strings.ToLower(string(respBody)))
+	return &callbackResult{StatusCode: resp.StatusCode, Body: string(respBody)}, nil
+}
+
+// checkCallbackResponse 校验商户回调响应，要求状态码为 200 且响应体为 success
+func checkCallbackResponse(ctx context.Context, callbackURL string, result *callbackResult) error {
+	if result.StatusCode != http.StatusOK {
+		return fmt.Errorf("回调返回异常状态码: %d", result.StatusCode)
+	}
+
+	responseText := strings.TrimSpace(strings.ToLower(result.Body))
 	if responseText != "success" {
-		return fmt.Errorf("回调返回非成功响应: %s", string(respBody))
+		return fmt.Errorf("回调返回非成功响应: %s", truncateSnippet(result.Body, WebhookResponseSnippetLimit))
+	}
+
+	logger.InfoF(ctx, "商户回调请求成功: URL[%s] 响应[%s]", callbackURL, result.Body)
+	return nil
+}
+
+// checkWebhookResponse 校验 JSON 格式回调响应，状态码为 2xx 即视为成功
+func checkWebhookResponse(ctx context.Context, callbackURL string, result *callbackResult) error {
+	if result.StatusCode < http.StatusOK || result.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("回调返回异常状态码: %d", result.StatusCode)
 	}
 
-	logger.InfoF(ctx, "商户回调请求成功: URL[%s] 响应[%s]", callbackURL, string(respBody))
+	logger.InfoF(ctx, "商户回调请求成功: URL[%s] 状态码[%d]", callbackURL, result.StatusCode)
 	return nil
 }
+
+// truncateSnippet 按字符截断文本，避免截断多字节字符
+func truncateSnippet(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit])
+}
@@ -17,14 +17,22 @@ limitations under the License.
 package payment
 
 import (
+	"bytes"
+	"crypto/hmac"
 	"crypto/md5"
+	"crypto/sha256"
 	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
@@ -177,6 +185,36 @@ func GenerateSignature(params map[string]string, secret string) string {
 	return fmt.Sprintf("%x", hash)
 }
 
+// buildEPayResultParams 构建易支付支付结果参数（异步通知与同步跳转共用），包含签名
+func buildEPayResultParams(order *model.Order, clientSecret string) map[string]string {
+	params := map[string]string{
+		"pid":          order.ClientID,
+		"trade_no":     strconv.FormatUint(order.ID, 10),
+		"out_trade_no": order.MerchantOrderNo,
+		"type":         common.PayTypeEPay,
+		"name":         order.OrderName,
+		"money":        order.Amount.Truncate(2).StringFixed(2),
+		"trade_status": "TRADE_SUCCESS",
+		"sign_type":    "MD5",
+	}
+	params["sign"] = GenerateSignature(params, clientSecret)
+	return params
+}
+
+// appendQueryParams 将参数追加到 URL 的查询字符串中
+func appendQueryParams(rawURL string, params map[string]string) string {
+	vals := url.Values{}
+	for k, v := range params {
+		vals.Add(k, v)
+	}
+
+	separator := "?"
+	if strings.Contains(rawURL, "?") {
+		separator = "&"
+	}
+	return rawURL + separator + vals.Encode()
+}
+
 // VerifySignature 验证MD5签名
 func VerifySignature(c *gin.Context, apiKey *model.MerchantAPIKey) (*CreateOrderRequest, error) {
 	var req EPayRequest
@@ -215,8 +253,310 @@ func VerifySignature(c *gin.Context, apiKey *model.MerchantAPIKey) (*CreateOrder
 
 	// 常量时间比较签名（防止时序攻击）
 	if subtle.ConstantTimeCompare([]byte(strings.ToLower(expectedSign)), []byte(strings.ToLower(req.Sign))) != 1 {
-		return nil, errors.New("签名验证失败")
+		return nil, errors.New(SignatureInvalid)
 	}
 
 	return req.ToCreateOrderRequest(), nil
 }
+
+// canonicalJSON 生成 LDPay 签名所用的规范化 JSON，规则如下（字符串转义与 RFC 8785 一致）：
+//  1. 排除 sign 字段，其余字段（含空值）全部参与
+//  2. 按 key 的 UTF-8 字节序升序排列，输出紧凑 JSON，不含任何空白
+//  3. key 与 value 均按 JSON 字符串输出：" 与 \ 转义为 \" 与 \\；\b \f \n \r \t 使用短转义；
+//     其余 U+0000~U+001F 控制字符转义为 \u00xx（小写十六进制）；其他字符（含 < > & U+2028 U+2029
+//     及所有非 ASCII 字符）均按原始 UTF-8 输出，不做任何转义
+//  4. key 与 value 必须为合法 UTF-8
+func canonicalJSON(params map[string]string) ([]byte, error) {
+	keys := make([]string, 0, len(params))
+	for k, v := range params {
+		if k == "sign" {
+			continue
+		}
+		if !utf8.ValidString(k) || !utf8.ValidString(v) {
+			return nil, errors.New(LDPayBodyInvalid)
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, k := range keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		writeCanonicalJSONString(&buf, k)
+		buf.WriteByte(':')
+		writeCanonicalJSONString(&buf, params[k])
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// writeCanonicalJSONString 按 canonicalJSON 的转义规则写入一个 JSON 字符串
+func writeCanonicalJSONString(buf *bytes.Buffer, s string) {
+	const hexDigits = "0123456789abcdef"
+
+	buf.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch c {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if c < 0x20 {
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hexDigits[c>>4])
+				buf.WriteByte(hexDigits[c&0xf])
+			} else {
+				buf.WriteByte(c)
+			}
+		}
+	}
+	buf.WriteByte('"')
+}
+
+// GenerateLDPaySignature 生成 LDPay HMAC-SHA256 签名（十六进制小写）
+func GenerateLDPaySignature(params map[string]string, secret string) (string, error) {
+	payload, err := canonicalJSON(params)
+	if err != nil {
+		return "", err
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// buildLDPayResultParams 构建 LDPay 支付结果参数（异步通知与同步跳转共用），包含时间戳、nonce 和签名
+func buildLDPayResultParams(order *model.Order, clientSecret string) (map[string]string, error) {
+	params := map[string]string{
+		"pid":          order.ClientID,
+		"trade_no":     strconv.FormatUint(order.ID, 10),
+		"out_trade_no": order.MerchantOrderNo,
+		"type":         common.PayTypeLDPay,
+		"name":         order.OrderName,
+		"money":        order.Amount.Truncate(2).StringFixed(2),
+		"trade_status": "TRADE_SUCCESS",
+		"sign_type":    LDPaySignType,
+		"timestamp":    strconv.FormatInt(time.Now().Unix(), 10),
+		"nonce":        util.GenerateUniqueIDSimple(),
+	}
+
+	sign, err := GenerateLDPaySignature(params, clientSecret)
+	if err != nil {
+		return nil, err
+	}
+	params["sign"] = sign
+	return params, nil
+}
+
+// buildEventParams 构建 Webhook 事件通知参数，按订单的支付协议签名
+func buildEventParams(event model.WebhookEvent, order *model.Order, data map[string]string, clientSecret string) (map[string]string, error) {
+	params := map[string]string{
+		"event":        string(event),
+		"pid":          order.ClientID,
+		"trade_no":     strconv.FormatUint(order.ID, 10),
+		"out_trade_no": order.MerchantOrderNo,
+		"name":         order.OrderName,
+		"money":        order.Amount.Truncate(2).StringFixed(2),
+		"status":       string(order.Status),
+	}
+	for k, v := range data {
+		params[k] = v
+	}
+
+	if order.PaymentType == common.PayTypeLDPay {
+		params["type"] = common.PayTypeLDPay
+		params["sign_type"] = LDPaySignType
+		params["timestamp"] = strconv.FormatInt(time.Now().Unix(), 10)
+		params["nonce"] = util.GenerateUniqueIDSimple()
+
+		sign, err := GenerateLDPaySignature(params, clientSecret)
+		if err != nil {
+			return nil, err
+		}
+		params["sign"] = sign
+		return params, nil
+	}
+
+	params["type"] = common.PayTypeEPay
+	params["sign_type"] = "MD5"
+	params["sign"] = GenerateSignature(params, clientSecret)
+	return params, nil
+}
+
+// buildCallbackParams 构建易支付兼容格式的回调参数，支付成功沿用原有支付结果参数
+func buildCallbackParams(event model.WebhookEvent, order *model.Order, data map[string]string, clientSecret string) (map[string]string, error) {
+	if event == model.WebhookEventOrderPaid {
+		return buildResultParams(order, clientSecret)
+	}
+	return buildEventParams(event, order, data, clientSecret)
+}
+
+// WebhookOrderInfo JSON 格式回调中的订单信息
+type WebhookOrderInfo struct {
+	TradeNo       string     `json:"trade_no"`
+	OutTradeNo    string     `json:"out_trade_no"`
+	ClientID      string     `json:"pid"`
+	Type          string     `json:"type"`
+	Name          string     `json:"name"`
+	Money         string     `json:"money"`
+	RefundedMoney string     `json:"refunded_money"`
+	Status        string     `json:"status"`
+	CreatedAt     time.Time  `json:"created_at"`
+	TradeTime     *time.Time `json:"trade_time"`
+}
+
+// WebhookPayload JSON 格式回调请求体
+type WebhookPayload struct {
+	ID        string                 `json:"id"`
+	Event     model.WebhookEvent     `json:"event"`
+	CreatedAt int64                  `json:"created_at"`
+	Data      map[string]interface{} `json:"data"`
+}
+
+// buildWebhookPayload 构建 JSON 格式回调请求体，事件附加信息与订单信息一并放入 data
+func buildWebhookPayload(eventID string, event model.WebhookEvent, order *model.Order, data map[string]string) ([]byte, error) {
+	payloadData := make(map[string]interface{}, len(data)+1)
+	for k, v := range data {
+		payloadData[k] = v
+	}
+
+	var tradeTime *time.Time
+	if !order.TradeTime.IsZero() {
+		tradeTime = &order.TradeTime
+	}
+	payloadData["order"] = WebhookOrderInfo{
+		TradeNo:       strconv.FormatUint(order.ID, 10),
+		OutTradeNo:    order.MerchantOrderNo,
+		ClientID:      order.ClientID,
+		Type:          order.PaymentType,
+		Name:          order.OrderName,
+		Money:         order.Amount.Truncate(2).StringFixed(2),
+		RefundedMoney: order.RefundedAmount.Truncate(2).StringFixed(2),
+		Status:        string(order.Status),
+		CreatedAt:     order.CreatedAt,
+		TradeTime:     tradeTime,
+	}
+
+	return json.Marshal(WebhookPayload{
+		ID:        eventID,
+		Event:     event,
+		CreatedAt: time.Now().Unix(),
+		Data:      payloadData,
+	})
+}
+
+// GenerateWebhookSignature 生成 JSON 格式回调签名：HMAC-SHA256(secret, timestamp + "." + body) 的十六进制小写
+func GenerateWebhookSignature(timestamp string, body []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// buildResultParams 按订单的支付协议构建支付结果参数
+func buildResultParams(order *model.Order, clientSecret string) (map[string]string, error) {
+	if order.PaymentType == common.PayTypeLDPay {
+		return buildLDPayResultParams(order, clientSecret)
+	}
+	return buildEPayResultParams(order, clientSecret), nil
+}
+
+// VerifyLDPaySignature 验证 LDPay HMAC-SHA256 签名，并校验时间戳与 nonce 防止重放
+func VerifyLDPaySignature(c *gin.Context, apiKey *model.MerchantAPIKey) (*CreateOrderRequest, error) {
+	rawBody, err := c.GetRawData()
+	if err != nil {
+		return nil, err
+	}
+
+	// 签名基于原始请求体中的全部字段，要求所有字段均为字符串
+	var params map[string]string
+	if err := json.Unmarshal(rawBody, &params); err != nil {
+		return nil, errors.New(LDPayBodyInvalid)
+	}
+
+	var req LDPayRequest
+	if err := json.Unmarshal(rawBody, &req); err != nil {
+		return nil, errors.New(LDPayBodyInvalid)
+	}
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		return nil, err
+	}
+
+	if req.PayType != common.PayTypeLDPay {
+		return nil, errors.New(UnsupportedPayType)
+	}
+
+	amount, err := decimal.NewFromString(req.Money)
+	if err != nil {
+		return nil, errors.New(LDPayMoneyInvalid)
+	}
+
+	// 验证金额必须大于0
+	if amount.LessThanOrEqual(decimal.Zero) {
+		return nil, errors.New(common.AmountMustBeGreaterThanZero)
+	}
+
+	// 验证小数位数不超过2位
+	if amount.Exponent() < -2 {
+		return nil, errors.New(common.AmountDecimalPlacesExceeded)
+	}
+
+	// 验证时间戳在允许的偏差范围内
+	timestamp, err := strconv.ParseInt(req.Timestamp, 10, 64)
+	if err != nil {
+		return nil, errors.New(LDPayTimestampInvalid)
+	}
+	if offset := time.Since(time.Unix(timestamp, 0)); offset > LDPayTimestampTolerance || offset < -LDPayTimestampTolerance {
+		return nil, errors.New(LDPayTimestampInvalid)
+	}
+
+	if err := apiKey.GetByClientID(db.DB(c.Request.Context()), req.ClientID); err != nil {
+		return nil, err
+	}
+
+	expectedSign, err := GenerateLDPaySignature(params, apiKey.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	// 常量时间比较签名（防止时序攻击）
+	if !hmac.Equal([]byte(expectedSign), []byte(strings.ToLower(req.Sign))) {
+		return nil, errors.New(SignatureInvalid)
+	}
+
+	// 签名通过后占用 nonce，有效期覆盖时间戳允许的偏差范围
+	nonceKey := db.PrefixedKey(fmt.Sprintf(LDPayNonceKeyFormat, req.ClientID, req.Nonce))
+	ok, err := db.Redis.SetNX(c.Request.Context(), nonceKey, req.Timestamp, 2*LDPayTimestampTolerance).Result()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.New(LDPayNonceReused)
+	}
+
+	return &CreateOrderRequest{
+		OrderName:       req.OrderName,
+		MerchantOrderNo: req.MerchantOrderNo,
+		Amount:          amount,
+		Remark:          req.Remark,
+		PaymentType:     common.PayTypeLDPay,
+		NotifyURL:       req.NotifyURL,
+		ReturnURL:       req.ReturnURL,
+	}, nil
+}
@@ -0,0 +1,310 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package payment
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hibiken/asynq"
+	"github.com/linux-do/credit/internal/apps/merchant"
+	"github.com/linux-do/credit/internal/db"
+	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/task"
+	"github.com/linux-do/credit/internal/task/scheduler"
+	"github.com/linux-do/credit/internal/util"
+	"gorm.io/gorm"
+)
+
+// ListWebhookDeliveriesRequest 回调投递记录查询请求
+type ListWebhookDeliveriesRequest struct {
+	Page     int    `form:"page" binding:"min=1"`
+	PageSize int    `form:"page_size" binding:"min=1,max=100"`
+	OrderID  uint64 `form:"order_id"`
+	Event    string `form:"event"`
+	Success  *bool  `form:"success"`
+}
+
+// ListWebhookDeliveriesResponse 回调投递记录查询响应
+type ListWebhookDeliveriesResponse struct {
+	Total      int64                   `json:"total"`
+	Page       int                     `json:"page"`
+	PageSize   int                     `json:"page_size"`
+	Deliveries []model.WebhookDelivery `json:"deliveries"`
+}
+
+// RedeliverWebhookRequest 手动重发回调请求
+type RedeliverWebhookRequest struct {
+	OrderID uint64 `json:"order_id" binding:"required"`
+}
+
+// ListWebhookDeliveries 获取商户回调投递记录
+// @Tags merchant
+// @Produce json
+// @Param id path uint64 true "API Key ID"
+// @Param request query ListWebhookDeliveriesRequest true "request query"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/merchant/api-keys/{id}/webhook-deliveries [get]
+func ListWebhookDeliveries(c *gin.Context) {
+	req := ListWebhookDeliveriesRequest{Page: 1, PageSize: 20}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, merchant.APIKeyObjKey)
+
+	query := db.DB(c.Request.Context()).Model(&model.WebhookDelivery{}).Where("client_id = ?", apiKey.ClientID)
+	if req.OrderID != 0 {
+		query = query.Where("order_id = ?", req.OrderID)
+	}
+	if req.Event != "" {
+		query = query.Where("event = ?", req.Event)
+	}
+	if req.Success != nil {
+		query = query.Where("success = ?", *req.Success)
+	}
+
+	response := ListWebhookDeliveriesResponse{
+		Page:       req.Page,
+		PageSize:   req.PageSize,
+		Deliveries: []model.WebhookDelivery{},
+	}
+	if err := query.Count(&response.Total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	offset := (req.Page - 1) * req.PageSize
+	if err := query.Order("created_at DESC").Offset(offset).Limit(req.PageSize).Find(&response.Deliveries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OK(response))
+}
+
+// RedeliverWebhook 手动重发订单支付回调，同步返回本次投递结果
+// @Tags merchant
+// @Accept json
+// @Produce json
+// @Param id path uint64 true "API Key ID"
+// @Param request body RedeliverWebhookRequest true "request body"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/merchant/api-keys/{id}/webhook-deliveries [post]
+func RedeliverWebhook(c *gin.Context) {
+	var req RedeliverWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, merchant.APIKeyObjKey)
+
+	var order model.Order
+	if err := db.DB(c.Request.Context()).
+		Where("id = ? AND client_id = ?", req.OrderID, apiKey.ClientID).
+		First(&order).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, util.Err(OrderNotFound))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	if !slices.Contains(model.PaidOrderStatuses, order.Status) {
+		c.JSON(http.StatusBadRequest, util.Err(OrderNotNotifiable))
+		return
+	}
+
+	// 沿用该订单最近一次支付成功通知的事件 ID，便于商户去重
+	eventID := "evt_" + util.GenerateUniqueIDSimple()
+	var lastDelivery model.WebhookDelivery
+	if err := db.DB(c.Request.Context()).
+		Where("order_id = ? AND event = ? AND event_id <> ''", order.ID, model.WebhookEventOrderPaid).
+		Order("created_at DESC").
+		First(&lastDelivery).Error; err == nil {
+		eventID = lastDelivery.EventID
+	}
+
+	// 投递失败同样返回投递记录，由商户根据记录排查原因
+	delivery, err := deliverWebhook(c.Request.Context(), model.WebhookEventOrderPaid, eventID, &order, apiKey, nil, 0, true)
+	if delivery == nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OK(delivery))
+}
+
+// ListNotifyFailedOrdersRequest 回调失败订单查询请求
+type ListNotifyFailedOrdersRequest struct {
+	Page     int `form:"page" binding:"min=1"`
+	PageSize int `form:"page_size" binding:"min=1,max=100"`
+}
+
+// NotifyFailedOrder 回调失败订单，附带最近一次投递的失败原因
+type NotifyFailedOrder struct {
+	model.Order
+	LastError string `json:"last_error"`
+}
+
+// ListNotifyFailedOrdersResponse 回调失败订单查询响应
+type ListNotifyFailedOrdersResponse struct {
+	Total    int64               `json:"total"`
+	Page     int                 `json:"page"`
+	PageSize int                 `json:"page_size"`
+	Orders   []NotifyFailedOrder `json:"orders"`
+}
+
+// RetryNotifyFailedOrdersResponse 批量重发回调响应
+type RetryNotifyFailedOrdersResponse struct {
+	Count           int      `json:"count"`
+	SkippedOrderIDs []uint64 `json:"skipped_order_ids"`
+}
+
+// ListNotifyFailedOrders 获取重试耗尽后仍回调失败的订单
+// @Tags merchant
+// @Produce json
+// @Param id path uint64 true "API Key ID"
+// @Param request query ListNotifyFailedOrdersRequest true "request query"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/merchant/api-keys/{id}/notify-failed-orders [get]
+func ListNotifyFailedOrders(c *gin.Context) {
+	req := ListNotifyFailedOrdersRequest{Page: 1, PageSize: 20}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, merchant.APIKeyObjKey)
+
+	query := db.DB(c.Request.Context()).Model(&model.Order{}).
+		Where("orders.client_id = ? AND orders.notify_failed", apiKey.ClientID)
+
+	response := ListNotifyFailedOrdersResponse{
+		Page:     req.Page,
+		PageSize: req.PageSize,
+		Orders:   []NotifyFailedOrder{},
+	}
+	if err := query.Count(&response.Total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	offset := (req.Page - 1) * req.PageSize
+	if err := query.
+		Select("orders.*, (SELECT d.error FROM webhook_deliveries d WHERE d.order_id = orders.id AND d.event = ? ORDER BY d.created_at DESC LIMIT 1) AS last_error", model.WebhookEventOrderPaid).
+		Order("orders.created_at DESC").
+		Offset(offset).
+		Limit(req.PageSize).
+		Find(&response.Orders).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OK(response))
+}
+
+// RetryNotifyFailedOrders 批量重新下发回调失败订单的支付回调任务，回调成功后自动清除失败标记
+// @Tags merchant
+// @Produce json
+// @Param id path uint64 true "API Key ID"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/merchant/api-keys/{id}/notify-failed-orders/retry [post]
+func RetryNotifyFailedOrders(c *gin.Context) {
+	apiKey, _ := util.GetFromContext[*model.MerchantAPIKey](c, merchant.APIKeyObjKey)
+
+	// 以回调失败标记筛选，已发生退款或争议的订单同样需要补发支付回调
+	var orderIDs []uint64
+	if err := db.DB(c.Request.Context()).Model(&model.Order{}).
+		Where("client_id = ? AND notify_failed", apiKey.ClientID).
+		Order("created_at ASC").
+		Limit(NotifyRetryBatchLimit).
+		Pluck("id", &orderIDs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	response := RetryNotifyFailedOrdersResponse{SkippedOrderIDs: []uint64{}}
+	for _, orderID := range orderIDs {
+		enqueued, err := enqueueNotifyRetry(apiKey.ClientID, orderID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+			return
+		}
+		if !enqueued {
+			response.SkippedOrderIDs = append(response.SkippedOrderIDs, orderID)
+			continue
+		}
+		response.Count++
+	}
+
+	c.JSON(http.StatusOK, util.OK(response))
+}
+
+// enqueueNotifyRetry 下发单笔订单的支付回调重发任务，任务 ID 以订单维度去重
+// 同 ID 的任务已重试耗尽被归档时先删除再重新下发；仍在队列中执行时跳过，返回 false
+func enqueueNotifyRetry(clientID string, orderID uint64) (bool, error) {
+	notifyPayload, _ := json.Marshal(map[string]interface{}{
+		"order_id":  orderID,
+		"client_id": clientID,
+	})
+	taskID := fmt.Sprintf(NotifyRetryTaskIDFormat, orderID)
+	enqueue := func() error {
+		_, err := scheduler.AsynqClient.Enqueue(
+			asynq.NewTask(task.MerchantPaymentNotifyTask, notifyPayload),
+			asynq.Queue(task.QueueWebhook),
+			asynq.MaxRetry(10),
+			asynq.Timeout(30*time.Second),
+			asynq.TaskID(taskID),
+		)
+		return err
+	}
+
+	err := enqueue()
+	if !errors.Is(err, asynq.ErrTaskIDConflict) {
+		return err == nil, err
+	}
+
+	info, err := scheduler.AsynqInspector.GetTaskInfo(task.QueueWebhook, taskID)
+	switch {
+	case errors.Is(err, asynq.ErrTaskNotFound):
+		// 任务在两次操作之间已执行完毕并被清理，直接重新下发
+	case err != nil:
+		return false, err
+	case info.State != asynq.TaskStateArchived && info.State != asynq.TaskStateCompleted:
+		return false, nil
+	default:
+		if err := scheduler.AsynqInspector.DeleteTask(task.QueueWebhook, taskID); err != nil && !errors.Is(err, asynq.ErrTaskNotFound) {
+			return false, err
+		}
+	}
+	if err := enqueue(); err != nil {
+		if errors.Is(err, asynq.ErrTaskIDConflict) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
@@ -17,5 +17,15 @@ limitations under the License.
 package user
 
 const (
-	EncryptPayKeyFailed = "加密支付密码失败"
+	HashPayKeyFailed     = "生成支付密码哈希失败"
+	PayKeyReauthRequired = "修改支付密码需验证原支付密码或重新登录"
+)
+
+const (
+	PayKeyNotSet            = "请先设置支付密码"
+	TOTPAlreadyEnabled      = "两步验证已开启"
+	TOTPNotEnabled          = "两步验证未开启"
+	TOTPNotSetup            = "请先生成两步验证密钥"
+	EncryptTOTPSecretFailed = "加密两步验证密钥失败"
+	TOTPThresholdNegative   = "两步验证阈值不能为负数"
 )
@@ -17,21 +17,30 @@ limitations under the License.
 package user
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/linux-do/credit/internal/apps/oauth"
+	"github.com/linux-do/credit/internal/common"
+	"github.com/linux-do/credit/internal/config"
 	"github.com/linux-do/credit/internal/db"
+	"github.com/linux-do/credit/internal/logger"
 	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/service"
 	"github.com/linux-do/credit/internal/util"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
 )
 
 // UpdatePayKeyRequest 更新支付密钥请求
 type UpdatePayKeyRequest struct {
-	PayKey string `json:"pay_key" binding:"required,max=6"`
+	PayKey    string `json:"pay_key" binding:"required,max=6"`
+	OldPayKey string `json:"old_pay_key" binding:"omitempty,max=6"`
 }
 
 // UpdatePayKey 更新用户支付密钥
+// 已设置支付密钥的用户需提供原支付密钥，或在最近登录后的时间窗口内操作
 // @Tags user
 // @Accept json
 // @Produce json
@@ -47,16 +56,386 @@ func UpdatePayKey(c *gin.Context) {
 
 	user, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
 
-	encryptedPayKey, err := util.Encrypt(user.SignKey, req.PayKey)
+	if user.PayKey != "" {
+		if req.OldPayKey != "" {
+			if err := service.VerifyPayKey(c.Request.Context(), user, req.OldPayKey); err != nil {
+				c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+				return
+			}
+		} else if !service.PayKeyRecentlyAuthenticated(oauth.GetLoginAtFromContext(c)) {
+			c.JSON(http.StatusForbidden, util.Err(PayKeyReauthRequired))
+			return
+		}
+	}
+
+	hashedPayKey, err := util.HashSecret(req.PayKey)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(HashPayKeyFailed))
+		return
+	}
+
+	if err := db.DB(c.Request.Context()).
+		Model(&model.User{}).
+		Where("id = ?", user.ID).
+		Update("pay_key", hashedPayKey).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	// 修改成功后清除错误次数和锁定状态
+	if err := service.UnlockPayKey(c.Request.Context(), user.ID); err != nil {
+		logger.ErrorF(c.Request.Context(), "[PayKey] 修改支付密码后清除锁定状态失败: user_id=%d, error=%v", user.ID, err)
+	}
+
+	c.JSON(http.StatusOK, util.OKNil())
+}
+
+// TOTPStatusResponse 两步验证状态
+type TOTPStatusResponse struct {
+	Enabled                bool             `json:"enabled"`
+	Threshold              *decimal.Decimal `json:"threshold"`
+	SystemThreshold        decimal.Decimal  `json:"system_threshold"`
+	EffectiveThreshold     decimal.Decimal  `json:"effective_threshold"`
+	RecoveryCodesRemaining int64            `json:"recovery_codes_remaining"`
+}
+
+// SetupTOTPRequest 生成两步验证密钥请求
+type SetupTOTPRequest struct {
+	PayKey string `json:"pay_key" binding:"required,max=6"`
+}
+
+// SetupTOTPResponse 生成两步验证密钥响应
+type SetupTOTPResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+// EnableTOTPRequest 开启两步验证请求
+type EnableTOTPRequest struct {
+	Code string `json:"code" binding:"required,len=6"`
+}
+
+// DisableTOTPRequest 关闭两步验证请求
+type DisableTOTPRequest struct {
+	PayKey string `json:"pay_key" binding:"required,max=6"`
+	Code   string `json:"code" binding:"required,max=16"`
+}
+
+// RegenerateTOTPRecoveryCodesRequest 重新生成恢复码请求
+type RegenerateTOTPRecoveryCodesRequest struct {
+	Code string `json:"code" binding:"required,max=16"`
+}
+
+// TOTPRecoveryCodesResponse 恢复码响应，明文仅返回一次
+type TOTPRecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// UpdateTOTPThresholdRequest 设置两步验证金额阈值请求，threshold 为空表示使用系统阈值
+type UpdateTOTPThresholdRequest struct {
+	Threshold *decimal.Decimal `json:"threshold"`
+	Code      string           `json:"code" binding:"required,max=16"`
+}
+
+// GetTOTPStatus 查询两步验证状态
+// @Tags user
+// @Produce json
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/user/totp [get]
+func GetTOTPStatus(c *gin.Context) {
+	user, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
+
+	systemThreshold, err := model.GetTOTPThreshold(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	effectiveThreshold, err := service.TOTPThreshold(c.Request.Context(), user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	var remaining int64
+	if err := db.DB(c.Request.Context()).
+		Model(&model.TOTPRecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", user.ID).
+		Count(&remaining).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OK(TOTPStatusResponse{
+		Enabled:                user.TOTPEnabled,
+		Threshold:              user.TOTPThreshold,
+		SystemThreshold:        systemThreshold,
+		EffectiveThreshold:     effectiveThreshold,
+		RecoveryCodesRemaining: remaining,
+	}))
+}
+
+// SetupTOTP 生成两步验证密钥，需在验证器中添加后调用开启接口确认
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param request body SetupTOTPRequest true "request body"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/user/totp/setup [post]
+func SetupTOTP(c *gin.Context) {
+	var req SetupTOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	user, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
+
+	if user.PayKey == "" {
+		c.JSON(http.StatusBadRequest, util.Err(PayKeyNotSet))
+		return
+	}
+	if user.TOTPEnabled {
+		c.JSON(http.StatusBadRequest, util.Err(TOTPAlreadyEnabled))
+		return
+	}
+
+	if err := service.VerifyPayKey(c.Request.Context(), user, req.PayKey); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	secret, err := util.GenerateTOTPSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	encryptedSecret, err := service.EncryptTOTPSecret(secret)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, util.Err(EncryptPayKeyFailed))
+		if errors.Is(err, service.ErrTOTPUnavailable) {
+			c.JSON(http.StatusBadRequest, util.Err(common.TOTPUnavailable))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, util.Err(EncryptTOTPSecretFailed))
+		return
+	}
+
+	if err := db.DB(c.Request.Context()).
+		Model(&model.User{}).
+		Where("id = ? AND totp_enabled = ?", user.ID, false).
+		Updates(map[string]interface{}{
+			"totp_secret":    encryptedSecret,
+			"totp_last_step": 0,
+		}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OK(SetupTOTPResponse{
+		Secret: secret,
+		URI:    util.TOTPProvisioningURI(config.Config.App.AppName, user.Username, secret),
+	}))
+}
+
+// EnableTOTP 校验验证器生成的验证码后开启两步验证，并返回恢复码
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param request body EnableTOTPRequest true "request body"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/user/totp/enable [post]
+func EnableTOTP(c *gin.Context) {
+	var req EnableTOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	user, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
+
+	if user.TOTPEnabled {
+		c.JSON(http.StatusBadRequest, util.Err(TOTPAlreadyEnabled))
+		return
+	}
+	if user.TOTPSecret == "" {
+		c.JSON(http.StatusBadRequest, util.Err(TOTPNotSetup))
+		return
+	}
+
+	if err := service.VerifyTOTPCode(c.Request.Context(), db.DB(c.Request.Context()), user, req.Code); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	var recoveryCodes []string
+	if err := db.DB(c.Request.Context()).Transaction(
+		func(tx *gorm.DB) error {
+			result := tx.Model(&model.User{}).
+				Where("id = ? AND totp_enabled = ?", user.ID, false).
+				Update("totp_enabled", true)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return errors.New(TOTPAlreadyEnabled)
+			}
+
+			var err error
+			recoveryCodes, err = service.ResetTOTPRecoveryCodes(tx, user.ID)
+			return err
+		},
+	); err != nil {
+		if err.Error() == TOTPAlreadyEnabled {
+			c.JSON(http.StatusBadRequest, util.Err(TOTPAlreadyEnabled))
+		} else {
+			c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OK(TOTPRecoveryCodesResponse{RecoveryCodes: recoveryCodes}))
+}
+
+// DisableTOTP 关闭两步验证，需同时验证支付密码和两步验证码（或恢复码）
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param request body DisableTOTPRequest true "request body"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/user/totp/disable [post]
+func DisableTOTP(c *gin.Context) {
+	var req DisableTOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	user, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
+
+	if !user.TOTPEnabled {
+		c.JSON(http.StatusBadRequest, util.Err(TOTPNotEnabled))
+		return
+	}
+
+	if err := service.VerifyPayKey(c.Request.Context(), user, req.PayKey); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+	if err := service.VerifyTOTPCode(c.Request.Context(), db.DB(c.Request.Context()), user, req.Code); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	if err := db.DB(c.Request.Context()).Transaction(
+		func(tx *gorm.DB) error {
+			if err := tx.Model(&model.User{}).
+				Where("id = ?", user.ID).
+				Updates(map[string]interface{}{
+					"totp_secret":    "",
+					"totp_enabled":   false,
+					"totp_last_step": 0,
+					"totp_threshold": gorm.Expr("NULL"),
+				}).Error; err != nil {
+				return err
+			}
+			return tx.Where("user_id = ?", user.ID).Delete(&model.TOTPRecoveryCode{}).Error
+		},
+	); err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, util.OKNil())
+}
+
+// RegenerateTOTPRecoveryCodes 重新生成恢复码，原有恢复码全部作废
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param request body RegenerateTOTPRecoveryCodesRequest true "request body"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/user/totp/recovery-codes [post]
+func RegenerateTOTPRecoveryCodes(c *gin.Context) {
+	var req RegenerateTOTPRecoveryCodesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	user, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
+
+	if !user.TOTPEnabled {
+		c.JSON(http.StatusBadRequest, util.Err(TOTPNotEnabled))
+		return
+	}
+
+	if err := service.VerifyTOTPCode(c.Request.Context(), db.DB(c.Request.Context()), user, req.Code); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	var recoveryCodes []string
+	if err := db.DB(c.Request.Context()).Transaction(
+		func(tx *gorm.DB) error {
+			var err error
+			recoveryCodes, err = service.ResetTOTPRecoveryCodes(tx, user.ID)
+			return err
+		},
+	); err != nil {
+		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
 		return
 	}
 
+	c.JSON(http.StatusOK, util.OK(TOTPRecoveryCodesResponse{RecoveryCodes: recoveryCodes}))
+}
+
+// UpdateTOTPThreshold 设置两步验证金额阈值，高于系统阈值时以系统阈值为准
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param request body UpdateTOTPThresholdRequest true "request body"
+// @Success 200 {object} util.ResponseAny
+// @Router /api/v1/user/totp/threshold [put]
+func UpdateTOTPThreshold(c *gin.Context) {
+	var req UpdateTOTPThresholdRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	if req.Threshold != nil {
+		if req.Threshold.IsNegative() {
+			c.JSON(http.StatusBadRequest, util.Err(TOTPThresholdNegative))
+			return
+		}
+		if req.Threshold.Exponent() < -2 {
+			c.JSON(http.StatusBadRequest, util.Err(common.AmountDecimalPlacesExceeded))
+			return
+		}
+	}
+
+	user, _ := util.GetFromContext[*model.User](c, oauth.UserObjKey)
+
+	if !user.TOTPEnabled {
+		c.JSON(http.StatusBadRequest, util.Err(TOTPNotEnabled))
+		return
+	}
+
+	if err := service.VerifyTOTPCode(c.Request.Context(), db.DB(c.Request.Context()), user, req.Code); err != nil {
+		c.JSON(http.StatusBadRequest, util.Err(err.Error()))
+		return
+	}
+
+	var threshold interface{} = gorm.Expr("NULL")
+	if req.Threshold != nil {
+		threshold = *req.Threshold
+	}
+
 	if err := db.DB(c.Request.Context()).
 		Model(&model.User{}).
 		Where("id = ?", user.ID).
-		Update("pay_key", encryptedPayKey).Error; err != nil {
+		Update("totp_threshold", threshold).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, util.Err(err.Error()))
 		return
 	}
@@ -163,7 +163,7 @@ func batchUpdateUserScores(ctx context.Context, userScores []model.LeaderboardUs
 			oldCommunityBalance := user.CommunityBalance
 			diff := newCommunityBalance.Sub(oldCommunityBalance)
 
-			createOrder := func(amount decimal.Decimal, remark string) error {
+			createOrder := func(amount decimal.Decimal, remark string) (*model.Order, error) {
 				order := model.Order{
 					OrderName:   "社区积分更新",
 					PayerUserID: 0,
@@ -176,9 +176,9 @@ func batchUpdateUserScores(ctx context.Context, userScores []model.LeaderboardUs
 					ExpiresAt:   now,
 				}
 				if err = tx.Create(&order).Error; err != nil {
-					return fmt.Errorf("创建用户[%s]订单失败: %w", user.Username, err)
+					return nil, fmt.Errorf("创建用户[%s]订单失败: %w", user.Username, err)
 				}
-				return nil
+				return &order, nil
 			}
 
 			if user.CommunityBalance.IsZero() && user.TotalCommunity.IsZero() {
@@ -194,7 +194,7 @@ func batchUpdateUserScores(ctx context.Context, userScores []model.LeaderboardUs
 			// 积分未变化
 			if diff.IsZero() {
 				remark := fmt.Sprintf("社区积分从 %s 更新到 %s，变化 %s", oldCommunityBalance.String(), newCommunityBalance.String(), diff.String())
-				if err = createOrder(decimal.Zero, remark); err != nil {
+				if _, err = createOrder(decimal.Zero, remark); err != nil {
 					return err
 				}
 				continue
@@ -211,7 +211,7 @@ func batchUpdateUserScores(ctx context.Context, userScores []model.LeaderboardUs
 					}
 					remark := fmt.Sprintf("社区积分从 %s 更新到 %s，变化 %s（保护期内，跳过扣分）",
 						oldCommunityBalance.String(), newCommunityBalance.String(), diff.String())
-					if err = createOrder(decimal.Zero, remark); err != nil {
+					if _, err = createOrder(decimal.Zero, remark); err != nil {
 						return err
 					}
 					logger.InfoF(ctx, "用户[%s]在保护期内，积分下降%s，跳过扣分", user.Username, diff.Abs().String())
@@ -219,21 +219,35 @@ func batchUpdateUserScores(ctx context.Context, userScores []model.LeaderboardUs
 				}
 			}
 
-			// 更新用户积分
-			if err = tx.Model(&user).UpdateColumns(map[string]interface{}{
-				"community_balance": newCommunityBalance,
-				"total_community":   gorm.Expr("total_community + ?", diff),
-				"total_receive":     gorm.Expr("total_receive + ?", diff),
-				"available_balance": gorm.Expr("available_balance + ?", diff),
-			}).Error; err != nil {
-				return fmt.Errorf("更新用户[%s]积分失败: %w", user.Username, err)
-			}
-
 			remark := fmt.Sprintf("社区积分从 %s 更新到 %s，变化 %s",
 				oldCommunityBalance.String(), newCommunityBalance.String(), diff.String())
-			if err = createOrder(diff, remark); err != nil {
+			order, err := createOrder(diff, remark)
+			if err != nil {
 				return err
 			}
+
+			// 记账：社区积分由社区发行账户拨付，积分下降时回收到发行账户
+			if err = model.PostLedgerEntry(tx, &model.LedgerEntry{
+				Type:    model.LedgerEntryCommunity,
+				OrderID: order.ID,
+			}, []model.LedgerLeg{
+				{
+					AccountType: model.LedgerAccountUser,
+					UserID:      user.ID,
+					Amount:      diff,
+					UserColumns: map[string]interface{}{
+						"community_balance": newCommunityBalance,
+						"total_community":   gorm.Expr("total_community + ?", diff),
+						"total_receive":     gorm.Expr("total_receive + ?", diff),
+					},
+				},
+				{
+					AccountType: model.LedgerAccountCommunityMint,
+					Amount:      diff.Neg(),
+				},
+			}); err != nil {
+				return fmt.Errorf("更新用户[%s]积分失败: %w", user.Username, err)
+			}
 		}
 		return nil
 	})
@@ -23,7 +23,17 @@ const (
 	InsufficientBalance         = "余额不足"
 	DailyLimitExceeded          = "已超过每日限额"
 	PayKeyIncorrect             = "支付密钥错误"
+	PayKeyLocked                = "支付密钥错误次数过多，已暂时锁定，请稍后再试"
 	CannotPaySelf               = "不能给自己付款"
+	TOTPRequired                = "支付金额超过两步验证阈值，请输入两步验证码"
+	TOTPIncorrect               = "两步验证码错误"
+	TOTPUnavailable             = "系统未配置两步验证加密密钥，暂不可用"
+)
+
+const (
+	LedgerEntryUnbalanced = "记账凭证借贷不平衡"
+	LedgerEntryImmutable  = "记账凭证不可修改"
+	LedgerUserNotFound    = "记账用户不存在"
 )
 
 const (
@@ -17,9 +17,13 @@ limitations under the License.
 package config
 
 import (
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"log"
+	"net/netip"
 	"os"
+	"strings"
 
 	"github.com/spf13/viper"
 )
@@ -48,6 +52,15 @@ func init() {
 		log.Fatalf("[Config] parse config failed: %v\n", err)
 	}
 
+	// 校验回调地址段配置，避免无效的黑白名单被静默忽略
+	if err := validateWebhookCIDRs(c.Webhook.AllowedCIDRs, c.Webhook.DeniedCIDRs); err != nil {
+		log.Fatalf("[Config] invalid webhook config: %v\n", err)
+	}
+
+	if err := validateTOTPEncryptionKey(c.TOTP.EncryptionKey); err != nil {
+		log.Fatalf("[Config] invalid totp config: %v\n", err)
+	}
+
 	// 设置全局配置
 	Config = &c
 
@@ -64,3 +77,31 @@ func printConfig(c *configModel) {
 	}
 	log.Printf("[Config] loaded configuration:\n%s\n", string(configJSON))
 }
+
+// validateWebhookCIDRs 校验回调地址段配置，每项须为 CIDR 或单个 IP
+func validateWebhookCIDRs(lists ...[]string) error {
+	for _, entries := range lists {
+		for _, entry := range entries {
+			entry = strings.TrimSpace(entry)
+			if _, err := netip.ParsePrefix(entry); err == nil {
+				continue
+			}
+			if _, err := netip.ParseAddr(entry); err == nil {
+				continue
+			}
+			return fmt.Errorf("invalid cidr %q", entry)
+		}
+	}
+	return nil
+}
+
+// validateTOTPEncryptionKey 校验两步验证密钥的加密密钥，未配置时两步验证不可用
+func validateTOTPEncryptionKey(key string) error {
+	if key == "" {
+		return nil
+	}
+	if raw, err := hex.DecodeString(key); err != nil || len(raw) != 32 {
+		return fmt.Errorf("encryption_key must be 64 hex characters")
+	}
+	return nil
+}
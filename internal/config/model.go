@@ -17,16 +17,22 @@ limitations under the License.
 package config
 
 type configModel struct {
-	App        appConfig        `mapstructure:"app"`
-	OAuth2     OAuth2Config     `mapstructure:"oauth2"`
-	Database   databaseConfig   `mapstructure:"database"`
-	Redis      redisConfig      `mapstructure:"redis"`
-	Log        logConfig        `mapstructure:"log"`
-	Scheduler  schedulerConfig  `mapstructure:"scheduler"`
-	Worker     workerConfig     `mapstructure:"worker"`
-	ClickHouse clickHouseConfig `mapstructure:"clickhouse"`
-	LinuxDo    linuxDoConfig    `mapstructure:"linuxdo"`
-	Otel       otelConfig       `mapstructure:"otel"`
+	App         appConfig         `mapstructure:"app"`
+	OAuth2      OAuth2Config      `mapstructure:"oauth2"`
+	Database    databaseConfig    `mapstructure:"database"`
+	Redis       redisConfig       `mapstructure:"redis"`
+	Log         logConfig         `mapstructure:"log"`
+	Scheduler   schedulerConfig   `mapstructure:"scheduler"`
+	Worker      workerConfig      `mapstructure:"worker"`
+	Webhook     webhookConfig     `mapstructure:"webhook"`
+	Outbox      outboxConfig      `mapstructure:"outbox"`
+	Reconcile   reconcileConfig   `mapstructure:"reconcile"`
+	Idempotency idempotencyConfig `mapstructure:"idempotency"`
+	PayKey      PayKeyConfig      `mapstructure:"pay_key"`
+	TOTP        totpConfig        `mapstructure:"totp"`
+	ClickHouse  clickHouseConfig  `mapstructure:"clickhouse"`
+	LinuxDo     linuxDoConfig     `mapstructure:"linuxdo"`
+	Otel        otelConfig        `mapstructure:"otel"`
 }
 
 // appConfig 应用基本配置
@@ -35,6 +41,7 @@ type appConfig struct {
 	Env                     string `mapstructure:"env"`
 	Addr                    string `mapstructure:"addr"`
 	NodeID                  int64  `mapstructure:"node_id"`
+	NodeIDAuto              bool   `mapstructure:"node_id_auto"`
 	APIPrefix               string `mapstructure:"api_prefix"`
 	GracefulShutdownTimeout int    `mapstructure:"graceful_shutdown_timeout"`
 	FrontendPayURL          string `mapstructure:"frontend_pay_url"`
@@ -140,6 +147,10 @@ type schedulerConfig struct {
 	DisputeAutoRefundDispatchIntervalSeconds int    `mapstructure:"dispute_auto_refund_dispatch_interval_seconds"`
 	AutoRefundExpiredDisputesTaskCron        string `mapstructure:"auto_refund_expired_disputes_task_cron"`
 	SyncOrdersToClickHouseTaskCron           string `mapstructure:"sync_orders_to_clickhouse_task_cron"`
+	ExpirePendingOrdersTaskCron              string `mapstructure:"expire_pending_orders_task_cron"`
+	ExpirePendingOrdersBatchSize             int    `mapstructure:"expire_pending_orders_batch_size"`
+	ReconcileUserBalancesTaskCron            string `mapstructure:"reconcile_user_balances_task_cron"`
+	CleanupIdempotencyKeysTaskCron           string `mapstructure:"cleanup_idempotency_keys_task_cron"`
 }
 
 // workerConfig 工作配置
@@ -162,6 +173,49 @@ type RateLimitConfig struct {
 	Period int `mapstructure:"period"` // 时间周期（秒）
 }
 
+// webhookConfig 商户回调请求配置
+type webhookConfig struct {
+	MaxResponseBytes int64    `mapstructure:"max_response_bytes"` // 商户响应体最大读取字节数
+	MaxRedirects     int      `mapstructure:"max_redirects"`      // 最大跟随重定向次数，0 表示不跟随
+	AllowedHosts     []string `mapstructure:"allowed_hosts"`      // 非空时仅允许回调列表中的域名，支持 *.example.com
+	DeniedHosts      []string `mapstructure:"denied_hosts"`       // 禁止回调的域名，支持 *.example.com
+	AllowedCIDRs     []string `mapstructure:"allowed_cidrs"`      // 放行的地址段，可用于放行默认禁止的内网地址
+	DeniedCIDRs      []string `mapstructure:"denied_cidrs"`       // 额外禁止的地址段
+}
+
+// reconcileConfig 余额对账配置
+type reconcileConfig struct {
+	BatchSize     int  `mapstructure:"batch_size"`     // 单批对账的用户数
+	RepairEnabled bool `mapstructure:"repair_enabled"` // 是否允许管理员修复对账差异
+}
+
+// idempotencyConfig 幂等键配置
+type idempotencyConfig struct {
+	TTLHours int `mapstructure:"ttl_hours"` // 幂等键的有效时长（小时），过期后可复用
+}
+
+// PayKeyConfig 支付密码安全配置
+type PayKeyConfig struct {
+	MaxAttempts          int `mapstructure:"max_attempts"`           // 统计窗口内连续错误达到该次数后锁定
+	FailureWindowMinutes int `mapstructure:"failure_window_minutes"` // 错误次数统计窗口（分钟）
+	BaseLockMinutes      int `mapstructure:"base_lock_minutes"`      // 首次锁定时长（分钟），之后每次锁定时长翻倍
+	MaxLockMinutes       int `mapstructure:"max_lock_minutes"`       // 最长锁定时长（分钟）
+	LockResetHours       int `mapstructure:"lock_reset_hours"`       // 锁定解除后多久内未再次锁定则重置锁定梯度（小时）
+	ReauthMinutes        int `mapstructure:"reauth_minutes"`         // 登录后多久内修改支付密码无需验证原密码（分钟）
+}
+
+// totpConfig 两步验证配置
+type totpConfig struct {
+	EncryptionKey string `mapstructure:"encryption_key" json:"-"` // 加密两步验证密钥的 AES-256 密钥（64 位 hex），仅保存在配置中
+}
+
+// outboxConfig 事务发件箱中继配置
+type outboxConfig struct {
+	PollIntervalMs int `mapstructure:"poll_interval_ms"` // 轮询待发布消息的间隔（毫秒）
+	BatchSize      int `mapstructure:"batch_size"`       // 单批投递的最大消息数
+	RetentionHours int `mapstructure:"retention_hours"`  // 已发布消息的保留时长（小时）
+}
+
 // linuxDoConfig
 type linuxDoConfig struct {
 	ApiKey string `mapstructure:"api_key"`
@@ -0,0 +1,144 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package idgen
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/linux-do/credit/internal/db"
+)
+
+const (
+	// nodeLeaseKeyFormat 节点 ID 租约 Key 格式
+	nodeLeaseKeyFormat = "snowflake:node:%d"
+	// nodeLeaseTTL 节点 ID 租约有效期
+	nodeLeaseTTL = 30 * time.Second
+	// nodeLeaseRenewInterval 节点 ID 租约心跳间隔
+	nodeLeaseRenewInterval = 10 * time.Second
+)
+
+// maxNodeID 节点 ID 最大值
+var maxNodeID = int64(-1 ^ (-1 << snowflake.NodeBits))
+
+// leaseNodeID 获取节点 ID 租约，从随机位置开始依次尝试所有节点 ID，跳过已被其他实例占用的节点 ID
+func leaseNodeID() (int64, error) {
+	if db.Redis == nil {
+		return 0, fmt.Errorf("redis is not initialized")
+	}
+
+	ctx := context.Background()
+	holder := db.LeaseHolderID()
+
+	start := rand.Int63n(maxNodeID + 1)
+	for i := int64(0); i <= maxNodeID; i++ {
+		nodeID := (start + i) % (maxNodeID + 1)
+		acquired, err := db.AcquireLease(ctx, fmt.Sprintf(nodeLeaseKeyFormat, nodeID), holder, nodeLeaseTTL)
+		if err != nil {
+			return 0, err
+		}
+		if acquired {
+			return nodeID, nil
+		}
+	}
+	return 0, fmt.Errorf("no available node ID in 0-%d", maxNodeID)
+}
+
+// leaseStaticNodeID 为配置指定的节点 ID 获取租约，防止多个实例误配相同节点 ID
+// 已被占用时等待一个租约有效期，以便进程重启后旧租约自然过期；期间仍被续期说明存在其他存活实例，返回错误
+func leaseStaticNodeID(nodeID int64) error {
+	if db.Redis == nil {
+		return fmt.Errorf("redis is not initialized")
+	}
+	if nodeID < 0 || nodeID > maxNodeID {
+		return fmt.Errorf("node ID must be between 0 and %d", maxNodeID)
+	}
+
+	ctx := context.Background()
+	key := fmt.Sprintf(nodeLeaseKeyFormat, nodeID)
+	holder := db.LeaseHolderID()
+
+	deadline := time.Now().Add(nodeLeaseTTL + nodeLeaseRenewInterval)
+	for {
+		acquired, err := db.AcquireLease(ctx, key, holder, nodeLeaseTTL)
+		if err != nil {
+			return err
+		}
+		if acquired {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("node ID %d is held by another running instance", nodeID)
+		}
+		log.Printf("[Snowflake] node ID %d lease is held, waiting for it to expire\n", nodeID)
+		time.Sleep(nodeLeaseRenewInterval / 2)
+	}
+}
+
+// keepNodeIDLease 定期续期节点 ID 租约
+// 租约过期后若仍未被占用则重新获取，已被其他实例占用时立即退出，避免生成重复 ID；
+// Redis 持续不可用导致租约可能在续期前过期时同样退出，避免其他实例获取同一节点 ID
+func keepNodeIDLease(nodeID int64) {
+	key := fmt.Sprintf(nodeLeaseKeyFormat, nodeID)
+	holder := db.LeaseHolderID()
+	lastRenewed := time.Now()
+
+	ticker := time.NewTicker(nodeLeaseRenewInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		held, err := renewNodeIDLease(key, holder)
+		if err != nil {
+			// 下一次心跳前租约可能过期，此时其他实例可能已获取该节点 ID
+			if time.Since(lastRenewed)+nodeLeaseRenewInterval >= nodeLeaseTTL {
+				log.Fatalf("[Snowflake] node ID %d lease not renewed since %s, exiting to avoid ID collision: %v\n",
+					nodeID, lastRenewed.Format(time.DateTime), err)
+			}
+			log.Printf("[Snowflake] renew node ID %d lease failed: %v\n", nodeID, err)
+			continue
+		}
+		if !held {
+			log.Fatalf("[Snowflake] node ID %d lease taken by another instance, exiting to avoid ID collision\n", nodeID)
+		}
+		lastRenewed = time.Now()
+	}
+}
+
+// renewNodeIDLease 续期节点 ID 租约，租约已过期且未被占用时重新获取
+// 返回：当前实例是否仍持有租约
+func renewNodeIDLease(key, holder string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), nodeLeaseRenewInterval/2)
+	defer cancel()
+
+	renewed, err := db.RenewLease(ctx, key, holder, nodeLeaseTTL)
+	if err != nil || renewed {
+		return renewed, err
+	}
+
+	acquired, err := db.AcquireLease(ctx, key, holder, nodeLeaseTTL)
+	if err != nil {
+		return false, err
+	}
+	if acquired {
+		log.Printf("[Snowflake] %s lease reacquired after expiry\n", key)
+	}
+	return acquired, nil
+}
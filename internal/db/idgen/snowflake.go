@@ -32,6 +32,19 @@ func init() {
 	snowflake.Epoch = epoch
 
 	nodeID := config.Config.App.NodeID
+	if config.Config.App.NodeIDAuto {
+		// 通过 Redis 租约自动分配节点 ID，防止多个实例使用相同节点 ID
+		leasedID, err := leaseNodeID()
+		if err != nil {
+			log.Fatalf("[Snowflake] lease node ID failed: %v\n", err)
+		}
+		nodeID = leasedID
+	} else if err := leaseStaticNodeID(nodeID); err != nil {
+		// 固定节点 ID 同样持有租约，检测多个实例配置了相同的节点 ID
+		log.Fatalf("[Snowflake] lease static node ID failed: %v\n", err)
+	}
+	go keepNodeIDLease(nodeID)
+
 	var err error
 	node, err = snowflake.NewNode(nodeID)
 	if err != nil {
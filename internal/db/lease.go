@@ -0,0 +1,93 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	leaseHolderID     string
+	leaseHolderIDOnce sync.Once
+)
+
+// LeaseHolderID 返回当前进程的租约持有者标识
+func LeaseHolderID() string {
+	leaseHolderIDOnce.Do(func() {
+		hostname, _ := os.Hostname()
+		leaseHolderID = fmt.Sprintf("%s:%d:%s", hostname, os.Getpid(), uuid.NewString())
+	})
+	return leaseHolderID
+}
+
+// renewLeaseScript 仅当租约仍由当前持有者持有时续期
+var renewLeaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// releaseLeaseScript 仅当租约仍由当前持有者持有时释放
+var releaseLeaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// AcquireLease 尝试获取 Redis 租约
+// key: 租约 Key（不含前缀）
+// holder: 持有者标识
+// ttl: 租约有效期
+func AcquireLease(ctx context.Context, key, holder string, ttl time.Duration) (bool, error) {
+	ok, err := Redis.SetNX(ctx, PrefixedKey(key), holder, ttl).Result()
+	if err != nil {
+		return false, err
+	}
+	return ok, nil
+}
+
+// RenewLease 续期租约，租约已过期或被他人持有时返回 false
+// key: 租约 Key（不含前缀）
+// holder: 持有者标识
+// ttl: 新的有效期
+func RenewLease(ctx context.Context, key, holder string, ttl time.Duration) (bool, error) {
+	n, err := renewLeaseScript.Run(ctx, Redis, []string{PrefixedKey(key)}, holder, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+// ReleaseLease 释放租约，租约已被他人持有时不做处理
+// key: 租约 Key（不含前缀）
+// holder: 持有者标识
+func ReleaseLease(ctx context.Context, key, holder string) error {
+	if err := releaseLeaseScript.Run(ctx, Redis, []string{PrefixedKey(key)}, holder).Err(); err != nil && !errors.Is(err, redis.Nil) {
+		return err
+	}
+	return nil
+}
@@ -24,7 +24,10 @@ import (
 
 	"github.com/linux-do/credit/internal/config"
 	"github.com/linux-do/credit/internal/db"
+	"github.com/linux-do/credit/internal/util"
 	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 func Migrate() {
@@ -40,31 +43,230 @@ func Migrate() {
 		&model.Order{},
 		&model.SystemConfig{},
 		&model.Dispute{},
+		&model.OrderRefund{},
+		&model.WebhookDelivery{},
+		&model.Notification{},
+		&model.OutboxMessage{},
+		&model.LedgerAccount{},
+		&model.LedgerEntry{},
+		&model.LedgerPosting{},
+		&model.BalanceDiscrepancy{},
+		&model.IdempotencyKey{},
+		&model.TOTPRecoveryCode{},
 	); err != nil {
 		log.Fatalf("[PostgreSQL] auto migrate failed: %v\n", err)
 	}
 	log.Printf("[PostgreSQL] auto migrate success\n")
 
+	// 创建商户订单号唯一索引
+	ensureMerchantOrderNoUniqueIndex()
+
+	// 创建回调失败订单部分索引
+	ensureNotifyFailedIndex()
+
+	// 创建待支付订单过期时间部分索引
+	ensurePendingOrderExpiresIndex()
+
+	// 创建对账差异部分唯一索引
+	ensureOpenDiscrepancyUniqueIndex()
+
+	// 回填历史订单手续费
+	backfillOrderFees()
+
+	// 回填历史退款订单的退款金额
+	backfillRefundedAmounts()
+
+	// 创建发件箱待发布消息部分索引
+	ensureOutboxPendingIndex()
+
 	// 初始化系统配置数据
 	initSystemConfigs()
 
 	// 初始化用户支付配置数据
 	initUserPayConfigs()
+
+	// 为存量用户开立记账账户
+	initLedgerAccounts()
+
+	// 迁移历史两步验证密钥的加密密钥
+	migrateTOTPSecrets()
 }
 
-// initSystemConfigs 初始化系统配置数据
-func initSystemConfigs() {
-	tx := db.DB(context.Background())
+// migrateTOTPSecrets 将使用用户 SignKey 加密的历史两步验证密钥改为使用服务端配置的密钥加密
+// 已能用服务端密钥解密的记录视为已迁移，可重复执行
+func migrateTOTPSecrets() {
+	ctx := context.Background()
+	key := config.Config.TOTP.EncryptionKey
 
-	var count int64
-	if err := tx.Model(&model.SystemConfig{}).Count(&count).Error; err != nil {
-		log.Printf("[PostgreSQL] failed to check system_config table: %v\n", err)
-		return
+	var lastID uint64
+	migrated := 0
+	for {
+		var users []model.User
+		if err := db.DB(ctx).Select("id", "sign_key", "totp_secret").
+			Where("id > ? AND totp_secret <> ''", lastID).
+			Order("id ASC").
+			Limit(1000).
+			Find(&users).Error; err != nil {
+			log.Printf("[PostgreSQL] failed to query users with totp secret: %v\n", err)
+			return
+		}
+		if len(users) == 0 {
+			break
+		}
+		if key == "" {
+			log.Printf("[PostgreSQL] totp.encryption_key is not configured, skip migrating totp secrets\n")
+			return
+		}
+
+		for _, user := range users {
+			lastID = user.ID
+			if _, err := util.Decrypt(key, user.TOTPSecret); err == nil {
+				continue
+			}
+
+			secret, err := util.Decrypt(user.SignKey, user.TOTPSecret)
+			if err != nil {
+				log.Printf("[PostgreSQL] failed to decrypt totp secret: user_id=%d, error=%v\n", user.ID, err)
+				continue
+			}
+			encrypted, err := util.Encrypt(key, secret)
+			if err != nil {
+				log.Printf("[PostgreSQL] failed to encrypt totp secret: user_id=%d, error=%v\n", user.ID, err)
+				continue
+			}
+
+			// 仅在密文未被并发修改时更新，避免覆盖用户刚重新设置的密钥
+			if err := db.DB(ctx).Model(&model.User{}).
+				Where("id = ? AND totp_secret = ?", user.ID, user.TOTPSecret).
+				Update("totp_secret", encrypted).Error; err != nil {
+				log.Printf("[PostgreSQL] failed to migrate totp secret: user_id=%d, error=%v\n", user.ID, err)
+				continue
+			}
+			migrated++
+		}
 	}
 
-	if count > 0 {
-		return
+	if migrated > 0 {
+		log.Printf("[PostgreSQL] migrated %d totp secrets\n", migrated)
 	}
+}
+
+// initLedgerAccounts 为尚未开户的存量用户开立记账账户，以当前可用余额作为期初余额
+func initLedgerAccounts() {
+	ctx := context.Background()
+
+	var lastID uint64
+	opened := 0
+	for {
+		var userIDs []uint64
+		if err := db.DB(ctx).Model(&model.User{}).
+			Where("id > ? AND NOT EXISTS (SELECT 1 FROM ledger_accounts a WHERE a.type = ? AND a.user_id = users.id)", lastID, model.LedgerAccountUser).
+			Order("id ASC").
+			Limit(1000).
+			Pluck("id", &userIDs).Error; err != nil {
+			log.Printf("[PostgreSQL] failed to query users without ledger account: %v\n", err)
+			return
+		}
+		if len(userIDs) == 0 {
+			break
+		}
+
+		if err := db.DB(ctx).Transaction(func(tx *gorm.DB) error {
+			for _, userID := range userIDs {
+				if err := model.OpenUserLedgerAccount(tx, userID); err != nil {
+					return err
+				}
+			}
+			return nil
+		}); err != nil {
+			log.Printf("[PostgreSQL] failed to open ledger accounts: %v\n", err)
+			return
+		}
+
+		opened += len(userIDs)
+		lastID = userIDs[len(userIDs)-1]
+	}
+
+	if opened > 0 {
+		log.Printf("[PostgreSQL] opened %d ledger accounts\n", opened)
+	}
+}
+
+// ensureMerchantOrderNoUniqueIndex 创建 (client_id, merchant_order_no) 部分唯一索引
+// 历史数据存在重复订单号时仅记录警告，新订单的唯一性由创建订单时的校验保证
+func ensureMerchantOrderNoUniqueIndex() {
+	if err := db.DB(context.Background()).Exec(
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_orders_client_merchant_order_no ON orders (client_id, merchant_order_no) WHERE merchant_order_no <> ''",
+	).Error; err != nil {
+		log.Printf("[PostgreSQL] failed to create unique index on orders(client_id, merchant_order_no), please clean up duplicated merchant order numbers: %v\n", err)
+	}
+}
+
+// ensureNotifyFailedIndex 创建回调失败订单的部分索引，仅索引少量失败订单
+func ensureNotifyFailedIndex() {
+	if err := db.DB(context.Background()).Exec(
+		"CREATE INDEX IF NOT EXISTS idx_orders_client_notify_failed ON orders (client_id, created_at) WHERE notify_failed",
+	).Error; err != nil {
+		log.Printf("[PostgreSQL] failed to create index on orders(client_id, created_at) where notify_failed: %v\n", err)
+	}
+}
+
+// ensurePendingOrderExpiresIndex 创建待支付订单过期时间的部分索引，过期清理任务只扫描 pending 订单
+func ensurePendingOrderExpiresIndex() {
+	if err := db.DB(context.Background()).Exec(
+		"CREATE INDEX IF NOT EXISTS idx_orders_pending_expires ON orders (expires_at) WHERE status = 'pending'",
+	).Error; err != nil {
+		log.Printf("[PostgreSQL] failed to create index on orders(expires_at) where status = 'pending': %v\n", err)
+	}
+}
+
+// ensureOpenDiscrepancyUniqueIndex 创建对账差异部分唯一索引，同一用户同一字段最多一条未处理差异
+func ensureOpenDiscrepancyUniqueIndex() {
+	if err := db.DB(context.Background()).Exec(
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_balance_discrepancies_open ON balance_discrepancies (user_id, field) WHERE status = 'open'",
+	).Error; err != nil {
+		log.Printf("[PostgreSQL] failed to create unique index on balance_discrepancies(user_id, field) where status = 'open': %v\n", err)
+	}
+}
+
+// backfillOrderFees 根据备注中的手续费比例回填历史商户订单的手续费
+func backfillOrderFees() {
+	if err := db.DB(context.Background()).Exec(`
+		UPDATE orders
+		SET fee = ROUND(amount * substring(remark from '收取商家([0-9]+)%手续费')::numeric / 100, 2)
+		WHERE fee = 0
+			AND type IN ('payment', 'online')
+			AND substring(remark from '收取商家([0-9]+)%手续费')::numeric > 0`,
+	).Error; err != nil {
+		log.Printf("[PostgreSQL] failed to backfill order fees: %v\n", err)
+	}
+}
+
+// backfillRefundedAmounts 回填历史全额退款订单（含争议退款）的累计退款金额与已退手续费
+// 历史退款由商户承担全部退款金额、手续费归平台所有，等同于 retain 手续费策略，已退手续费为 0
+func backfillRefundedAmounts() {
+	if err := db.DB(context.Background()).Exec(`
+		UPDATE orders
+		SET refunded_amount = amount, refunded_fee = 0
+		WHERE status = ? AND refunded_amount = 0 AND amount > 0`,
+		model.OrderStatusRefund,
+	).Error; err != nil {
+		log.Printf("[PostgreSQL] failed to backfill refunded amounts: %v\n", err)
+	}
+}
+
+// ensureOutboxPendingIndex 创建发件箱待发布消息的部分索引，中继轮询只扫描未发布消息
+func ensureOutboxPendingIndex() {
+	if err := db.DB(context.Background()).Exec(
+		"CREATE INDEX IF NOT EXISTS idx_outbox_messages_pending ON outbox_messages (id) WHERE published_at IS NULL",
+	).Error; err != nil {
+		log.Printf("[PostgreSQL] failed to create index on outbox_messages(id) where published_at is null: %v\n", err)
+	}
+}
+
+// initSystemConfigs 初始化系统配置数据
+func initSystemConfigs() {
+	tx := db.DB(context.Background())
 
 	defaultConfigs := []model.SystemConfig{
 		{
@@ -92,12 +294,27 @@ func initSystemConfigs() {
 			Value:       "30",
 			Description: "新用户保护期天数，期内积分下降不扣分",
 		},
+		{
+			Key:         model.ConfigKeyRefundFeePolicy,
+			Value:       string(model.RefundFeePolicyReturn),
+			Description: "退款手续费策略：return 按退款比例退还手续费，retain 不退还手续费",
+		},
+		{
+			Key:         model.ConfigKeyTOTPThreshold,
+			Value:       model.DefaultTOTPThreshold.String(),
+			Description: "两步验证金额阈值，开启两步验证的用户单笔支付超过该金额需输入验证码，用户可自行设置更低的阈值",
+		},
 	}
 
-	if err := tx.Create(&defaultConfigs).Error; err != nil {
-		log.Printf("[PostgreSQL] failed to create default system configs: %v\n", err)
-	} else {
-		log.Printf("[PostgreSQL] initialized %d default system configs\n", len(defaultConfigs))
+	// 逐项补齐缺失的配置，已存在的配置保留管理员修改后的值
+	result := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoNothing: true,
+	}).Create(&defaultConfigs)
+	if result.Error != nil {
+		log.Printf("[PostgreSQL] failed to create default system configs: %v\n", result.Error)
+	} else if result.RowsAffected > 0 {
+		log.Printf("[PostgreSQL] initialized %d default system configs\n", result.RowsAffected)
 	}
 }
 
@@ -0,0 +1,105 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package leader
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/linux-do/credit/internal/db"
+)
+
+const (
+	// leaseKeyFormat 选主租约 Key 格式
+	leaseKeyFormat = "leader:%s"
+	// leaseTTL 租约有效期，Leader 异常退出后最迟在该时间后完成故障转移
+	leaseTTL = 15 * time.Second
+	// renewInterval 租约续期间隔
+	renewInterval = 5 * time.Second
+	// retryInterval 未当选时重新竞选的间隔
+	retryInterval = 5 * time.Second
+)
+
+// Run 基于 Redis 租约进行选主，仅在当选 Leader 期间执行 fn
+// fn 收到的 context 在失去 Leader 身份或 ctx 结束时取消，fn 需在 context 取消后尽快返回
+// 失去 Leader 身份后会自动重新参与竞选，实现故障转移
+func Run(ctx context.Context, name string, fn func(ctx context.Context)) {
+	go func() {
+		key := fmt.Sprintf(leaseKeyFormat, name)
+		holder := db.LeaseHolderID()
+
+		for {
+			acquired, err := db.AcquireLease(ctx, key, holder, leaseTTL)
+			if err != nil && ctx.Err() == nil {
+				log.Printf("[Leader] %s 竞选失败: %v", name, err)
+			}
+			if acquired {
+				log.Printf("[Leader] %s 当选 Leader: %s", name, holder)
+				lead(ctx, name, key, holder, fn)
+				log.Printf("[Leader] %s 卸任 Leader: %s", name, holder)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(retryInterval):
+			}
+		}
+	}()
+}
+
+// lead 执行 fn 并定期续期租约，续期失败或 ctx 结束时取消 fn 并释放租约
+func lead(ctx context.Context, name, key, holder string, fn func(ctx context.Context)) {
+	leaderCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn(leaderCtx)
+	}()
+
+	ticker := time.NewTicker(renewInterval)
+	defer ticker.Stop()
+
+	for running := true; running; {
+		select {
+		case <-ctx.Done():
+			running = false
+		case <-done:
+			running = false
+		case <-ticker.C:
+			renewed, err := db.RenewLease(ctx, key, holder, leaseTTL)
+			if err != nil {
+				log.Printf("[Leader] %s 续期租约失败: %v", name, err)
+			}
+			if !renewed {
+				running = false
+			}
+		}
+	}
+
+	cancel()
+	<-done
+
+	// ctx 可能已取消，使用独立的 context 释放租约，便于其他实例尽快接管
+	releaseCtx, releaseCancel := context.WithTimeout(context.Background(), time.Second)
+	defer releaseCancel()
+	if err := db.ReleaseLease(releaseCtx, key, holder); err != nil {
+		log.Printf("[Leader] %s 释放租约失败: %v", name, err)
+	}
+}
@@ -35,21 +35,26 @@ import (
 	"github.com/linux-do/credit/internal/db"
 	"github.com/linux-do/credit/internal/logger"
 	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/service"
 	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-// orderExpireKeyPrefix 订单过期 Key 前缀
-const orderExpireKeyPrefix = "payment:order:expire:"
+const (
+	// orderExpireKeyPrefix 订单过期 Key 前缀
+	orderExpireKeyPrefix = "payment:order:expire:"
+	// ExpireListenerLeaderName 过期监听器选主名称
+	ExpireListenerLeaderName = "expire_listener"
+)
 
-// StartExpireListener 启动过期监听器
+// StartExpireListener 启动过期监听器，需在 Leader 实例上运行，ctx 取消时停止监听
+// 仅作为订单过期的低延迟通道，遗漏的过期事件由定时任务 ExpirePendingOrdersTask 兜底
 func StartExpireListener(ctx context.Context) error {
 	if db.Redis == nil {
 		return fmt.Errorf("redis client is not initialized")
 	}
 
-	// 初始化时先处理已过期的订单
-	model.ExpirePendingOrders(ctx)
-
 	cfg := config.Config.Redis
 
 	// Cluster 模式：订阅所有分片节点
@@ -144,14 +149,25 @@ func handleExpiredKey(ctx context.Context, expiredKey string) {
 		return
 	}
 
-	// 更新订单状态为过期
-	result := db.DB(ctx).Model(&model.Order{}).
-		Where("id = ? AND status = ?", orderID, model.OrderStatusPending).
-		Update("status", model.OrderStatusExpired)
-
-	if result.Error != nil {
-		logger.ErrorF(ctx, "更新订单状态为过期失败: order_id=%d, error=%v", orderID, result.Error)
-	} else if result.RowsAffected > 0 {
+	// 更新订单状态为过期，并在同一事务内下发订单过期事件
+	var order model.Order
+	var expired bool
+	if err := db.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&order).
+			Clauses(clause.Returning{}).
+			Where("id = ? AND status = ?", orderID, model.OrderStatusPending).
+			Update("status", model.OrderStatusExpired)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		expired = true
+		return service.EnqueueWebhookEvent(tx, &order, model.WebhookEventOrderExpired, nil)
+	}); err != nil {
+		logger.ErrorF(ctx, "更新订单状态为过期失败: order_id=%d, error=%v", orderID, err)
+	} else if expired {
 		logger.InfoF(ctx, "订单已过期: order_id=%d", orderID)
 	}
 }
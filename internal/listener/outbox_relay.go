@@ -0,0 +1,164 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package listener
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/linux-do/credit/internal/config"
+	"github.com/linux-do/credit/internal/db"
+	"github.com/linux-do/credit/internal/logger"
+	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/task/scheduler"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// outboxTaskIDFormat 发件箱任务 ID 格式，保证同一消息重复投递时任务队列只保留一份
+const outboxTaskIDFormat = "outbox-%d"
+
+// StartOutboxRelay 启动发件箱中继，将已提交事务写入的消息投递到任务队列
+// 多实例部署时通过行锁跳过其他实例正在处理的消息
+func StartOutboxRelay(ctx context.Context) {
+	cfg := config.Config.Outbox
+
+	pollInterval := time.Duration(cfg.PollIntervalMs) * time.Millisecond
+	if pollInterval <= 0 {
+		pollInterval = time.Second
+	}
+
+	go func() {
+		log.Printf("[Outbox Relay] 发件箱中继已启动，轮询间隔: %s", pollInterval)
+
+		pollTicker := time.NewTicker(pollInterval)
+		defer pollTicker.Stop()
+		cleanupTicker := time.NewTicker(time.Hour)
+		defer cleanupTicker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				log.Printf("[Outbox Relay] 发件箱中继已停止")
+				return
+			case <-pollTicker.C:
+				relayOutboxMessages(ctx)
+			case <-cleanupTicker.C:
+				cleanupOutboxMessages(ctx)
+			}
+		}
+	}()
+}
+
+// relayOutboxMessages 分批投递待发布消息，直到没有积压或任务队列不可用
+func relayOutboxMessages(ctx context.Context) {
+	batchSize := config.Config.Outbox.BatchSize
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+
+	for ctx.Err() == nil {
+		published, err := relayOutboxBatch(ctx, batchSize)
+		if err != nil {
+			logger.ErrorF(ctx, "发件箱消息投递失败: %v", err)
+			return
+		}
+		if published < batchSize {
+			return
+		}
+	}
+}
+
+// relayOutboxBatch 锁定一批待发布消息并投递，返回成功投递的数量
+func relayOutboxBatch(ctx context.Context, batchSize int) (int, error) {
+	published := 0
+	err := db.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		var messages []model.OutboxMessage
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("published_at IS NULL").
+			Order("id ASC").
+			Limit(batchSize).
+			Find(&messages).Error; err != nil {
+			return err
+		}
+
+		for _, message := range messages {
+			opts := []asynq.Option{
+				asynq.Queue(message.Queue),
+				asynq.MaxRetry(message.MaxRetry),
+				asynq.TaskID(fmt.Sprintf(outboxTaskIDFormat, message.ID)),
+			}
+			if message.TimeoutSeconds > 0 {
+				opts = append(opts, asynq.Timeout(time.Duration(message.TimeoutSeconds)*time.Second))
+			}
+
+			if _, errTask := scheduler.AsynqClient.EnqueueContext(
+				ctx,
+				asynq.NewTask(message.TaskType, []byte(message.Payload)),
+				opts...,
+			); errTask != nil && !errors.Is(errTask, asynq.ErrTaskIDConflict) {
+				// 任务队列不可用时记录失败并结束本批次，已投递的消息仍随事务提交
+				if err := tx.Model(&model.OutboxMessage{}).
+					Where("id = ?", message.ID).
+					UpdateColumns(map[string]interface{}{
+						"attempts":   gorm.Expr("attempts + 1"),
+						"last_error": errTask.Error(),
+					}).Error; err != nil {
+					return err
+				}
+				return nil
+			}
+
+			if err := tx.Model(&model.OutboxMessage{}).
+				Where("id = ?", message.ID).
+				UpdateColumn("published_at", time.Now()).Error; err != nil {
+				return err
+			}
+			published++
+		}
+		return nil
+	})
+	return published, err
+}
+
+// cleanupOutboxMessages 清理超过保留期的已发布消息
+func cleanupOutboxMessages(ctx context.Context) {
+	retentionHours := config.Config.Outbox.RetentionHours
+	if retentionHours <= 0 {
+		retentionHours = 72
+	}
+
+	result := db.DB(ctx).
+		Where("published_at < ?", time.Now().Add(-time.Duration(retentionHours)*time.Hour)).
+		Delete(&model.OutboxMessage{})
+	if result.Error != nil {
+		logger.ErrorF(ctx, "清理发件箱消息失败: %v", result.Error)
+	} else if result.RowsAffected > 0 {
+		logger.InfoF(ctx, "已清理发件箱消息: %d 条", result.RowsAffected)
+	}
+}
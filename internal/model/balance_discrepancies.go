@@ -0,0 +1,163 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"time"
+
+	"github.com/linux-do/credit/internal/db/idgen"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type BalanceField string
+
+const (
+	BalanceFieldAvailableBalance BalanceField = "available_balance"
+	BalanceFieldTotalReceive     BalanceField = "total_receive"
+	BalanceFieldTotalPayment     BalanceField = "total_payment"
+	BalanceFieldTotalTransfer    BalanceField = "total_transfer"
+	BalanceFieldLedgerBalance    BalanceField = "ledger_balance"
+)
+
+type DiscrepancyStatus string
+
+const (
+	DiscrepancyStatusOpen     DiscrepancyStatus = "open"
+	DiscrepancyStatusResolved DiscrepancyStatus = "resolved"
+	DiscrepancyStatusRepaired DiscrepancyStatus = "repaired"
+)
+
+// BalanceDiscrepancy 对账差异，记录用户余额字段与订单流水推算值不一致的情况
+// 同一用户同一字段最多存在一条 open 记录
+type BalanceDiscrepancy struct {
+	ID               uint64            `json:"id" gorm:"primaryKey"`
+	UserID           uint64            `json:"user_id" gorm:"not null;index"`
+	Username         string            `json:"username" gorm:"->"`
+	Field            BalanceField      `json:"field" gorm:"type:varchar(32);not null"`
+	Actual           decimal.Decimal   `json:"actual" gorm:"type:numeric(20,2);not null"`
+	Expected         decimal.Decimal   `json:"expected" gorm:"type:numeric(20,2);not null"`
+	Diff             decimal.Decimal   `json:"diff" gorm:"type:numeric(20,2);not null"` // 实际值 - 推算值
+	Status           DiscrepancyStatus `json:"status" gorm:"type:varchar(20);not null;index:idx_balance_discrepancies_status_created,priority:1"`
+	ResolvedByUserID uint64            `json:"resolved_by_user_id"`
+	ResolvedAt       *time.Time        `json:"resolved_at"`
+	CreatedAt        time.Time         `json:"created_at" gorm:"autoCreateTime;index:idx_balance_discrepancies_status_created,priority:2"`
+	UpdatedAt        time.Time         `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (d *BalanceDiscrepancy) BeforeCreate(*gorm.DB) error {
+	if d.ID == 0 {
+		d.ID = idgen.NextUint64ID()
+	}
+	return nil
+}
+
+// ExpectedUserBalance 由订单流水推算的用户余额
+type ExpectedUserBalance struct {
+	UserID                uint64          `json:"user_id"`
+	AvailableBalance      decimal.Decimal `json:"available_balance"`
+	TotalReceive          decimal.Decimal `json:"total_receive"`
+	TotalPayment          decimal.Decimal `json:"total_payment"`
+	TotalTransfer         decimal.Decimal `json:"total_transfer"`
+	LedgerBalance         decimal.Decimal `json:"ledger_balance"`
+	ExpectedTotalReceive  decimal.Decimal `json:"expected_total_receive"`
+	ExpectedTotalPayment  decimal.Decimal `json:"expected_total_payment"`
+	ExpectedTotalTransfer decimal.Decimal `json:"expected_total_transfer"`
+}
+
+// ExpectedAvailableBalance 推算的可用余额：总收入 - 总支付 - 总转出
+func (e *ExpectedUserBalance) ExpectedAvailableBalance() decimal.Decimal {
+	return e.ExpectedTotalReceive.Sub(e.ExpectedTotalPayment).Sub(e.ExpectedTotalTransfer)
+}
+
+// expectedUserBalanceSQL 由订单流水推算用户余额
+// 收入：社区积分、转入、商户收款扣除手续费和商户承担的退款金额；支出：支付扣除已退款金额；转出：转账金额
+const expectedUserBalanceSQL = `
+SELECT u.id AS user_id, u.available_balance, u.total_receive, u.total_payment, u.total_transfer,
+	COALESCE(la.balance, u.available_balance) AS ledger_balance,
+	COALESCE(SUM(CASE
+		WHEN o.payee_user_id = u.id AND o.type IN (@incomeTypes) THEN o.amount
+		WHEN o.payee_user_id = u.id AND o.type IN (@paymentTypes) THEN o.amount - o.fee - o.refunded_amount + o.refunded_fee
+	END), 0) AS expected_total_receive,
+	COALESCE(SUM(CASE
+		WHEN o.payer_user_id = u.id AND o.type IN (@paymentTypes) THEN o.amount - o.refunded_amount
+	END), 0) AS expected_total_payment,
+	COALESCE(SUM(CASE
+		WHEN o.payer_user_id = u.id AND o.type = @transferType THEN o.amount
+	END), 0) AS expected_total_transfer
+FROM users u
+LEFT JOIN ledger_accounts la ON la.type = @ledgerUserType AND la.user_id = u.id
+LEFT JOIN orders o ON (o.payer_user_id = u.id OR o.payee_user_id = u.id) AND o.status IN (@paidStatuses)
+WHERE u.id IN (@userIDs)
+GROUP BY u.id, la.balance
+`
+
+// PaidOrderStatuses 已完成支付的订单状态（含后续发生退款或争议的订单）
+var PaidOrderStatuses = []OrderStatus{
+	OrderStatusSuccess,
+	OrderStatusPartiallyRefunded,
+	OrderStatusRefund,
+	OrderStatusDisputing,
+	OrderStatusRefused,
+}
+
+// GetExpectedUserBalances 批量由订单流水推算用户余额
+func GetExpectedUserBalances(tx *gorm.DB, userIDs []uint64) ([]ExpectedUserBalance, error) {
+	var balances []ExpectedUserBalance
+	if err := tx.Raw(expectedUserBalanceSQL, map[string]interface{}{
+		"incomeTypes":    []OrderType{OrderTypeCommunity, OrderTypeTransfer},
+		"paymentTypes":   []OrderType{OrderTypePayment, OrderTypeOnline},
+		"transferType":   OrderTypeTransfer,
+		"ledgerUserType": LedgerAccountUser,
+		"paidStatuses":   PaidOrderStatuses,
+		"userIDs":        userIDs,
+	}).Scan(&balances).Error; err != nil {
+		return nil, err
+	}
+	return balances, nil
+}
+
+// HasUnbackfilledRefundOrders 是否存在尚未回填退款金额的历史全额退款订单
+// 这类订单按流水推算会把已正确退回的金额误判为差异，回填完成前不能对账和修复
+func HasUnbackfilledRefundOrders(tx *gorm.DB) (bool, error) {
+	var exists bool
+	if err := tx.Raw("SELECT EXISTS (SELECT 1 FROM orders WHERE status = ? AND refunded_amount = 0 AND amount > 0)",
+		OrderStatusRefund).Scan(&exists).Error; err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+// Mismatches 返回与推算值不一致的字段，key 为字段名，value 为 [实际值, 推算值]
+func (e *ExpectedUserBalance) Mismatches() map[BalanceField][2]decimal.Decimal {
+	expectedAvailable := e.ExpectedAvailableBalance()
+	checks := map[BalanceField][2]decimal.Decimal{
+		BalanceFieldAvailableBalance: {e.AvailableBalance, expectedAvailable},
+		BalanceFieldTotalReceive:     {e.TotalReceive, e.ExpectedTotalReceive},
+		BalanceFieldTotalPayment:     {e.TotalPayment, e.ExpectedTotalPayment},
+		BalanceFieldTotalTransfer:    {e.TotalTransfer, e.ExpectedTotalTransfer},
+		BalanceFieldLedgerBalance:    {e.LedgerBalance, expectedAvailable},
+	}
+
+	mismatches := make(map[BalanceField][2]decimal.Decimal)
+	for field, values := range checks {
+		if !values[0].Equal(values[1]) {
+			mismatches[field] = values
+		}
+	}
+	return mismatches
+}
@@ -0,0 +1,45 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"time"
+
+	"github.com/linux-do/credit/internal/db/idgen"
+	"gorm.io/gorm"
+)
+
+// IdempotencyKey 幂等键，记录用户携带 Idempotency-Key 请求的指纹与响应，重放时直接返回原响应
+// StatusCode 为 0 表示首个请求仍在处理中
+type IdempotencyKey struct {
+	ID           uint64    `json:"id" gorm:"primaryKey"`
+	UserID       uint64    `json:"user_id" gorm:"not null;uniqueIndex:idx_idempotency_keys_user_key,priority:1"`
+	Key          string    `json:"key" gorm:"size:64;not null;uniqueIndex:idx_idempotency_keys_user_key,priority:2"`
+	Fingerprint  string    `json:"fingerprint" gorm:"size:64;not null"`
+	StatusCode   int       `json:"status_code" gorm:"not null;default:0"`
+	ResponseBody []byte    `json:"-" gorm:"type:bytea"`
+	ExpiresAt    time.Time `json:"expires_at" gorm:"not null;index"`
+	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (k *IdempotencyKey) BeforeCreate(*gorm.DB) error {
+	if k.ID == 0 {
+		k.ID = idgen.NextUint64ID()
+	}
+	return nil
+}
@@ -0,0 +1,281 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"errors"
+	"time"
+
+	"github.com/linux-do/credit/internal/common"
+	"github.com/linux-do/credit/internal/db/idgen"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LedgerAccountType string
+
+const (
+	// LedgerAccountUser 用户账户，余额与 users.available_balance 一致
+	LedgerAccountUser LedgerAccountType = "user"
+	// LedgerAccountPlatformFee 平台手续费收入账户
+	LedgerAccountPlatformFee LedgerAccountType = "platform_fee"
+	// LedgerAccountCommunityMint 社区积分发行账户，余额为负数，绝对值等于累计发行的积分
+	LedgerAccountCommunityMint LedgerAccountType = "community_mint"
+	// LedgerAccountReconciliation 对账调整账户，记录对账修复产生的余额调整
+	LedgerAccountReconciliation LedgerAccountType = "reconciliation"
+)
+
+type LedgerEntryType string
+
+const (
+	LedgerEntryOpening       LedgerEntryType = "opening"
+	LedgerEntryInitialCredit LedgerEntryType = "initial_credit"
+	LedgerEntryCommunity     LedgerEntryType = "community"
+	LedgerEntryPayment       LedgerEntryType = "payment"
+	LedgerEntryTransfer      LedgerEntryType = "transfer"
+	LedgerEntryRefund        LedgerEntryType = "refund"
+	LedgerEntryDisputeRefund LedgerEntryType = "dispute_refund"
+	LedgerEntryAdjustment    LedgerEntryType = "adjustment"
+)
+
+// LedgerAccount 记账账户
+type LedgerAccount struct {
+	ID        uint64            `json:"id" gorm:"primaryKey"`
+	Type      LedgerAccountType `json:"type" gorm:"type:varchar(20);not null;uniqueIndex:idx_ledger_accounts_type_user,priority:1"`
+	UserID    uint64            `json:"user_id" gorm:"not null;default:0;uniqueIndex:idx_ledger_accounts_type_user,priority:2"`
+	Balance   decimal.Decimal   `json:"balance" gorm:"type:numeric(20,2);not null;default:0"`
+	CreatedAt time.Time         `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time         `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (a *LedgerAccount) BeforeCreate(*gorm.DB) error {
+	if a.ID == 0 {
+		a.ID = idgen.NextUint64ID()
+	}
+	return nil
+}
+
+// LedgerEntry 记账凭证，写入后不可修改，所有分录金额之和为 0
+type LedgerEntry struct {
+	ID        uint64          `json:"id" gorm:"primaryKey"`
+	Type      LedgerEntryType `json:"type" gorm:"type:varchar(20);not null"`
+	OrderID   uint64          `json:"order_id" gorm:"index"`
+	Memo      string          `json:"memo" gorm:"size:255"`
+	CreatedAt time.Time       `json:"created_at" gorm:"autoCreateTime;index"`
+}
+
+func (e *LedgerEntry) BeforeCreate(*gorm.DB) error {
+	if e.ID == 0 {
+		e.ID = idgen.NextUint64ID()
+	}
+	return nil
+}
+
+func (e *LedgerEntry) BeforeUpdate(*gorm.DB) error {
+	return errors.New(common.LedgerEntryImmutable)
+}
+
+func (e *LedgerEntry) BeforeDelete(*gorm.DB) error {
+	return errors.New(common.LedgerEntryImmutable)
+}
+
+// LedgerPosting 记账分录，金额为正表示入账，为负表示出账
+type LedgerPosting struct {
+	ID        uint64          `json:"id" gorm:"primaryKey"`
+	EntryID   uint64          `json:"entry_id" gorm:"not null;index"`
+	AccountID uint64          `json:"account_id" gorm:"not null;index:idx_ledger_postings_account_created,priority:1"`
+	Amount    decimal.Decimal `json:"amount" gorm:"type:numeric(20,2);not null"`
+	CreatedAt time.Time       `json:"created_at" gorm:"autoCreateTime;index:idx_ledger_postings_account_created,priority:2"`
+}
+
+func (p *LedgerPosting) BeforeCreate(*gorm.DB) error {
+	if p.ID == 0 {
+		p.ID = idgen.NextUint64ID()
+	}
+	return nil
+}
+
+func (p *LedgerPosting) BeforeUpdate(*gorm.DB) error {
+	return errors.New(common.LedgerEntryImmutable)
+}
+
+func (p *LedgerPosting) BeforeDelete(*gorm.DB) error {
+	return errors.New(common.LedgerEntryImmutable)
+}
+
+// LedgerLeg 记账凭证中针对单个账户的变动
+type LedgerLeg struct {
+	AccountType LedgerAccountType
+	UserID      uint64
+	// Amount 正数入账，负数出账
+	Amount decimal.Decimal
+	// UserColumns 用户账户随余额一并更新的统计字段，如 total_payment、pay_score
+	UserColumns map[string]interface{}
+	// CheckBalance 出账时校验用户可用余额充足
+	CheckBalance bool
+}
+
+// PostLedgerEntry 写入一笔借贷平衡的记账凭证，并在同一事务内更新账户余额
+// 用户账户同时更新 users.available_balance 及 UserColumns 中的统计字段，所有余额变动都应通过此函数完成
+func PostLedgerEntry(tx *gorm.DB, entry *LedgerEntry, legs []LedgerLeg) error {
+	total := decimal.Zero
+	for _, leg := range legs {
+		total = total.Add(leg.Amount)
+	}
+	if len(legs) < 2 || !total.IsZero() {
+		return errors.New(common.LedgerEntryUnbalanced)
+	}
+
+	if err := tx.Create(entry).Error; err != nil {
+		return err
+	}
+
+	postings := make([]LedgerPosting, 0, len(legs))
+	for _, leg := range legs {
+		// 先变更记账账户，用户账户尚未开户时需以变动前的可用余额作为期初余额
+		accountID, err := applyAccountBalance(tx, leg.AccountType, leg.UserID, leg.Amount)
+		if err != nil {
+			return err
+		}
+
+		if leg.AccountType == LedgerAccountUser {
+			if err := applyUserLeg(tx, leg); err != nil {
+				return err
+			}
+		}
+
+		if leg.Amount.IsZero() {
+			continue
+		}
+		postings = append(postings, LedgerPosting{
+			EntryID:   entry.ID,
+			AccountID: accountID,
+			Amount:    leg.Amount,
+		})
+	}
+
+	if len(postings) == 0 {
+		return nil
+	}
+	return tx.Create(&postings).Error
+}
+
+// applyUserLeg 更新用户可用余额及统计字段
+func applyUserLeg(tx *gorm.DB, leg LedgerLeg) error {
+	columns := make(map[string]interface{}, len(leg.UserColumns)+1)
+	for column, value := range leg.UserColumns {
+		columns[column] = value
+	}
+	if !leg.Amount.IsZero() {
+		columns["available_balance"] = gorm.Expr("available_balance + ?", leg.Amount)
+	}
+	if len(columns) == 0 {
+		return nil
+	}
+
+	query := tx.Model(&User{}).Where("id = ?", leg.UserID)
+	if leg.CheckBalance && leg.Amount.IsNegative() {
+		query = query.Where("available_balance >= ?", leg.Amount.Neg())
+	}
+
+	result := query.UpdateColumns(columns)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		if leg.CheckBalance && leg.Amount.IsNegative() {
+			return errors.New(common.InsufficientBalance)
+		}
+		return errors.New(common.LedgerUserNotFound)
+	}
+	return nil
+}
+
+// applyAccountBalance 变更记账账户余额，账户不存在时自动开户，返回账户 ID
+func applyAccountBalance(tx *gorm.DB, accountType LedgerAccountType, userID uint64, amount decimal.Decimal) (uint64, error) {
+	var account LedgerAccount
+	result := tx.Model(&account).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "id"}}}).
+		Where("type = ? AND user_id = ?", accountType, userID).
+		UpdateColumn("balance", gorm.Expr("balance + ?", amount))
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return account.ID, nil
+	}
+
+	// 用户账户按期初余额开户后重新累加
+	if accountType == LedgerAccountUser {
+		if err := OpenUserLedgerAccount(tx, userID); err != nil {
+			return 0, err
+		}
+		return applyAccountBalance(tx, accountType, userID, amount)
+	}
+
+	account = LedgerAccount{Type: accountType, UserID: userID, Balance: amount}
+	result = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&account)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return account.ID, nil
+	}
+
+	// 并发开户冲突，重新累加余额
+	return applyAccountBalance(tx, accountType, userID, amount)
+}
+
+// OpenUserLedgerAccount 为用户开立记账账户，以当前可用余额作为期初余额，由社区发行账户拨付
+// 账户已存在时不做处理
+func OpenUserLedgerAccount(tx *gorm.DB, userID uint64) error {
+	var user User
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id", "available_balance").
+		Where("id = ?", userID).
+		First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New(common.LedgerUserNotFound)
+		}
+		return err
+	}
+
+	account := LedgerAccount{Type: LedgerAccountUser, UserID: userID, Balance: user.AvailableBalance}
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&account)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 || user.AvailableBalance.IsZero() {
+		return nil
+	}
+
+	entry := LedgerEntry{Type: LedgerEntryOpening, Memo: "期初余额"}
+	if err := tx.Create(&entry).Error; err != nil {
+		return err
+	}
+
+	mintAccountID, err := applyAccountBalance(tx, LedgerAccountCommunityMint, 0, user.AvailableBalance.Neg())
+	if err != nil {
+		return err
+	}
+
+	return tx.Create(&[]LedgerPosting{
+		{EntryID: entry.ID, AccountID: account.ID, Amount: user.AvailableBalance},
+		{EntryID: entry.ID, AccountID: mintAccountID, Amount: user.AvailableBalance.Neg()},
+	}).Error
+}
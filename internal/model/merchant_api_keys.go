@@ -23,19 +23,28 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	// CallbackFormatEPay 易支付兼容格式：GET 请求，参数位于查询字符串，MD5 签名
+	CallbackFormatEPay = "epay"
+	// CallbackFormatJSON JSON 格式：POST 请求体，请求头携带带时间戳的 HMAC-SHA256 签名
+	CallbackFormatJSON = "json"
+)
+
 type MerchantAPIKey struct {
-	ID             uint64         `json:"id" gorm:"primaryKey"`
-	UserID         uint64         `json:"user_id" gorm:"not null;index:idx_merchant_api_keys_user_created,priority:1"`
-	ClientID       string         `json:"client_id" gorm:"size:64;uniqueIndex;index:idx_client_credentials,priority:2;not null"`
-	ClientSecret   string         `json:"client_secret" gorm:"size:64;index:idx_client_credentials,priority:1;not null"`
-	AppName        string         `json:"app_name" gorm:"size:20;not null"`
-	AppHomepageURL string         `json:"app_homepage_url" gorm:"size:100;not null"`
-	AppDescription string         `json:"app_description" gorm:"size:100"`
-	RedirectURI    string         `json:"redirect_uri" gorm:"size:100"`
-	NotifyURL      string         `json:"notify_url" gorm:"size:100;not null"`
-	CreatedAt      time.Time      `json:"created_at" gorm:"autoCreateTime;index:idx_merchant_api_keys_user_created,priority:2"`
-	UpdatedAt      time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
-	DeletedAt      gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+	ID             uint64           `json:"id" gorm:"primaryKey"`
+	UserID         uint64           `json:"user_id" gorm:"not null;index:idx_merchant_api_keys_user_created,priority:1"`
+	ClientID       string           `json:"client_id" gorm:"size:64;uniqueIndex;index:idx_client_credentials,priority:2;not null"`
+	ClientSecret   string           `json:"client_secret" gorm:"size:64;index:idx_client_credentials,priority:1;not null"`
+	AppName        string           `json:"app_name" gorm:"size:20;not null"`
+	AppHomepageURL string           `json:"app_homepage_url" gorm:"size:100;not null"`
+	AppDescription string           `json:"app_description" gorm:"size:100"`
+	RedirectURI    string           `json:"redirect_uri" gorm:"size:100"`
+	NotifyURL      string           `json:"notify_url" gorm:"size:100;not null"`
+	WebhookEvents  WebhookEventList `json:"webhook_events" gorm:"type:text;not null;default:'[\"order.paid\"]'"`
+	CallbackFormat string           `json:"callback_format" gorm:"size:16;not null;default:'epay'"`
+	CreatedAt      time.Time        `json:"created_at" gorm:"autoCreateTime;index:idx_merchant_api_keys_user_created,priority:2"`
+	UpdatedAt      time.Time        `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt      gorm.DeletedAt   `json:"deleted_at" gorm:"index"`
 }
 
 // GetByID 通过 ID 查询商户 API Key
@@ -52,5 +61,11 @@ func (m *MerchantAPIKey) BeforeCreate(*gorm.DB) error {
 	if m.ID == 0 {
 		m.ID = idgen.NextUint64ID()
 	}
+	if m.WebhookEvents == nil {
+		m.WebhookEvents = DefaultWebhookEvents
+	}
+	if m.CallbackFormat == "" {
+		m.CallbackFormat = CallbackFormatEPay
+	}
 	return nil
 }
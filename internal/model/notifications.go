@@ -0,0 +1,65 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"time"
+
+	"github.com/linux-do/credit/internal/db/idgen"
+	"gorm.io/gorm"
+)
+
+type NotificationType string
+
+const (
+	NotificationTypeWebhookFailed NotificationType = "webhook_failed"
+	NotificationTypePayKeyLocked  NotificationType = "pay_key_locked"
+)
+
+// Notification 站内通知
+type Notification struct {
+	ID        uint64           `json:"id" gorm:"primaryKey"`
+	UserID    uint64           `json:"user_id" gorm:"not null;index:idx_notifications_user_read_created,priority:1"`
+	Type      NotificationType `json:"type" gorm:"type:varchar(32);not null"`
+	RefKey    string           `json:"ref_key" gorm:"size:64"`
+	Title     string           `json:"title" gorm:"size:64;not null"`
+	Content   string           `json:"content" gorm:"size:255;not null"`
+	Link      string           `json:"link" gorm:"size:255"`
+	IsRead    bool             `json:"is_read" gorm:"not null;default:false;index:idx_notifications_user_read_created,priority:2"`
+	CreatedAt time.Time        `json:"created_at" gorm:"autoCreateTime;index:idx_notifications_user_read_created,priority:3"`
+}
+
+func (n *Notification) BeforeCreate(*gorm.DB) error {
+	if n.ID == 0 {
+		n.ID = idgen.NextUint64ID()
+	}
+	return nil
+}
+
+// CreateUnlessUnread 创建通知，若同一用户已有相同类型和关联对象的未读通知则跳过，避免重复提醒
+func (n *Notification) CreateUnlessUnread(tx *gorm.DB) error {
+	var count int64
+	if err := tx.Model(&Notification{}).
+		Where("user_id = ? AND type = ? AND ref_key = ? AND is_read = ?", n.UserID, n.Type, n.RefKey, false).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	return tx.Create(n).Error
+}
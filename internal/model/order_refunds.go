@@ -0,0 +1,41 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"time"
+
+	"github.com/linux-do/credit/internal/db/idgen"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type OrderRefund struct {
+	ID               uint64          `json:"id" gorm:"primaryKey"`
+	OrderID          uint64          `json:"order_id" gorm:"not null;index:idx_order_refunds_order_created,priority:1"`
+	ClientID         string          `json:"client_id" gorm:"size:64;not null;uniqueIndex:idx_order_refunds_client_refund_no,priority:1"`
+	MerchantRefundNo string          `json:"merchant_refund_no" gorm:"size:64;not null;uniqueIndex:idx_order_refunds_client_refund_no,priority:2"`
+	Amount           decimal.Decimal `json:"amount" gorm:"type:numeric(20,2);not null"`
+	CreatedAt        time.Time       `json:"created_at" gorm:"autoCreateTime;index:idx_order_refunds_order_created,priority:2"`
+}
+
+func (r *OrderRefund) BeforeCreate(*gorm.DB) error {
+	if r.ID == 0 {
+		r.ID = idgen.NextUint64ID()
+	}
+	return nil
+}
@@ -17,16 +17,14 @@ limitations under the License.
 package model
 
 import (
-	"context"
 	"fmt"
 	"time"
 
-	"github.com/linux-do/credit/internal/db"
 	"github.com/linux-do/credit/internal/db/idgen"
-	"github.com/linux-do/credit/internal/logger"
 	"github.com/shopspring/decimal"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type OrderType string
@@ -42,13 +40,15 @@ const (
 type OrderStatus string
 
 const (
-	OrderStatusSuccess   OrderStatus = "success"
-	OrderStatusFailed    OrderStatus = "failed"
-	OrderStatusPending   OrderStatus = "pending"
-	OrderStatusExpired   OrderStatus = "expired"
-	OrderStatusDisputing OrderStatus = "disputing"
-	OrderStatusRefund    OrderStatus = "refund"
-	OrderStatusRefused   OrderStatus = "refused"
+	OrderStatusSuccess           OrderStatus = "success"
+	OrderStatusFailed            OrderStatus = "failed"
+	OrderStatusPending           OrderStatus = "pending"
+	OrderStatusExpired           OrderStatus = "expired"
+	OrderStatusDisputing         OrderStatus = "disputing"
+	OrderStatusRefund            OrderStatus = "refund"
+	OrderStatusRefused           OrderStatus = "refused"
+	OrderStatusPartiallyRefunded OrderStatus = "partially_refunded"
+	OrderStatusClosed            OrderStatus = "closed"
 )
 
 type Order struct {
@@ -62,10 +62,16 @@ type Order struct {
 	PayerUsername   string          `json:"payer_username" gorm:"->"`
 	PayeeUsername   string          `json:"payee_username" gorm:"->"`
 	Amount          decimal.Decimal `json:"amount" gorm:"type:numeric(20,2);not null;index"`
+	RefundedAmount  decimal.Decimal `json:"refunded_amount" gorm:"type:numeric(20,2);not null;default:0"`
+	Fee             decimal.Decimal `json:"fee" gorm:"type:numeric(20,2);not null;default:0"`
+	RefundedFee     decimal.Decimal `json:"refunded_fee" gorm:"type:numeric(20,2);not null;default:0"`
 	Status          OrderStatus     `json:"status" gorm:"type:varchar(20);not null;index:idx_orders_payee_status_type_created,priority:2;index:idx_orders_payer_status_type_created,priority:2;index:idx_orders_client_status_created,priority:2;index:idx_orders_payer_status_type_trade,priority:2"`
 	Type            OrderType       `json:"type" gorm:"type:varchar(20);not null;index:idx_orders_payee_status_type_created,priority:3;index:idx_orders_payer_status_type_created,priority:3;index:idx_orders_payer_status_type_trade,priority:3"`
 	Remark          string          `json:"remark" gorm:"size:255"`
 	PaymentType     string          `json:"payment_type" gorm:"size:20"`
+	NotifyURL       string          `json:"notify_url" gorm:"size:255"`
+	ReturnURL       string          `json:"return_url" gorm:"size:255"`
+	NotifyFailed    bool            `json:"notify_failed" gorm:"not null;default:false"`
 	TradeTime       time.Time       `json:"trade_time" gorm:"index:idx_orders_payer_status_type_trade,priority:4"`
 	ExpiresAt       time.Time       `json:"expires_at" gorm:"not null"`
 	CreatedAt       time.Time       `json:"created_at" gorm:"autoCreateTime;index:idx_orders_payee_status_type_created,priority:4;index:idx_orders_payer_status_type_created,priority:4;index:idx_orders_client_status_created,priority:3"`
@@ -85,15 +91,23 @@ func (o *Order) AfterFind(*gorm.DB) error {
 	return nil
 }
 
-// ExpirePendingOrders 将已过期且 pending 状态的订单设置为 expired
-func ExpirePendingOrders(ctx context.Context) {
-	result := db.DB(ctx).Model(&Order{}).
+// ExpirePendingOrdersBatch 将一批已过期且 pending 状态的订单设置为 expired，返回本次过期的订单
+// 已被其他事务锁定的订单（如正在支付）会被跳过，留给下一批次处理
+func ExpirePendingOrdersBatch(tx *gorm.DB, limit int) ([]Order, error) {
+	var orders []Order
+	subQuery := tx.Session(&gorm.Session{NewDB: true}).
+		Model(&Order{}).
+		Select("id").
 		Where("status = ? AND expires_at <= ?", OrderStatusPending, time.Now()).
-		Update("status", OrderStatusExpired)
-
-	if result.Error != nil {
-		logger.ErrorF(ctx, "过期 pending 订单失败: %v", result.Error)
-	} else {
-		logger.InfoF(ctx, "已将 %d 个已过期的 pending 订单设置为 expired", result.RowsAffected)
+		Order("expires_at ASC").
+		Limit(limit).
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"})
+
+	if err := tx.Model(&orders).
+		Clauses(clause.Returning{}).
+		Where("id IN (?) AND status = ?", subQuery, OrderStatusPending).
+		Update("status", OrderStatusExpired).Error; err != nil {
+		return nil, err
 	}
+	return orders, nil
 }
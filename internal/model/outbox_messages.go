@@ -0,0 +1,45 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"time"
+
+	"github.com/linux-do/credit/internal/db/idgen"
+	"gorm.io/gorm"
+)
+
+// OutboxMessage 事务发件箱消息，与业务数据在同一事务内写入，提交后由中继投递到任务队列
+type OutboxMessage struct {
+	ID             uint64     `json:"id" gorm:"primaryKey"`
+	TaskType       string     `json:"task_type" gorm:"size:64;not null"`
+	Payload        string     `json:"payload" gorm:"type:text;not null"`
+	Queue          string     `json:"queue" gorm:"size:32;not null"`
+	MaxRetry       int        `json:"max_retry" gorm:"not null;default:0"`
+	TimeoutSeconds int        `json:"timeout_seconds" gorm:"not null;default:0"`
+	Attempts       int        `json:"attempts" gorm:"not null;default:0"`
+	LastError      string     `json:"last_error" gorm:"type:text"`
+	PublishedAt    *time.Time `json:"published_at" gorm:"index"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (m *OutboxMessage) BeforeCreate(*gorm.DB) error {
+	if m.ID == 0 {
+		m.ID = idgen.NextUint64ID()
+	}
+	return nil
+}
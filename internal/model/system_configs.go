@@ -25,6 +25,7 @@ import (
 
 	"github.com/redis/go-redis/v9"
 	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
 
 	"github.com/linux-do/credit/internal/db"
 )
@@ -36,8 +37,23 @@ const (
 	ConfigKeyDisputeTimeWindowHours     = "dispute_time_window_hours"     // 商家争议时间窗口（小时）
 	ConfigKeyNewUserInitialCredit       = "new_user_initial_credit"       // 新用户注册初始积分
 	ConfigKeyNewUserProtectionDays      = "new_user_protection_days"      // 新用户保护期天数（期内不扣分）
+	ConfigKeyRefundFeePolicy            = "refund_fee_policy"             // 退款手续费策略（return/retain）
+	ConfigKeyTOTPThreshold              = "totp_threshold"                // 两步验证金额阈值，超过该金额的支付需输入两步验证码
 )
 
+// RefundFeePolicy 退款时手续费的处理策略
+type RefundFeePolicy string
+
+const (
+	// RefundFeePolicyReturn 平台按退款比例退还手续费，商户只承担实际到账部分
+	RefundFeePolicyReturn RefundFeePolicy = "return"
+	// RefundFeePolicyRetain 平台不退还手续费，由商户承担全部退款金额
+	RefundFeePolicyRetain RefundFeePolicy = "retain"
+)
+
+// DefaultTOTPThreshold 未配置两步验证金额阈值时使用的默认值
+var DefaultTOTPThreshold = decimal.NewFromInt(500)
+
 const (
 	// SystemConfigRedisHashKey Redis Hash key，存储所有系统配置
 	SystemConfigRedisHashKey = "system:system_configs"
@@ -102,3 +118,33 @@ func GetDecimalByKey(ctx context.Context, key string, precision int32) (decimal.
 	// 裁剪到指定小数位数
 	return value.Truncate(precision), nil
 }
+
+// GetRefundFeePolicy 查询退款手续费策略，未配置时默认退还手续费
+func GetRefundFeePolicy(ctx context.Context) (RefundFeePolicy, error) {
+	var sc SystemConfig
+	if err := sc.GetByKey(ctx, ConfigKeyRefundFeePolicy); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return RefundFeePolicyReturn, nil
+		}
+		return "", err
+	}
+
+	switch policy := RefundFeePolicy(sc.Value); policy {
+	case RefundFeePolicyReturn, RefundFeePolicyRetain:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("配置 %s 的值 '%s' 无效，可选值为 return 或 retain", ConfigKeyRefundFeePolicy, sc.Value)
+	}
+}
+
+// GetTOTPThreshold 查询两步验证金额阈值，未配置时使用默认值
+func GetTOTPThreshold(ctx context.Context) (decimal.Decimal, error) {
+	threshold, err := GetDecimalByKey(ctx, ConfigKeyTOTPThreshold, 2)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return DefaultTOTPThreshold, nil
+		}
+		return decimal.Zero, err
+	}
+	return threshold, nil
+}
@@ -0,0 +1,41 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"time"
+
+	"github.com/linux-do/credit/internal/db/idgen"
+	"gorm.io/gorm"
+)
+
+// TOTPRecoveryCode 两步验证恢复码，验证器丢失时可代替验证码使用一次
+// 恢复码本身为高熵随机串，只保存其 SHA-256 摘要
+type TOTPRecoveryCode struct {
+	ID        uint64     `json:"id" gorm:"primaryKey"`
+	UserID    uint64     `json:"user_id" gorm:"not null;uniqueIndex:idx_totp_recovery_codes_user_code,priority:1"`
+	CodeHash  string     `json:"-" gorm:"size:64;not null;uniqueIndex:idx_totp_recovery_codes_user_code,priority:2"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (r *TOTPRecoveryCode) BeforeCreate(*gorm.DB) error {
+	if r.ID == 0 {
+		r.ID = idgen.NextUint64ID()
+	}
+	return nil
+}
@@ -93,25 +93,29 @@ type LeaderboardUser struct {
 }
 
 type User struct {
-	ID               uint64          `json:"id" gorm:"primaryKey"`
-	Username         string          `json:"username" gorm:"size:64;uniqueIndex"`
-	Nickname         string          `json:"nickname" gorm:"size:100"`
-	AvatarUrl        string          `json:"avatar_url" gorm:"size:100"`
-	TrustLevel       TrustLevel      `json:"trust_level" gorm:"index"`
-	PayScore         int64           `json:"pay_score" gorm:"default:0;index"`
-	PayKey           string          `json:"pay_key" gorm:"size:128"`
-	SignKey          string          `json:"sign_key" gorm:"size:64;uniqueIndex;not null"`
-	TotalReceive     decimal.Decimal `json:"total_receive" gorm:"type:numeric(20,2);default:0"`
-	TotalPayment     decimal.Decimal `json:"total_payment" gorm:"type:numeric(20,2);default:0"`
-	TotalTransfer    decimal.Decimal `json:"total_transfer" gorm:"type:numeric(20,2);default:0"`
-	TotalCommunity   decimal.Decimal `json:"total_community" gorm:"type:numeric(20,2);default:0"`
-	CommunityBalance decimal.Decimal `json:"community_balance" gorm:"type:numeric(20,2);default:0"`
-	AvailableBalance decimal.Decimal `json:"available_balance" gorm:"type:numeric(20,2);default:0"`
-	IsActive         bool            `json:"is_active" gorm:"default:true"`
-	IsAdmin          bool            `json:"is_admin" gorm:"default:false"`
-	LastLoginAt      time.Time       `json:"last_login_at" gorm:"index"`
-	CreatedAt        time.Time       `json:"created_at" gorm:"autoCreateTime;index"`
-	UpdatedAt        time.Time       `json:"updated_at" gorm:"autoUpdateTime;index"`
+	ID               uint64           `json:"id" gorm:"primaryKey"`
+	Username         string           `json:"username" gorm:"size:64;uniqueIndex"`
+	Nickname         string           `json:"nickname" gorm:"size:100"`
+	AvatarUrl        string           `json:"avatar_url" gorm:"size:100"`
+	TrustLevel       TrustLevel       `json:"trust_level" gorm:"index"`
+	PayScore         int64            `json:"pay_score" gorm:"default:0;index"`
+	PayKey           string           `json:"pay_key" gorm:"size:128"`
+	SignKey          string           `json:"sign_key" gorm:"size:64;uniqueIndex;not null"`
+	TOTPSecret       string           `json:"-" gorm:"column:totp_secret;size:128"`
+	TOTPEnabled      bool             `json:"totp_enabled" gorm:"column:totp_enabled;not null;default:false"`
+	TOTPLastStep     int64            `json:"-" gorm:"column:totp_last_step;not null;default:0"`
+	TOTPThreshold    *decimal.Decimal `json:"totp_threshold" gorm:"column:totp_threshold;type:numeric(20,2)"`
+	TotalReceive     decimal.Decimal  `json:"total_receive" gorm:"type:numeric(20,2);default:0"`
+	TotalPayment     decimal.Decimal  `json:"total_payment" gorm:"type:numeric(20,2);default:0"`
+	TotalTransfer    decimal.Decimal  `json:"total_transfer" gorm:"type:numeric(20,2);default:0"`
+	TotalCommunity   decimal.Decimal  `json:"total_community" gorm:"type:numeric(20,2);default:0"`
+	CommunityBalance decimal.Decimal  `json:"community_balance" gorm:"type:numeric(20,2);default:0"`
+	AvailableBalance decimal.Decimal  `json:"available_balance" gorm:"type:numeric(20,2);default:0"`
+	IsActive         bool             `json:"is_active" gorm:"default:true"`
+	IsAdmin          bool             `json:"is_admin" gorm:"default:false"`
+	LastLoginAt      time.Time        `json:"last_login_at" gorm:"index"`
+	CreatedAt        time.Time        `json:"created_at" gorm:"autoCreateTime;index"`
+	UpdatedAt        time.Time        `json:"updated_at" gorm:"autoUpdateTime;index"`
 }
 
 func (u *User) GetByID(tx *gorm.DB, id uint64) error {
@@ -131,11 +135,15 @@ func GetByIDs(tx *gorm.DB, ids []uint64) ([]User, error) {
 }
 
 // VerifyPayKey 验证用户支付密码
-// 使用用户的 SignKey 解密存储的加密密码，然后与输入的明文密码比较
+// 支付密码以 argon2id 加盐哈希存储；历史数据为使用 SignKey 加密的密文，解密后与输入的明文密码比较
 func (u *User) VerifyPayKey(inputPayKey string) bool {
 	if u.PayKey == "" {
 		return false
 	}
+	if util.IsSecretHash(u.PayKey) {
+		return util.VerifySecret(u.PayKey, inputPayKey)
+	}
+
 	decrypted, err := util.Decrypt(u.SignKey, u.PayKey)
 	if err != nil {
 		return false
@@ -144,6 +152,11 @@ func (u *User) VerifyPayKey(inputPayKey string) bool {
 	return subtle.ConstantTimeCompare([]byte(decrypted), []byte(inputPayKey)) == 1
 }
 
+// PayKeyNeedsRehash 支付密码是否仍为历史加密密文，需要在下次验证成功后迁移为哈希
+func (u *User) PayKeyNeedsRehash() bool {
+	return u.PayKey != "" && !util.IsSecretHash(u.PayKey)
+}
+
 func (u *User) GetUserGamificationScore(ctx context.Context) (*UserGamificationScoreResponse, error) {
 	url := fmt.Sprintf("https://linux.do/u/%s.json", u.Username)
 	resp, err := util.Request(ctx, http.MethodGet, url, nil, nil, nil)
@@ -245,16 +258,14 @@ func (u *User) CreateWithInitialCredit(ctx context.Context, oauthInfo *OAuthUser
 
 		now := time.Now()
 		newUser := User{
-			ID:               oauthInfo.GetID(),
-			Username:         oauthInfo.Username,
-			Nickname:         oauthInfo.Name,
-			AvatarUrl:        oauthInfo.AvatarUrl,
-			IsActive:         oauthInfo.Active,
-			TrustLevel:       oauthInfo.TrustLevel,
-			SignKey:          util.GenerateUniqueIDSimple(),
-			TotalReceive:     newUserInitialCredit,
-			AvailableBalance: newUserInitialCredit,
-			LastLoginAt:      now,
+			ID:          oauthInfo.GetID(),
+			Username:    oauthInfo.Username,
+			Nickname:    oauthInfo.Name,
+			AvatarUrl:   oauthInfo.AvatarUrl,
+			IsActive:    oauthInfo.Active,
+			TrustLevel:  oauthInfo.TrustLevel,
+			SignKey:     util.GenerateUniqueIDSimple(),
+			LastLoginAt: now,
 		}
 		if err = tx.Create(&newUser).Error; err != nil {
 			return err
@@ -275,6 +286,29 @@ func (u *User) CreateWithInitialCredit(ctx context.Context, oauthInfo *OAuthUser
 			return err
 		}
 
+		// 记账：初始积分由社区发行账户拨付
+		if err = PostLedgerEntry(tx, &LedgerEntry{
+			Type:    LedgerEntryInitialCredit,
+			OrderID: order.ID,
+		}, []LedgerLeg{
+			{
+				AccountType: LedgerAccountUser,
+				UserID:      newUser.ID,
+				Amount:      newUserInitialCredit,
+				UserColumns: map[string]interface{}{
+					"total_receive": gorm.Expr("total_receive + ?", newUserInitialCredit),
+				},
+			},
+			{
+				AccountType: LedgerAccountCommunityMint,
+				Amount:      newUserInitialCredit.Neg(),
+			},
+		}); err != nil {
+			return err
+		}
+		newUser.TotalReceive = newUser.TotalReceive.Add(newUserInitialCredit)
+		newUser.AvailableBalance = newUser.AvailableBalance.Add(newUserInitialCredit)
+
 		*u = newUser
 
 		return u.EnqueueBadgeScoreTask(ctx, 0)
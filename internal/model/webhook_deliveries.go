@@ -0,0 +1,106 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/linux-do/credit/internal/db/idgen"
+	"gorm.io/gorm"
+)
+
+// WebhookEvent 商户 Webhook 事件类型
+type WebhookEvent string
+
+const (
+	WebhookEventOrderPaid       WebhookEvent = "order.paid"
+	WebhookEventOrderRefunded   WebhookEvent = "order.refunded"
+	WebhookEventOrderExpired    WebhookEvent = "order.expired"
+	WebhookEventDisputeOpened   WebhookEvent = "dispute.opened"
+	WebhookEventDisputeResolved WebhookEvent = "dispute.resolved"
+)
+
+// WebhookEventList 商户订阅的事件列表，以 JSON 数组形式存储
+type WebhookEventList []WebhookEvent
+
+// DefaultWebhookEvents 未指定订阅时的默认事件，与原有支付成功回调保持一致
+var DefaultWebhookEvents = WebhookEventList{WebhookEventOrderPaid}
+
+// Contains 判断是否订阅了指定事件
+func (l WebhookEventList) Contains(event WebhookEvent) bool {
+	return slices.Contains(l, event)
+}
+
+// Value 实现 driver.Valuer 接口
+func (l WebhookEventList) Value() (driver.Value, error) {
+	if l == nil {
+		l = WebhookEventList{}
+	}
+	data, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan 实现 sql.Scanner 接口
+func (l *WebhookEventList) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*l = WebhookEventList{}
+		return nil
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return errors.New("webhook events 类型不支持")
+	}
+	return json.Unmarshal(data, l)
+}
+
+// WebhookDelivery 商户回调投递记录，每次回调尝试记录一条
+type WebhookDelivery struct {
+	ID           uint64    `json:"id" gorm:"primaryKey"`
+	OrderID      uint64    `json:"order_id" gorm:"not null;index:idx_webhook_deliveries_order_created,priority:1"`
+	ClientID     string    `json:"client_id" gorm:"size:64;not null;index:idx_webhook_deliveries_client_created,priority:1"`
+	Event        string    `json:"event" gorm:"size:32;not null;default:'order.paid'"`
+	EventID      string    `json:"event_id" gorm:"size:64;index"`
+	URL          string    `json:"url" gorm:"type:text;not null"`
+	Method       string    `json:"method" gorm:"size:8;not null"`
+	Params       string    `json:"params" gorm:"type:text;not null"`
+	HTTPStatus   int       `json:"http_status" gorm:"not null;default:0"`
+	ResponseBody string    `json:"response_body" gorm:"type:text"`
+	Error        string    `json:"error" gorm:"type:text"`
+	LatencyMs    int64     `json:"latency_ms" gorm:"not null;default:0"`
+	RetryCount   int       `json:"retry_count" gorm:"not null;default:0"`
+	Manual       bool      `json:"manual" gorm:"not null;default:false"`
+	Success      bool      `json:"success" gorm:"not null;default:false"`
+	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime;index:idx_webhook_deliveries_order_created,priority:2;index:idx_webhook_deliveries_client_created,priority:2"`
+}
+
+func (d *WebhookDelivery) BeforeCreate(*gorm.DB) error {
+	if d.ID == 0 {
+		d.ID = idgen.NextUint64ID()
+	}
+	return nil
+}
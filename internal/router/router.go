@@ -32,6 +32,8 @@ import (
 	"github.com/linux-do/credit/internal/apps/dispute"
 	"github.com/linux-do/credit/internal/apps/merchant/api_key"
 	"github.com/linux-do/credit/internal/apps/merchant/link"
+	"github.com/linux-do/credit/internal/apps/notification"
+	"github.com/linux-do/credit/internal/leader"
 	"github.com/linux-do/credit/internal/listener"
 	"github.com/linux-do/credit/internal/util"
 
@@ -41,10 +43,14 @@ import (
 	"github.com/gin-contrib/sessions/redis"
 	"github.com/gin-gonic/gin"
 	_ "github.com/linux-do/credit/docs"
+	"github.com/linux-do/credit/internal/apps/admin/fee_revenue"
+	"github.com/linux-do/credit/internal/apps/admin/pay_key_lock"
+	"github.com/linux-do/credit/internal/apps/admin/reconciliation"
 	"github.com/linux-do/credit/internal/apps/admin/system_config"
 	"github.com/linux-do/credit/internal/apps/admin/user_pay_config"
 	"github.com/linux-do/credit/internal/apps/dashboard"
 	"github.com/linux-do/credit/internal/apps/health"
+	"github.com/linux-do/credit/internal/apps/idempotency"
 	"github.com/linux-do/credit/internal/apps/oauth"
 	"github.com/linux-do/credit/internal/apps/order"
 	"github.com/linux-do/credit/internal/apps/user"
@@ -101,10 +107,12 @@ func Serve() {
 
 	// 支付接口
 	r.POST("/pay/submit.php", payment.RequireSignatureAuth(), payment.CreateMerchantOrder)
-	// 查询订单
-	r.GET("/api.php", payment.QueryMerchantOrder)
-	// 退款接口
-	r.POST("/api.php", payment.RefundMerchantOrder)
+	// 支付接口（API 方式）
+	r.POST("/mapi.php", payment.RequireSignatureAuth(), payment.CreateMerchantOrderAPI)
+	// 查询接口（act=order 查询订单，act=orders 批量查询订单）
+	r.GET("/api.php", payment.EPayQuery)
+	// 操作接口（act=refund 退款，act=close 关闭订单）
+	r.POST("/api.php", payment.EPayAction)
 
 	apiGroup := r.Group(config.Config.App.APIPrefix)
 	{
@@ -130,6 +138,21 @@ func Serve() {
 			userRouter.Use(oauth.LoginRequired())
 			{
 				userRouter.PUT("/pay-key", user.UpdatePayKey)
+				userRouter.GET("/totp", user.GetTOTPStatus)
+				userRouter.POST("/totp/setup", user.SetupTOTP)
+				userRouter.POST("/totp/enable", user.EnableTOTP)
+				userRouter.POST("/totp/disable", user.DisableTOTP)
+				userRouter.POST("/totp/recovery-codes", user.RegenerateTOTPRecoveryCodes)
+				userRouter.PUT("/totp/threshold", user.UpdateTOTPThreshold)
+			}
+
+			// Notification
+			notificationRouter := apiV1Router.Group("/notifications")
+			notificationRouter.Use(oauth.LoginRequired())
+			{
+				notificationRouter.GET("", notification.ListNotifications)
+				notificationRouter.POST("/read-all", notification.ReadAllNotifications)
+				notificationRouter.POST("/:id/read", notification.ReadNotification)
 			}
 
 			// Dashboard
@@ -148,7 +171,7 @@ func Serve() {
 				orderRouter.POST("/dispute", dispute.CreateDispute)
 				orderRouter.POST("/disputes/merchant", dispute.ListMerchantDisputes)
 				orderRouter.POST("/disputes", dispute.ListDisputes)
-				orderRouter.POST("/refund-review", dispute.RefundReview)
+				orderRouter.POST("/refund-review", idempotency.WithIdempotencyKey(), dispute.RefundReview)
 				orderRouter.POST("/dispute/close", dispute.CloseDispute)
 			}
 
@@ -156,7 +179,7 @@ func Serve() {
 			paymentRouter := apiV1Router.Group("/payment")
 			paymentRouter.Use(oauth.LoginRequired())
 			{
-				paymentRouter.POST("/transfer", payment.Transfer)
+				paymentRouter.POST("/transfer", idempotency.WithIdempotencyKey(), payment.Transfer)
 			}
 
 			// Config (public)
@@ -186,16 +209,30 @@ func Serve() {
 						linkRouter.POST("", link.CreatePaymentLink)
 						linkRouter.DELETE("/:linkId", link.DeletePaymentLink)
 					}
+
+					// Webhook Deliveries
+					webhookRouter := apiKeyRouter.Group("/webhook-deliveries")
+					{
+						webhookRouter.GET("", payment.ListWebhookDeliveries)
+						webhookRouter.POST("", payment.RedeliverWebhook)
+					}
+
+					// Notify Failed Orders
+					notifyFailedRouter := apiKeyRouter.Group("/notify-failed-orders")
+					{
+						notifyFailedRouter.GET("", payment.ListNotifyFailedOrders)
+						notifyFailedRouter.POST("/retry", payment.RetryNotifyFailedOrders)
+					}
 				}
 
 				merchantRouter.GET("/payment-links/:token", oauth.LoginRequired(), link.GetPaymentLinkByToken)
-				merchantRouter.POST("/payment-links/pay", oauth.LoginRequired(), link.PayByLink)
+				merchantRouter.POST("/payment-links/pay", oauth.LoginRequired(), idempotency.WithIdempotencyKey(), link.PayByLink)
 
 				// MerchantAPIKey Payment
 				MerchantPaymentRouter := merchantRouter.Group("/payment")
 				{
 					MerchantPaymentRouter.GET("/order", oauth.LoginRequired(), payment.GetPaymentPageDetails)
-					MerchantPaymentRouter.POST("", oauth.LoginRequired(), payment.PayMerchantOrder)
+					MerchantPaymentRouter.POST("", oauth.LoginRequired(), idempotency.WithIdempotencyKey(), payment.PayMerchantOrder)
 				}
 			}
 
@@ -224,15 +261,51 @@ func Serve() {
 					userPayConfigRouter.PUT("", user_pay_config.UpdateUserPayConfig)
 					userPayConfigRouter.DELETE("", user_pay_config.DeleteUserPayConfig)
 				}
+
+				// Fee Revenue
+				adminRouter.GET("/fee-revenue", fee_revenue.GetFeeRevenue)
+
+				// Pay Key Lock
+				adminRouter.GET("/pay-key-locks", pay_key_lock.GetPayKeyLock)
+				adminRouter.DELETE("/pay-key-locks/:user_id", pay_key_lock.UnlockPayKey)
+
+				// Balance Reconciliation
+				adminRouter.GET("/balance-discrepancies", reconciliation.ListBalanceDiscrepancies)
+				adminRouter.POST("/balance-discrepancies/reconcile", reconciliation.TriggerReconcile)
+				adminRouter.POST("/balance-discrepancies/:id/repair", reconciliation.RepairBalanceDiscrepancy)
+			}
+		}
+
+		// API V2
+		apiV2Router := apiGroup.Group("/v2")
+		{
+			// Merchant API
+			merchantV2Router := apiV2Router.Group("/merchant")
+			merchantV2Router.Use(payment.RequireMerchantAuth())
+			{
+				merchantV2Router.POST("/orders", payment.MerchantAPICreateOrder)
+				merchantV2Router.GET("/orders", payment.MerchantAPIListOrders)
+				merchantV2Router.GET("/orders/:trade_no", payment.MerchantAPIGetOrder)
+				merchantV2Router.POST("/orders/:trade_no/close", payment.MerchantAPICloseOrder)
+				merchantV2Router.POST("/orders/:trade_no/refunds", payment.MerchantAPICreateRefund)
+				merchantV2Router.GET("/orders/:trade_no/refunds", payment.MerchantAPIListRefunds)
+				merchantV2Router.GET("/balance", payment.MerchantAPIGetBalance)
 			}
 		}
 	}
 
-	expireListenerCtx, expireListenerCancel := context.WithCancel(context.Background())
+	listenerCtx, listenerCancel := context.WithCancel(context.Background())
 
-	if err := listener.StartExpireListener(expireListenerCtx); err != nil {
-		log.Fatalf("[API] 警告: 启动过期监听器失败: %v\n", err)
-	}
+	// 过期监听器仅在 Leader 实例上运行，避免多副本重复处理同一过期事件
+	leader.Run(listenerCtx, listener.ExpireListenerLeaderName, func(ctx context.Context) {
+		if err := listener.StartExpireListener(ctx); err != nil {
+			log.Printf("[API] 警告: 启动过期监听器失败: %v\n", err)
+			return
+		}
+		<-ctx.Done()
+	})
+
+	listener.StartOutboxRelay(listenerCtx)
 
 	srv := &http.Server{
 		Addr:    config.Config.App.Addr,
@@ -252,7 +325,7 @@ func Serve() {
 
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(config.Config.App.GracefulShutdownTimeout)*time.Second)
 	defer cancel()
-	defer expireListenerCancel()
+	defer listenerCancel()
 
 	otel_trace.Shutdown(shutdownCtx)
 
@@ -0,0 +1,143 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"github.com/linux-do/credit/internal/model"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// PostOrderPayment 记账：付款方支付订单金额，商户收到扣除手续费后的金额，手续费计入平台手续费账户
+// 付款方余额不足时返回 common.InsufficientBalance
+func PostOrderPayment(tx *gorm.DB, order *model.Order, merchantUserID uint64, merchantAmount decimal.Decimal, merchantScoreIncrease int64) error {
+	legs := []model.LedgerLeg{
+		{
+			AccountType: model.LedgerAccountUser,
+			UserID:      order.PayerUserID,
+			Amount:      order.Amount.Neg(),
+			UserColumns: map[string]interface{}{
+				"total_payment": gorm.Expr("total_payment + ?", order.Amount),
+				"pay_score":     gorm.Expr("pay_score + ?", order.Amount.Round(0).IntPart()),
+			},
+			CheckBalance: true,
+		},
+		{
+			AccountType: model.LedgerAccountUser,
+			UserID:      merchantUserID,
+			Amount:      merchantAmount,
+			UserColumns: map[string]interface{}{
+				"total_receive": gorm.Expr("total_receive + ?", merchantAmount),
+				"pay_score":     gorm.Expr("pay_score + ?", merchantScoreIncrease),
+			},
+		},
+	}
+	if fee := order.Amount.Sub(merchantAmount); !fee.IsZero() {
+		legs = append(legs, model.LedgerLeg{
+			AccountType: model.LedgerAccountPlatformFee,
+			Amount:      fee,
+		})
+	}
+
+	return model.PostLedgerEntry(tx, &model.LedgerEntry{
+		Type:    model.LedgerEntryPayment,
+		OrderID: order.ID,
+	}, legs)
+}
+
+// PostOrderRefund 记账：付款方收回退款金额，其中退还的手续费由平台手续费账户承担，其余由商户承担
+// 同时扣减双方的统计字段和积分
+func PostOrderRefund(tx *gorm.DB, entryType model.LedgerEntryType, order *model.Order, amount decimal.Decimal, feeRefund decimal.Decimal, merchantScoreDecrease int64, memo string) error {
+	merchantAmount := amount.Sub(feeRefund)
+	legs := []model.LedgerLeg{
+		{
+			AccountType: model.LedgerAccountUser,
+			UserID:      order.PayeeUserID,
+			Amount:      merchantAmount.Neg(),
+			UserColumns: map[string]interface{}{
+				"total_receive": gorm.Expr("total_receive - ?", merchantAmount),
+				"pay_score":     gorm.Expr("pay_score - ?", merchantScoreDecrease),
+			},
+		},
+		{
+			AccountType: model.LedgerAccountUser,
+			UserID:      order.PayerUserID,
+			Amount:      amount,
+			UserColumns: map[string]interface{}{
+				"total_payment": gorm.Expr("total_payment - ?", amount),
+				"pay_score":     gorm.Expr("pay_score - ?", amount.Round(0).IntPart()),
+			},
+		},
+	}
+	if !feeRefund.IsZero() {
+		legs = append(legs, model.LedgerLeg{
+			AccountType: model.LedgerAccountPlatformFee,
+			Amount:      feeRefund.Neg(),
+		})
+	}
+
+	return model.PostLedgerEntry(tx, &model.LedgerEntry{
+		Type:    entryType,
+		OrderID: order.ID,
+		Memo:    memo,
+	}, legs)
+}
+
+// PostTransfer 记账：用户间转账
+// 付款方余额不足时返回 common.InsufficientBalance
+func PostTransfer(tx *gorm.DB, order *model.Order) error {
+	return model.PostLedgerEntry(tx, &model.LedgerEntry{
+		Type:    model.LedgerEntryTransfer,
+		OrderID: order.ID,
+	}, []model.LedgerLeg{
+		{
+			AccountType: model.LedgerAccountUser,
+			UserID:      order.PayerUserID,
+			Amount:      order.Amount.Neg(),
+			UserColumns: map[string]interface{}{
+				"total_transfer": gorm.Expr("total_transfer + ?", order.Amount),
+			},
+			CheckBalance: true,
+		},
+		{
+			AccountType: model.LedgerAccountUser,
+			UserID:      order.PayeeUserID,
+			Amount:      order.Amount,
+			UserColumns: map[string]interface{}{
+				"total_receive": gorm.Expr("total_receive + ?", order.Amount),
+			},
+		},
+	})
+}
+
+// PostBalanceAdjustment 记账：对账修复时按差额调整用户可用余额，对方科目为对账调整账户
+func PostBalanceAdjustment(tx *gorm.DB, userID uint64, amount decimal.Decimal, memo string) error {
+	return model.PostLedgerEntry(tx, &model.LedgerEntry{
+		Type: model.LedgerEntryAdjustment,
+		Memo: memo,
+	}, []model.LedgerLeg{
+		{
+			AccountType: model.LedgerAccountUser,
+			UserID:      userID,
+			Amount:      amount,
+		},
+		{
+			AccountType: model.LedgerAccountReconciliation,
+			Amount:      amount.Neg(),
+		},
+	})
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+
+	"github.com/linux-do/credit/internal/db"
+	"github.com/linux-do/credit/internal/model"
+	"gorm.io/gorm"
+)
+
+// ExpirePendingOrders 分批将已过期的 pending 订单设置为 expired，并在同一事务内下发订单过期事件
+// 返回本次过期的订单总数
+func ExpirePendingOrders(ctx context.Context, batchSize int) (int, error) {
+	total := 0
+	for {
+		var expired int
+		if err := db.DB(ctx).Transaction(func(tx *gorm.DB) error {
+			orders, err := model.ExpirePendingOrdersBatch(tx, batchSize)
+			if err != nil {
+				return err
+			}
+			for i := range orders {
+				if err := EnqueueWebhookEvent(tx, &orders[i], model.WebhookEventOrderExpired, nil); err != nil {
+					return err
+				}
+			}
+			expired = len(orders)
+			return nil
+		}); err != nil {
+			return total, err
+		}
+
+		total += expired
+		if expired < batchSize {
+			return total, nil
+		}
+	}
+}
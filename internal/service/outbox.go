@@ -0,0 +1,41 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/linux-do/credit/internal/model"
+	"gorm.io/gorm"
+)
+
+// EnqueueOutboxTask 在事务内写入发件箱消息，事务提交后由中继投递到任务队列
+// 事务回滚时消息随之丢弃，任务队列不可用也不会影响事务提交
+func EnqueueOutboxTask(tx *gorm.DB, taskType string, payload interface{}, queue string, maxRetry int, timeout time.Duration) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return tx.Create(&model.OutboxMessage{
+		TaskType:       taskType,
+		Payload:        string(data),
+		Queue:          queue,
+		MaxRetry:       maxRetry,
+		TimeoutSeconds: int(timeout / time.Second),
+	}).Error
+}
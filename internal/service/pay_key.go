@@ -0,0 +1,225 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/linux-do/credit/internal/common"
+	"github.com/linux-do/credit/internal/config"
+	"github.com/linux-do/credit/internal/db"
+	"github.com/linux-do/credit/internal/logger"
+	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/util"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	payKeyFailuresKeyFormat  = "pay_key:failures:%d"
+	payKeyLockLevelKeyFormat = "pay_key:lock_level:%d"
+	payKeyLockedKeyFormat    = "pay_key:locked:%d"
+
+	payKeyLockedNotificationTitle   = "支付密码已锁定"
+	payKeyLockedNotificationContent = "支付密码或两步验证码连续错误 %d 次，已锁定至 %s。如非本人操作，请尽快修改支付密码。"
+)
+
+// payKeyFailureScript 累计一次支付密码错误，达到阈值时按锁定梯度加锁
+// KEYS: 错误次数、锁定梯度、锁定标记
+// ARGV: 锁定阈值、统计窗口毫秒、首次锁定毫秒、最长锁定毫秒、梯度重置毫秒
+// 返回：{累计错误次数, 本次锁定毫秒（0 表示未锁定）}
+var payKeyFailureScript = redis.NewScript(`
+local failures = redis.call("INCR", KEYS[1])
+redis.call("PEXPIRE", KEYS[1], ARGV[2])
+if failures < tonumber(ARGV[1]) then
+	return {failures, 0}
+end
+redis.call("DEL", KEYS[1])
+local level = redis.call("INCR", KEYS[2])
+local lockMs = tonumber(ARGV[4])
+if level <= 32 then
+	lockMs = math.min(tonumber(ARGV[3]) * 2 ^ (level - 1), lockMs)
+end
+redis.call("PEXPIRE", KEYS[2], lockMs + tonumber(ARGV[5]))
+redis.call("SET", KEYS[3], level, "PX", lockMs)
+return {failures, lockMs}
+`)
+
+// PayKeyLockStatus 支付密码锁定状态
+type PayKeyLockStatus struct {
+	FailedAttempts int64      `json:"failed_attempts"`
+	LockLevel      int64      `json:"lock_level"`
+	LockedUntil    *time.Time `json:"locked_until"`
+}
+
+// VerifyPayKey 校验用户支付密码，转账、商户支付、链接支付共用同一错误计数
+// 统计窗口内连续错误达到阈值后锁定，锁定时长逐次翻倍；锁定期间不再校验密码
+func VerifyPayKey(ctx context.Context, user *model.User, payKey string) error {
+	if err := checkPayKeyLocked(ctx, user.ID); err != nil {
+		return err
+	}
+
+	if user.VerifyPayKey(payKey) {
+		if err := db.Redis.Del(ctx, db.PrefixedKey(fmt.Sprintf(payKeyFailuresKeyFormat, user.ID))).Err(); err != nil {
+			logger.ErrorF(ctx, "[PayKey] 清除支付密码错误次数失败: user_id=%d, error=%v", user.ID, err)
+		}
+		if user.PayKeyNeedsRehash() {
+			rehashPayKey(ctx, user, payKey)
+		}
+		return nil
+	}
+
+	if err := recordPayKeyFailure(ctx, user); err != nil {
+		return err
+	}
+	return errors.New(common.PayKeyIncorrect)
+}
+
+// checkPayKeyLocked 支付密码处于锁定期间时返回 common.PayKeyLocked
+func checkPayKeyLocked(ctx context.Context, userID uint64) error {
+	lockedFor, err := db.Redis.PTTL(ctx, db.PrefixedKey(fmt.Sprintf(payKeyLockedKeyFormat, userID))).Result()
+	if err != nil {
+		return err
+	}
+	if lockedFor > 0 {
+		return errors.New(common.PayKeyLocked)
+	}
+	return nil
+}
+
+// recordPayKeyFailure 累计一次支付验证错误，支付密码与两步验证码共用错误计数
+// 本次错误触发锁定时发送提醒并返回 common.PayKeyLocked
+func recordPayKeyFailure(ctx context.Context, user *model.User) error {
+	cfg := payKeyConfig()
+	result, err := payKeyFailureScript.Run(ctx, db.Redis,
+		[]string{
+			db.PrefixedKey(fmt.Sprintf(payKeyFailuresKeyFormat, user.ID)),
+			db.PrefixedKey(fmt.Sprintf(payKeyLockLevelKeyFormat, user.ID)),
+			db.PrefixedKey(fmt.Sprintf(payKeyLockedKeyFormat, user.ID)),
+		},
+		cfg.MaxAttempts,
+		(time.Duration(cfg.FailureWindowMinutes) * time.Minute).Milliseconds(),
+		(time.Duration(cfg.BaseLockMinutes) * time.Minute).Milliseconds(),
+		(time.Duration(cfg.MaxLockMinutes) * time.Minute).Milliseconds(),
+		(time.Duration(cfg.LockResetHours) * time.Hour).Milliseconds(),
+	).Int64Slice()
+	if err != nil {
+		return err
+	}
+
+	lockMs := result[1]
+	if lockMs <= 0 {
+		return nil
+	}
+
+	lockedUntil := time.Now().Add(time.Duration(lockMs) * time.Millisecond)
+	logger.InfoF(ctx, "[PayKey] 支付验证连续错误已锁定: user_id=%d, failures=%d, locked_until=%s",
+		user.ID, result[0], lockedUntil.Format(time.DateTime))
+
+	notification := model.Notification{
+		UserID:  user.ID,
+		Type:    model.NotificationTypePayKeyLocked,
+		Title:   payKeyLockedNotificationTitle,
+		Content: fmt.Sprintf(payKeyLockedNotificationContent, result[0], lockedUntil.Format(time.DateTime)),
+		Link:    "/settings/security",
+	}
+	if err := db.DB(ctx).Create(&notification).Error; err != nil {
+		logger.ErrorF(ctx, "[PayKey] 创建支付密码锁定提醒失败: user_id=%d, error=%v", user.ID, err)
+	}
+	return errors.New(common.PayKeyLocked)
+}
+
+// rehashPayKey 将历史加密密文形式的支付密码迁移为哈希，失败不影响本次支付
+// 仅在存储值未被并发修改时更新，避免覆盖用户刚设置的新密码
+func rehashPayKey(ctx context.Context, user *model.User, payKey string) {
+	hashed, err := util.HashSecret(payKey)
+	if err != nil {
+		logger.ErrorF(ctx, "[PayKey] 生成支付密码哈希失败: user_id=%d, error=%v", user.ID, err)
+		return
+	}
+
+	if err := db.DB(ctx).Model(&model.User{}).
+		Where("id = ? AND pay_key = ?", user.ID, user.PayKey).
+		Update("pay_key", hashed).Error; err != nil {
+		logger.ErrorF(ctx, "[PayKey] 迁移支付密码哈希失败: user_id=%d, error=%v", user.ID, err)
+		return
+	}
+	user.PayKey = hashed
+}
+
+// PayKeyRecentlyAuthenticated 判断登录时间是否在免验证原支付密码的时间窗口内
+func PayKeyRecentlyAuthenticated(loginAt time.Time) bool {
+	if loginAt.IsZero() {
+		return false
+	}
+	return time.Since(loginAt) <= time.Duration(payKeyConfig().ReauthMinutes)*time.Minute
+}
+
+// GetPayKeyLockStatus 查询用户支付密码的错误次数和锁定状态
+func GetPayKeyLockStatus(ctx context.Context, userID uint64) (*PayKeyLockStatus, error) {
+	pipe := db.Redis.Pipeline()
+	failuresCmd := pipe.Get(ctx, db.PrefixedKey(fmt.Sprintf(payKeyFailuresKeyFormat, userID)))
+	levelCmd := pipe.Get(ctx, db.PrefixedKey(fmt.Sprintf(payKeyLockLevelKeyFormat, userID)))
+	lockedCmd := pipe.PTTL(ctx, db.PrefixedKey(fmt.Sprintf(payKeyLockedKeyFormat, userID)))
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	status := &PayKeyLockStatus{}
+	status.FailedAttempts, _ = failuresCmd.Int64()
+	status.LockLevel, _ = levelCmd.Int64()
+	if lockedFor := lockedCmd.Val(); lockedFor > 0 {
+		lockedUntil := time.Now().Add(lockedFor)
+		status.LockedUntil = &lockedUntil
+	}
+	return status, nil
+}
+
+// UnlockPayKey 解除用户支付密码锁定，并清空错误次数和锁定梯度
+func UnlockPayKey(ctx context.Context, userID uint64) error {
+	return db.Redis.Del(ctx,
+		db.PrefixedKey(fmt.Sprintf(payKeyFailuresKeyFormat, userID)),
+		db.PrefixedKey(fmt.Sprintf(payKeyLockLevelKeyFormat, userID)),
+		db.PrefixedKey(fmt.Sprintf(payKeyLockedKeyFormat, userID)),
+	).Err()
+}
+
+// payKeyConfig 返回支付密码防暴力破解配置，未配置的项使用默认值
+func payKeyConfig() config.PayKeyConfig {
+	cfg := config.Config.PayKey
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 5
+	}
+	if cfg.FailureWindowMinutes <= 0 {
+		cfg.FailureWindowMinutes = 60
+	}
+	if cfg.BaseLockMinutes <= 0 {
+		cfg.BaseLockMinutes = 15
+	}
+	if cfg.MaxLockMinutes <= 0 {
+		cfg.MaxLockMinutes = 1440
+	}
+	if cfg.LockResetHours <= 0 {
+		cfg.LockResetHours = 24
+	}
+	if cfg.ReauthMinutes <= 0 {
+		cfg.ReauthMinutes = 10
+	}
+	return cfg
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package service
 
 import (
+	"context"
 	"errors"
 	"time"
 
@@ -40,20 +41,8 @@ func CheckDailyLimit(tx *gorm.DB, userID uint64, amount decimal.Decimal, dailyLi
 		return err
 	}
 
-	todayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-	todayEnd := todayStart.Add(24 * time.Hour)
-
-	// 统计当日成功支付的订单总金额
-	var todayTotalAmount decimal.Decimal
-	if err := tx.Model(&model.Order{}).
-		Where("payer_user_id = ? AND status = ? AND type IN ? AND trade_time >= ? AND trade_time < ?",
-			userID,
-			model.OrderStatusSuccess,
-			[]model.OrderType{model.OrderTypePayment, model.OrderTypeOnline},
-			todayStart,
-			todayEnd).
-		Select("COALESCE(SUM(amount), 0)").
-		Scan(&todayTotalAmount).Error; err != nil {
+	todayTotalAmount, err := GetTodayUsedAmount(tx, userID)
+	if err != nil {
 		return err
 	}
 
@@ -65,38 +54,6 @@ func CheckDailyLimit(tx *gorm.DB, userID uint64, amount decimal.Decimal, dailyLi
 	return nil
 }
 
-// DeductUserBalance 扣减用户余额
-// 返回 nil 表示扣减成功，返回 error 表示余额不足或更新失败
-func DeductUserBalance(tx *gorm.DB, userID uint64, amount decimal.Decimal) error {
-	result := tx.Model(&model.User{}).
-		Where("id = ? AND available_balance >= ?", userID, amount).
-		UpdateColumns(map[string]interface{}{
-			"available_balance": gorm.Expr("available_balance - ?", amount),
-			"total_payment":     gorm.Expr("total_payment + ?", amount),
-			"pay_score":         gorm.Expr("pay_score + ?", amount.Round(0).IntPart()),
-		})
-
-	if result.Error != nil {
-		return result.Error
-	}
-	if result.RowsAffected == 0 {
-		return errors.New(common.InsufficientBalance)
-	}
-
-	return nil
-}
-
-// AddMerchantBalance 增加商户余额和积分
-func AddMerchantBalance(tx *gorm.DB, merchantUserID uint64, amount decimal.Decimal, scoreIncrease int64) error {
-	return tx.Model(&model.User{}).
-		Where("id = ?", merchantUserID).
-		UpdateColumns(map[string]interface{}{
-			"available_balance": gorm.Expr("available_balance + ?", amount),
-			"total_receive":     gorm.Expr("total_receive + ?", amount),
-			"pay_score":         gorm.Expr("pay_score + ?", scoreIncrease),
-		}).Error
-}
-
 // CalculateFee 计算手续费和商户实收金额
 // 返回：手续费、商户实收金额、手续费百分比
 func CalculateFee(amount decimal.Decimal, feeRate decimal.Decimal) (fee decimal.Decimal, merchantAmount decimal.Decimal, feePercent int64) {
@@ -106,7 +63,30 @@ func CalculateFee(amount decimal.Decimal, feeRate decimal.Decimal) (fee decimal.
 	return
 }
 
+// CalculateRefundFee 按退款手续费策略计算本次退款应退还的手续费
+// 按退款金额占订单金额的比例分摊，累计退款达到订单金额时退还全部剩余手续费，避免分次退款的舍入误差
+func CalculateRefundFee(ctx context.Context, order *model.Order, amount decimal.Decimal) (decimal.Decimal, error) {
+	if order.Fee.IsZero() || order.Amount.IsZero() {
+		return decimal.Zero, nil
+	}
+
+	policy, err := model.GetRefundFeePolicy(ctx)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if policy == model.RefundFeePolicyRetain {
+		return decimal.Zero, nil
+	}
+
+	remainingFee := order.Fee.Sub(order.RefundedFee)
+	if order.RefundedAmount.Add(amount).GreaterThanOrEqual(order.Amount) {
+		return remainingFee, nil
+	}
+	return decimal.Min(amount.Mul(order.Fee).Div(order.Amount).Round(2), remainingFee), nil
+}
+
 // GetTodayUsedAmount 获取用户当日已使用的支付额度
+// 部分退款的订单按未退款部分计入，避免通过小额退款使整笔订单移出限额统计
 func GetTodayUsedAmount(db *gorm.DB, userID uint64) (decimal.Decimal, error) {
 	now := time.Now()
 	todayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
@@ -114,13 +94,13 @@ func GetTodayUsedAmount(db *gorm.DB, userID uint64) (decimal.Decimal, error) {
 
 	var todayTotalAmount decimal.Decimal
 	if err := db.Model(&model.Order{}).
-		Where("payer_user_id = ? AND status = ? AND type IN ? AND trade_time >= ? AND trade_time < ?",
+		Where("payer_user_id = ? AND status IN ? AND type IN ? AND trade_time >= ? AND trade_time < ?",
 			userID,
-			model.OrderStatusSuccess,
+			[]model.OrderStatus{model.OrderStatusSuccess, model.OrderStatusPartiallyRefunded},
 			[]model.OrderType{model.OrderTypePayment, model.OrderTypeOnline},
 			todayStart,
 			todayEnd).
-		Select("COALESCE(SUM(amount), 0)").
+		Select("COALESCE(SUM(amount - refunded_amount), 0)").
 		Scan(&todayTotalAmount).Error; err != nil {
 		return decimal.Zero, err
 	}
@@ -0,0 +1,192 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/linux-do/credit/internal/common"
+	"github.com/linux-do/credit/internal/config"
+	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/util"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+const (
+	totpRecoveryCodeCount = 10
+	totpRecoveryCodeLen   = 10
+)
+
+// ErrTOTPUnavailable 未配置两步验证加密密钥时返回，调用方通过 errors.Is 判断
+var ErrTOTPUnavailable = errors.New(common.TOTPUnavailable)
+
+// TOTPThreshold 返回用户实际生效的两步验证金额阈值
+// 用户可自行设置低于系统阈值的金额，高于系统阈值时以系统阈值为准
+func TOTPThreshold(ctx context.Context, user *model.User) (decimal.Decimal, error) {
+	threshold, err := model.GetTOTPThreshold(ctx)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if user.TOTPThreshold != nil && user.TOTPThreshold.LessThan(threshold) {
+		return *user.TOTPThreshold, nil
+	}
+	return threshold, nil
+}
+
+// VerifyTOTPForPayment 已开启两步验证的用户支付金额超过阈值时，校验两步验证码或恢复码
+// 应传入支付事务，支付失败回滚时验证码或恢复码不会被消耗；未携带验证码时返回 common.TOTPRequired
+func VerifyTOTPForPayment(ctx context.Context, tx *gorm.DB, user *model.User, amount decimal.Decimal, code string) error {
+	if !user.TOTPEnabled {
+		return nil
+	}
+
+	threshold, err := TOTPThreshold(ctx, user)
+	if err != nil {
+		return err
+	}
+	if amount.LessThanOrEqual(threshold) {
+		return nil
+	}
+
+	if code == "" {
+		return errors.New(common.TOTPRequired)
+	}
+	return VerifyTOTPCode(ctx, tx, user, code)
+}
+
+// VerifyTOTPCode 校验两步验证码或恢复码，错误与支付密码共用错误计数和锁定
+// 验证码的使用记录通过 tx 写入，随事务一同提交或回滚；错误计数不受事务回滚影响
+func VerifyTOTPCode(ctx context.Context, tx *gorm.DB, user *model.User, code string) error {
+	if err := checkPayKeyLocked(ctx, user.ID); err != nil {
+		return err
+	}
+
+	ok, err := consumeTOTPCode(tx, user, code)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return nil
+	}
+
+	if err := recordPayKeyFailure(ctx, user); err != nil {
+		return err
+	}
+	return errors.New(common.TOTPIncorrect)
+}
+
+// consumeTOTPCode 使用一次验证码或恢复码，同一时间步的验证码和同一恢复码都只能使用一次
+func consumeTOTPCode(tx *gorm.DB, user *model.User, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+
+	if len(code) == util.TOTPDigits {
+		secret, err := DecryptTOTPSecret(user.TOTPSecret)
+		if errors.Is(err, ErrTOTPUnavailable) {
+			return false, err
+		}
+		if err != nil {
+			return false, fmt.Errorf("failed to decrypt totp secret: %w", err)
+		}
+
+		step, ok := util.ValidateTOTP(secret, code, time.Now())
+		if !ok {
+			return false, nil
+		}
+
+		result := tx.Model(&model.User{}).
+			Where("id = ? AND totp_last_step < ?", user.ID, step).
+			Update("totp_last_step", step)
+		if result.Error != nil {
+			return false, result.Error
+		}
+		if result.RowsAffected == 0 {
+			return false, nil
+		}
+		user.TOTPLastStep = step
+		return true, nil
+	}
+
+	result := tx.Model(&model.TOTPRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", user.ID, hashTOTPRecoveryCode(code)).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// EncryptTOTPSecret 使用服务端配置的密钥加密两步验证密钥，密钥不与密文存放在同一数据库中
+func EncryptTOTPSecret(secret string) (string, error) {
+	if config.Config.TOTP.EncryptionKey == "" {
+		return "", ErrTOTPUnavailable
+	}
+	return util.Encrypt(config.Config.TOTP.EncryptionKey, secret)
+}
+
+// DecryptTOTPSecret 使用服务端配置的密钥解密两步验证密钥
+func DecryptTOTPSecret(ciphertext string) (string, error) {
+	if config.Config.TOTP.EncryptionKey == "" {
+		return "", ErrTOTPUnavailable
+	}
+	return util.Decrypt(config.Config.TOTP.EncryptionKey, ciphertext)
+}
+
+// ResetTOTPRecoveryCodes 作废用户已有的恢复码并生成一组新的恢复码
+// return: 恢复码明文，仅在生成时返回一次
+func ResetTOTPRecoveryCodes(tx *gorm.DB, userID uint64) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&model.TOTPRecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, totpRecoveryCodeCount)
+	records := make([]model.TOTPRecoveryCode, 0, totpRecoveryCodeCount)
+	for len(codes) < totpRecoveryCodeCount {
+		raw := make([]byte, 8)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+
+		encoded := strings.ToLower(base32.StdEncoding.EncodeToString(raw))[:totpRecoveryCodeLen]
+		code := encoded[:totpRecoveryCodeLen/2] + "-" + encoded[totpRecoveryCodeLen/2:]
+		codes = append(codes, code)
+		records = append(records, model.TOTPRecoveryCode{
+			UserID:   userID,
+			CodeHash: hashTOTPRecoveryCode(code),
+		})
+	}
+
+	if err := tx.Create(&records).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// hashTOTPRecoveryCode 计算恢复码摘要，忽略大小写和分隔符
+func hashTOTPRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/linux-do/credit/internal/model"
+	"github.com/linux-do/credit/internal/task"
+	"gorm.io/gorm"
+)
+
+// EnqueueWebhookEvent 通过发件箱下发商户 Webhook 事件通知任务，非商户订单直接跳过
+// 是否投递由任务执行时商户的事件订阅决定
+func EnqueueWebhookEvent(tx *gorm.DB, order *model.Order, event model.WebhookEvent, data map[string]string) error {
+	if order.ClientID == "" {
+		return nil
+	}
+
+	if err := EnqueueOutboxTask(tx, task.MerchantWebhookEventTask, map[string]interface{}{
+		"event":     event,
+		"order_id":  order.ID,
+		"client_id": order.ClientID,
+		"data":      data,
+	}, task.QueueWebhook, 10, 30*time.Second); err != nil {
+		return fmt.Errorf("下发商户事件通知任务失败: %w", err)
+	}
+	return nil
+}
+
+// EnqueueMerchantPaymentNotify 通过发件箱下发商户支付回调任务
+func EnqueueMerchantPaymentNotify(tx *gorm.DB, orderID uint64, clientID string) error {
+	if err := EnqueueOutboxTask(tx, task.MerchantPaymentNotifyTask, map[string]interface{}{
+		"order_id":  orderID,
+		"client_id": clientID,
+	}, task.QueueWebhook, 10, 30*time.Second); err != nil {
+		return fmt.Errorf("下发商户回调任务失败: %w", err)
+	}
+	return nil
+}
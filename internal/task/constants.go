@@ -22,7 +22,11 @@ const (
 	AutoRefundExpiredDisputesTask         = "dispute:auto_refund_expired"
 	AutoRefundSingleDisputeTask           = "dispute:auto_refund_single"
 	MerchantPaymentNotifyTask             = "payment:merchant_notify"
+	MerchantWebhookEventTask              = "payment:merchant_webhook_event"
 	SyncOrdersToClickHouseTask            = "order:sync_to_clickhouse"
+	ExpirePendingOrdersTask               = "order:expire_pending"
+	ReconcileUserBalancesTask             = "user:reconcile_balances"
+	CleanupIdempotencyKeysTask            = "idempotency:cleanup_expired"
 )
 
 const (
@@ -28,13 +28,15 @@ import (
 )
 
 var (
-	AsynqClient   *asynq.Client
-	scheduler     *asynq.Scheduler
-	schedulerOnce sync.Once
+	AsynqClient    *asynq.Client
+	AsynqInspector *asynq.Inspector
+	scheduler      *asynq.Scheduler
+	schedulerOnce  sync.Once
 )
 
 func init() {
 	AsynqClient = asynq.NewClient(task.RedisOpt)
+	AsynqInspector = asynq.NewInspector(task.RedisOpt)
 }
 
 // StartScheduler 启动调度器
@@ -83,6 +85,38 @@ func StartScheduler() error {
 			return
 		}
 
+		// 过期订单清理任务，Unique 保证同一时刻只有一个清理任务在执行
+		if _, err = scheduler.Register(
+			config.Config.Scheduler.ExpirePendingOrdersTaskCron,
+			asynq.NewTask(task.ExpirePendingOrdersTask, nil),
+			asynq.MaxRetry(0),
+			asynq.Timeout(5*time.Minute),
+			asynq.Unique(5*time.Minute),
+		); err != nil {
+			return
+		}
+
+		// 余额对账任务
+		if _, err = scheduler.Register(
+			config.Config.Scheduler.ReconcileUserBalancesTaskCron,
+			asynq.NewTask(task.ReconcileUserBalancesTask, nil),
+			asynq.MaxRetry(3),
+			asynq.Timeout(time.Hour),
+			asynq.Unique(23*time.Hour),
+		); err != nil {
+			return
+		}
+
+		// 过期幂等键清理任务
+		if _, err = scheduler.Register(
+			config.Config.Scheduler.CleanupIdempotencyKeysTaskCron,
+			asynq.NewTask(task.CleanupIdempotencyKeysTask, nil),
+			asynq.MaxRetry(0),
+			asynq.Unique(30*time.Minute),
+		); err != nil {
+			return
+		}
+
 		// 启动调度器
 		err = scheduler.Run()
 	})
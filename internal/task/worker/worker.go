@@ -23,7 +23,9 @@ import (
 	"time"
 
 	"github.com/hibiken/asynq"
+	"github.com/linux-do/credit/internal/apps/admin/reconciliation"
 	"github.com/linux-do/credit/internal/apps/dispute"
+	"github.com/linux-do/credit/internal/apps/idempotency"
 	"github.com/linux-do/credit/internal/apps/order"
 	"github.com/linux-do/credit/internal/apps/payment"
 	"github.com/linux-do/credit/internal/apps/user"
@@ -76,7 +78,11 @@ func StartWorker() error {
 	mux.HandleFunc(task.AutoRefundExpiredDisputesTask, dispute.HandleAutoRefundExpiredDisputes)
 	mux.HandleFunc(task.AutoRefundSingleDisputeTask, dispute.HandleAutoRefundSingleDispute)
 	mux.HandleFunc(task.MerchantPaymentNotifyTask, payment.HandleMerchantPaymentNotify)
+	mux.HandleFunc(task.MerchantWebhookEventTask, payment.HandleMerchantWebhookEvent)
 	mux.HandleFunc(task.SyncOrdersToClickHouseTask, order.HandleSyncOrdersToClickHouse)
+	mux.HandleFunc(task.ExpirePendingOrdersTask, order.HandleExpirePendingOrders)
+	mux.HandleFunc(task.ReconcileUserBalancesTask, reconciliation.HandleReconcileUserBalances)
+	mux.HandleFunc(task.CleanupIdempotencyKeysTask, idempotency.HandleCleanupIdempotencyKeys)
 	// 启动服务器
 	return asynqServer.Run(mux)
 }
@@ -0,0 +1,99 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// argon2id 参数，参考 OWASP 推荐的最低配置
+const (
+	argon2Memory  = 19 * 1024 // KiB
+	argon2Time    = 2
+	argon2Threads = 1
+	argon2SaltLen = 16
+	argon2KeyLen  = 32
+)
+
+const argon2idPrefix = "$argon2id$"
+
+// HashSecret 使用 argon2id 对密码类数据生成加盐哈希
+// return: PHC 格式字符串，如 $argon2id$v=19$m=19456,t=2,p=1$<salt>$<hash>
+func HashSecret(secret string) (string, error) {
+	salt := make([]byte, argon2SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	hash := argon2.IDKey([]byte(secret), salt, argon2Time, argon2Memory, argon2Threads, argon2KeyLen)
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2idPrefix, argon2.Version, argon2Memory, argon2Time, argon2Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(hash),
+	), nil
+}
+
+// VerifySecret 校验明文与 HashSecret 生成的哈希是否匹配，按哈希中记录的参数计算
+func VerifySecret(encoded string, secret string) bool {
+	memory, time, threads, salt, hash, err := decodeArgon2idHash(encoded)
+	if err != nil {
+		return false
+	}
+
+	computed := argon2.IDKey([]byte(secret), salt, time, memory, threads, uint32(len(hash)))
+	return subtle.ConstantTimeCompare(computed, hash) == 1
+}
+
+// IsSecretHash 判断字符串是否为 HashSecret 生成的哈希
+func IsSecretHash(encoded string) bool {
+	return strings.HasPrefix(encoded, argon2idPrefix)
+}
+
+// decodeArgon2idHash 解析 PHC 格式的 argon2id 哈希
+func decodeArgon2idHash(encoded string) (memory uint32, time uint32, threads uint8, salt []byte, hash []byte, err error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return 0, 0, 0, nil, nil, errors.New("invalid argon2id hash format")
+	}
+
+	var version int
+	if _, err = fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return 0, 0, 0, nil, nil, fmt.Errorf("invalid argon2id version: %w", err)
+	}
+	if version != argon2.Version {
+		return 0, 0, 0, nil, nil, errors.New("incompatible argon2id version")
+	}
+
+	if _, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &time, &threads); err != nil {
+		return 0, 0, 0, nil, nil, fmt.Errorf("invalid argon2id params: %w", err)
+	}
+
+	if salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return 0, 0, 0, nil, nil, fmt.Errorf("invalid argon2id salt: %w", err)
+	}
+	if hash, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil {
+		return 0, 0, 0, nil, nil, fmt.Errorf("invalid argon2id hash: %w", err)
+	}
+	return memory, time, threads, salt, hash, nil
+}
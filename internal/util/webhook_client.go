@@ -0,0 +1,250 @@
+/*
+Copyright 2025 linux.do
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/linux-do/credit/internal/config"
+)
+
+const (
+	WebhookURLInvalid        = "回调地址格式错误，仅支持 http/https"
+	WebhookHostDenied        = "回调地址域名不在允许范围内"
+	WebhookAddressDenied     = "回调地址指向受限的网络地址"
+	WebhookHostResolveFailed = "回调地址域名解析失败"
+)
+
+// defaultWebhookMaxRespSize 未配置时商户响应体最大读取字节数
+const defaultWebhookMaxRespSize = 64 * 1024
+
+// webhookBlockedPrefixes 默认禁止访问的特殊用途地址段（回环、私有、链路本地等由 netip 判断）
+var webhookBlockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+var (
+	webhookClient     *http.Client
+	webhookClientOnce sync.Once
+	webhookAllowCIDRs []netip.Prefix
+	webhookDenyCIDRs  []netip.Prefix
+)
+
+// getWebhookClient 获取商户回调专用 HTTP 客户端
+// 连接建立时校验实际连接的 IP，防止 DNS 重绑定；不使用环境代理，避免绕过地址校验
+func getWebhookClient() *http.Client {
+	webhookClientOnce.Do(func() {
+		cfg := config.Config.Webhook
+		webhookAllowCIDRs = parseWebhookCIDRs(cfg.AllowedCIDRs)
+		webhookDenyCIDRs = parseWebhookCIDRs(cfg.DeniedCIDRs)
+
+		dialer := &net.Dialer{
+			Timeout:   5 * time.Second,
+			KeepAlive: 30 * time.Second,
+			Control: func(network, address string, _ syscall.RawConn) error {
+				host, _, err := net.SplitHostPort(address)
+				if err != nil {
+					return err
+				}
+				ip, err := netip.ParseAddr(host)
+				if err != nil {
+					return err
+				}
+				return checkWebhookIP(ip)
+			},
+		}
+
+		webhookClient = &http.Client{
+			Timeout: 10 * time.Second,
+			Transport: &http.Transport{
+				Proxy:               nil,
+				DialContext:         dialer.DialContext,
+				MaxIdleConns:        100,
+				MaxIdleConnsPerHost: 20,
+				IdleConnTimeout:     60 * time.Second,
+			},
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				// 超过重定向次数时直接返回重定向响应，由调用方按异常状态码处理
+				if len(via) > cfg.MaxRedirects {
+					return http.ErrUseLastResponse
+				}
+				return checkWebhookURL(req.URL)
+			},
+		}
+	})
+	return webhookClient
+}
+
+// parseWebhookCIDRs 解析配置中的地址段，支持单个 IP（配置加载时已校验格式）
+func parseWebhookCIDRs(entries []string) []netip.Prefix {
+	prefixes := make([]netip.Prefix, 0, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if prefix, err := netip.ParsePrefix(entry); err == nil {
+			prefixes = append(prefixes, prefix.Masked())
+		} else if ip, err := netip.ParseAddr(entry); err == nil {
+			prefixes = append(prefixes, netip.PrefixFrom(ip, ip.BitLen()))
+		}
+	}
+	return prefixes
+}
+
+// matchWebhookHost 判断域名是否命中规则，*.example.com 匹配所有子域名
+func matchWebhookHost(host string, patterns []string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(pattern)), ".")
+		if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+		} else if host == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// checkWebhookURL 校验回调地址的协议与域名黑白名单
+func checkWebhookURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return errors.New(WebhookURLInvalid)
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "" {
+		return errors.New(WebhookURLInvalid)
+	}
+
+	cfg := config.Config.Webhook
+	if matchWebhookHost(host, cfg.DeniedHosts) {
+		return errors.New(WebhookHostDenied)
+	}
+	if len(cfg.AllowedHosts) > 0 && !matchWebhookHost(host, cfg.AllowedHosts) {
+		return errors.New(WebhookHostDenied)
+	}
+	return nil
+}
+
+// checkWebhookIP 校验回调目标 IP，默认禁止回环、私有、链路本地、组播等地址
+func checkWebhookIP(ip netip.Addr) error {
+	ip = ip.Unmap()
+
+	for _, prefix := range webhookDenyCIDRs {
+		if prefix.Contains(ip) {
+			return errors.New(WebhookAddressDenied)
+		}
+	}
+	for _, prefix := range webhookAllowCIDRs {
+		if prefix.Contains(ip) {
+			return nil
+		}
+	}
+
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return errors.New(WebhookAddressDenied)
+	}
+	for _, prefix := range webhookBlockedPrefixes {
+		if prefix.Contains(ip) {
+			return errors.New(WebhookAddressDenied)
+		}
+	}
+	return nil
+}
+
+// ValidateWebhookURL 保存回调地址前校验：协议、域名黑白名单，并解析域名确认不指向受限地址
+func ValidateWebhookURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return errors.New(WebhookURLInvalid)
+	}
+	if err := checkWebhookURL(u); err != nil {
+		return err
+	}
+
+	// 确保地址段配置已加载
+	getWebhookClient()
+
+	host := u.Hostname()
+	if ip, err := netip.ParseAddr(host); err == nil {
+		return checkWebhookIP(ip)
+	}
+
+	ips, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil || len(ips) == 0 {
+		return errors.New(WebhookHostResolveFailed)
+	}
+	for _, ip := range ips {
+		if err := checkWebhookIP(ip); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// limitedReadCloser 限制读取长度的响应体
+type limitedReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// WebhookRequest 发送商户回调请求，限制目标地址、重定向与响应体大小
+func WebhookRequest(ctx context.Context, method, rawURL string, body io.Reader, headers map[string]string) (*http.Response, error) {
+	client := getWebhookClient()
+
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, body)
+	if err != nil {
+		return nil, fmt.Errorf("创建HTTP请求失败: %w", err)
+	}
+	if err := checkWebhookURL(req.URL); err != nil {
+		return nil, err
+	}
+
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求%s接口失败: %w", rawURL, err)
+	}
+
+	maxBytes := config.Config.Webhook.MaxResponseBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultWebhookMaxRespSize
+	}
+	resp.Body = limitedReadCloser{Reader: io.LimitReader(resp.Body, maxBytes), Closer: resp.Body}
+
+	return resp, nil
+}